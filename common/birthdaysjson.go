@@ -1,11 +1,160 @@
 package common
 
+const DATE_LAYOUT = "2006-01-02"
+
+const (
+	NOTIFICATION_KIND_BIRTHDAY = "birthday"
+	NOTIFICATION_KIND_DIGEST   = "digest"
+	NOTIFICATION_KIND_EVENT    = "event"
+	NOTIFICATION_KIND_NAME_DAY = "nameday"
+)
+
+const (
+	DELIVERY_STEP_VIDEO   = "video"
+	DELIVERY_STEP_MESSAGE = "message"
+	DELIVERY_STEP_WISHES  = "wishes"
+)
+
 type BirthdaysJson struct {
 	Birthdays []BirthdayJson `json:"birthdays"`
 }
 
+// BirthdayJson carries the name to display, which may be a link or a @username, along with the user's plain first and last name.
 type BirthdayJson struct {
+	ChatId    int64  `json:"chatId"`
+	UserId    int64  `json:"userId"`
+	Name      string `json:"name"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Date      string `json:"date,omitempty"`
+}
+
+type EventsJson struct {
+	Events []EventJson `json:"events"`
+}
+
+type EventJson struct {
 	ChatId int64  `json:"chatId"`
-	UserId int64  `json:"userId"`
 	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Date   string `json:"date"`
+	Year   int    `json:"year,omitempty"`
+}
+
+type NameDaysJson struct {
+	NameDays []NameDayJson `json:"nameDays"`
+}
+
+type NameDayJson struct {
+	ChatId      int64  `json:"chatId"`
+	UserId      int64  `json:"userId"`
+	Name        string `json:"name"`
+	NameDayName string `json:"nameDayName"`
+	Country     string `json:"country"`
+}
+
+type WishesJson struct {
+	Wishes []WishJson `json:"wishes"`
+}
+
+type WishJson struct {
+	AuthorName  string `json:"authorName"`
+	Text        string `json:"text,omitempty"`
+	VoiceFileId string `json:"voiceFileId,omitempty"`
+}
+
+type VideoJson struct {
+	ChatId int64  `json:"chatId"`
+	UserId int64  `json:"userId"`
+	Year   int    `json:"year"`
+	FileId string `json:"fileId"`
+}
+
+type CachedVideoJson struct {
+	UserId          int64  `json:"userId"`
+	PictureUniqueId string `json:"pictureUniqueId"`
+	FileId          string `json:"fileId"`
+}
+
+// CustomPictureJson has an empty FileId when the user didn't choose a picture.
+type CustomPictureJson struct {
+	UserId       int64  `json:"userId"`
+	ChatId       int64  `json:"chatId"`
+	FileId       string `json:"fileId"`
+	FileUniqueId string `json:"fileUniqueId"`
+}
+
+type DeliveriesJson struct {
+	Deliveries []DeliveryJson `json:"deliveries"`
+}
+
+type DeliveryJson struct {
+	ChatId      int64  `json:"chatId"`
+	UserId      int64  `json:"userId"`
+	Date        string `json:"date"`
+	Kind        string `json:"kind"`
+	Step        string `json:"step"`
+	CompletedAt string `json:"completedAt,omitempty"`
+}
+
+type UnavailableChatJson struct {
+	ChatId int64  `json:"chatId"`
+	Reason string `json:"reason"`
+}
+
+type ScheduleSummaryJson struct {
+	Scheduled int    `json:"scheduled"`
+	Failed    int    `json:"failed"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ScheduledDateJson struct {
+	Date string `json:"date,omitempty"`
+}
+
+const (
+	JOB_STATUS_PENDING = "pending"
+	JOB_STATUS_RUNNING = "running"
+	JOB_STATUS_DONE    = "done"
+	JOB_STATUS_DEAD    = "dead"
+)
+
+type NotificationJobsJson struct {
+	Jobs []NotificationJobJson `json:"jobs"`
+}
+
+type NotificationJobJson struct {
+	Id           int64            `json:"id,omitempty"`
+	TaskId       string           `json:"taskId,omitempty"`
+	Notification NotificationJson `json:"notification"`
+	Status       string           `json:"status,omitempty"`
+	Attempts     int              `json:"attempts,omitempty"`
+	RunAt        string           `json:"runAt,omitempty"`
+	LastError    string           `json:"lastError,omitempty"`
+}
+
+type ClaimJobsJson struct {
+	Limit              int `json:"limit"`
+	VisibilityTimeoutS int `json:"visibilityTimeoutS"`
+	MaxAttempts        int `json:"maxAttempts"`
+}
+
+// JobResultJson reports a finished attempt of a job. A failed job is retried at RetryAt unless it's dead.
+// Attempts must be the number of attempts the job was claimed with, so a result of an expired claim is rejected.
+type JobResultJson struct {
+	Id       int64  `json:"id"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+	RetryAt  string `json:"retryAt,omitempty"`
+	Dead     bool   `json:"dead,omitempty"`
+}
+
+type NotificationJson struct {
+	Kind      string         `json:"kind"`
+	ChatId    int64          `json:"chatId"`
+	Birthdays []BirthdayJson `json:"birthdays"`
+	Events    []EventJson    `json:"events,omitempty"`
+	NameDays  []NameDayJson  `json:"nameDays,omitempty"`
+	Belated   bool           `json:"belated,omitempty"`
+	Date      string         `json:"date,omitempty"`
 }
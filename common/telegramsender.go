@@ -0,0 +1,280 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	TELEGRAM_GLOBAL_MESSAGES_PER_SECOND  = 30
+	TELEGRAM_GROUP_MESSAGES_PER_MINUTE   = 20
+	TELEGRAM_PRIVATE_MESSAGES_PER_SECOND = 1
+	TELEGRAM_MAX_ATTEMPTS                = 4
+	TELEGRAM_MIN_BACKOFF                 = time.Second
+	TELEGRAM_MAX_BACKOFF                 = 30 * time.Second
+	// TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT is how long the limit of a chat is kept after its last call. A limit is only dropped
+	// once it refilled, so a chat that comes back later starts with the same limit it would have had anyway.
+	TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT = 10 * time.Minute
+)
+
+var ErrRateLimitExceedsDeadline = errors.New("telegram rate limit wait exceeds the context deadline")
+
+var telegramErrorResponseRegexp = regexp.MustCompile(`(?s)error response from telegram for method \S+, (\d{3}) (.*)`)
+var telegramRetryAfterRegexp = regexp.MustCompile(`retry[_ ]after (\d+)`)
+
+// TelegramError is an error response of the Telegram API.
+type TelegramError struct {
+	StatusCode  int
+	Description string
+	Err         error
+}
+
+func (err *TelegramError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *TelegramError) Unwrap() error {
+	return err.Err
+}
+
+// ParseTelegramError extracts the status code and the description from the generic error returned by the bot library
+// for a failed Telegram response. It returns nil if the error isn't a Telegram response, e.g. a network error.
+func ParseTelegramError(err error) *TelegramError {
+	match := telegramErrorResponseRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return nil
+	}
+	statusCode, _ := strconv.Atoi(match[1])
+	return &TelegramError{StatusCode: statusCode, Description: match[2], Err: err}
+}
+
+// TelegramRetryableError is a Telegram API error that's worth retrying: a 429 with an optional retry_after or a 5xx.
+type TelegramRetryableError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (err *TelegramRetryableError) Error() string {
+	return fmt.Sprintf("telegram responded with %v (retry after %v): %v", err.StatusCode, err.RetryAfter, err.Err)
+}
+
+func (err *TelegramRetryableError) Unwrap() error {
+	return err.Err
+}
+
+// ParseTelegramErrorResponse classifies the generic error returned by the bot library for a failed Telegram response.
+// It returns nil if the error isn't worth retrying.
+func ParseTelegramErrorResponse(err error) *TelegramRetryableError {
+	telegramError := ParseTelegramError(err)
+	if telegramError == nil || telegramError.StatusCode != 429 && telegramError.StatusCode < 500 {
+		return nil
+	}
+	retryableError := &TelegramRetryableError{StatusCode: telegramError.StatusCode, Err: err}
+	if retryAfterMatch := telegramRetryAfterRegexp.FindStringSubmatch(err.Error()); retryAfterMatch != nil {
+		retryAfter, _ := strconv.Atoi(retryAfterMatch[1])
+		retryableError.RetryAfter = time.Duration(retryAfter) * time.Second
+	}
+	return retryableError
+}
+
+// TelegramSender keeps the calls to the Telegram API within its global and per-chat limits
+// and retries the ones rejected due to rate limiting or server errors.
+type TelegramSender struct {
+	mutex       sync.Mutex
+	globalLimit *tokenBucket
+	chatLimits  map[int64]*tokenBucket
+	evictedAt   time.Time
+	classify    func(error) *TelegramRetryableError
+}
+
+// NewTelegramSender creates a sender that uses classify to find out whether a failed call can be retried.
+func NewTelegramSender(classify func(error) *TelegramRetryableError) *TelegramSender {
+	return &TelegramSender{
+		globalLimit: newTokenBucket(TELEGRAM_GLOBAL_MESSAGES_PER_SECOND, TELEGRAM_GLOBAL_MESSAGES_PER_SECOND),
+		chatLimits:  map[int64]*tokenBucket{},
+		evictedAt:   time.Now(),
+		classify:    classify,
+	}
+}
+
+// Send makes a call that sends something to the chat. It waits for the chat's and the global limit,
+// unless the wait would run past the context's deadline.
+func (sender *TelegramSender) Send(ctx context.Context, chatId int64, send func(ctx context.Context) error) error {
+	return sender.retry(ctx, chatId, func(ctx context.Context) error {
+		if err := sender.waitForLimits(ctx, chatId); err != nil {
+			return err
+		}
+		return send(ctx)
+	})
+}
+
+// Call makes a call that doesn't send anything, e.g. a query. It's only retried and doesn't count towards the limits.
+func (sender *TelegramSender) Call(ctx context.Context, call func(ctx context.Context) error) error {
+	return sender.retry(ctx, 0, call)
+}
+
+func (sender *TelegramSender) retry(ctx context.Context, chatId int64, call func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := call(ctx)
+		if err == nil {
+			return nil
+		}
+		retryableError := sender.classify(err)
+		if retryableError == nil {
+			return err
+		}
+		if attempt >= TELEGRAM_MAX_ATTEMPTS {
+			return retryableError
+		}
+		delay := retryableError.RetryAfter
+		if delay == 0 {
+			delay = getTelegramBackoff(attempt)
+		} else {
+			sender.pause(chatId, time.Now().Add(delay))
+		}
+		if deadline, present := ctx.Deadline(); present && time.Now().Add(delay).After(deadline) {
+			return retryableError
+		}
+		log.Printf("Retrying a telegram call to chatId: %v in %v (attempt %v/%v) due to: %v\n", chatId, delay, attempt+1, TELEGRAM_MAX_ATTEMPTS, retryableError)
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// pause stops sending to the chat, or to all chats for calls without one, until Telegram allows it again.
+func (sender *TelegramSender) pause(chatId int64, until time.Time) {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	if chatId == 0 {
+		sender.globalLimit.pause(until)
+		return
+	}
+	sender.getChatLimit(chatId).pause(until)
+}
+
+func (sender *TelegramSender) waitForLimits(ctx context.Context, chatId int64) error {
+	sender.mutex.Lock()
+	now := time.Now()
+	sender.evictIdleChatLimits(now)
+	globalWait := sender.globalLimit.reserve(now)
+	chatLimit := sender.getChatLimit(chatId)
+	wait := max(globalWait, chatLimit.reserve(now))
+	if deadline, present := ctx.Deadline(); present && now.Add(wait).After(deadline) {
+		sender.globalLimit.release()
+		chatLimit.release()
+		sender.mutex.Unlock()
+		return ErrRateLimitExceedsDeadline
+	}
+	sender.mutex.Unlock()
+	if wait > 0 {
+		log.Printf("Waiting %v for the telegram rate limit of chatId: %v\n", wait, chatId)
+	}
+	return sleep(ctx, wait)
+}
+
+func (sender *TelegramSender) getChatLimit(chatId int64) *tokenBucket {
+	chatLimit, present := sender.chatLimits[chatId]
+	if !present {
+		if chatId < 0 {
+			chatLimit = newTokenBucket(TELEGRAM_GROUP_MESSAGES_PER_MINUTE, TELEGRAM_GROUP_MESSAGES_PER_MINUTE/60.0)
+		} else {
+			chatLimit = newTokenBucket(TELEGRAM_PRIVATE_MESSAGES_PER_SECOND, TELEGRAM_PRIVATE_MESSAGES_PER_SECOND)
+		}
+		sender.chatLimits[chatId] = chatLimit
+	}
+	return chatLimit
+}
+
+// evictIdleChatLimits drops the limits of chats that weren't sent to for a while, so a long-running process
+// doesn't keep one for every chat it ever sent to. It looks through the limits at most once per idle timeout.
+func (sender *TelegramSender) evictIdleChatLimits(now time.Time) {
+	if now.Sub(sender.evictedAt) < TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT {
+		return
+	}
+	sender.evictedAt = now
+	for chatId, chatLimit := range sender.chatLimits {
+		if chatLimit.isIdle(now, TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT) {
+			delete(sender.chatLimits, chatId)
+		}
+	}
+}
+
+func getTelegramBackoff(attempt int) time.Duration {
+	backoff := TELEGRAM_MIN_BACKOFF << (attempt - 1)
+	if backoff <= 0 || backoff > TELEGRAM_MAX_BACKOFF {
+		return TELEGRAM_MAX_BACKOFF
+	}
+	return backoff
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// tokenBucket allows bursts of up to capacity calls and refills at the given rate per second.
+// Tokens can be reserved in advance, which makes the bucket go negative until it refills.
+type tokenBucket struct {
+	capacity    float64
+	rate        float64
+	tokens      float64
+	updatedAt   time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(capacity float64, rate float64) *tokenBucket {
+	return &tokenBucket{
+		capacity:  capacity,
+		rate:      rate,
+		tokens:    capacity,
+		updatedAt: time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+func (bucket *tokenBucket) reserve(now time.Time) time.Duration {
+	if now.After(bucket.updatedAt) {
+		bucket.tokens = min(bucket.capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*bucket.rate)
+		bucket.updatedAt = now
+	}
+	bucket.tokens--
+	wait := time.Duration(0)
+	if bucket.tokens < 0 {
+		wait = time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+	}
+	return max(wait, bucket.pausedUntil.Sub(now))
+}
+
+// isIdle tells whether the bucket wasn't used for the given time and is full again, so it's no different from a new one.
+func (bucket *tokenBucket) isIdle(now time.Time, timeout time.Duration) bool {
+	idleTime := now.Sub(bucket.updatedAt)
+	isFull := bucket.tokens+idleTime.Seconds()*bucket.rate >= bucket.capacity
+	return idleTime >= timeout && isFull && !bucket.pausedUntil.After(now)
+}
+
+func (bucket *tokenBucket) release() {
+	bucket.tokens = min(bucket.capacity, bucket.tokens+1)
+}
+
+func (bucket *tokenBucket) pause(until time.Time) {
+	if until.After(bucket.pausedUntil) {
+		bucket.pausedUntil = until
+	}
+}
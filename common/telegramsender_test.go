@@ -0,0 +1,280 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	GROUP_CHAT_ID   = -100
+	PRIVATE_CHAT_ID = 100
+)
+
+var _ = Describe("Telegram sender", func() {
+	var sender *TelegramSender
+	var classifiedErrors []error
+
+	BeforeEach(func() {
+		classifiedErrors = nil
+		sender = NewTelegramSender(func(err error) *TelegramRetryableError {
+			classifiedErrors = append(classifiedErrors, err)
+			return ParseTelegramErrorResponse(err)
+		})
+	})
+
+	// telegramErrorResponse is how both versions of the bot library used by the services (v1.1.7 and v1.5.0) report
+	// an error response they don't wrap in an error of their own. v1.1.7 does it for everything but 403.
+	telegramErrorResponse := func(method string, statusCode int, description string) error {
+		return fmt.Errorf("error response from telegram for method %s, %d %s", method, statusCode, description)
+	}
+
+	Describe("classifying errors", func() {
+		DescribeTable("should retry rate limiting and server errors",
+			func(err error, expectedStatusCode int, expectedRetryAfter time.Duration) {
+				retryableError := ParseTelegramErrorResponse(err)
+
+				Expect(retryableError).To(Not(BeNil()))
+				Expect(retryableError.StatusCode).To(Equal(expectedStatusCode))
+				Expect(retryableError.RetryAfter).To(Equal(expectedRetryAfter))
+				Expect(errors.Is(retryableError, err)).To(BeTrue())
+			},
+			Entry("for rate limiting with a retry after", telegramErrorResponse("sendMessage", 429, "Too Many Requests: retry after 7"), 429, 7*time.Second),
+			Entry("for rate limiting without a retry after", telegramErrorResponse("sendMessage", 429, "Too Many Requests"), 429, time.Duration(0)),
+			Entry("for an internal server error", telegramErrorResponse("sendVideo", 500, "Internal Server Error"), 500, time.Duration(0)),
+			Entry("for a bad gateway", telegramErrorResponse("sendVideo", 502, "Bad Gateway"), 502, time.Duration(0)),
+		)
+
+		DescribeTable("should not retry other errors",
+			func(err error) {
+				Expect(ParseTelegramErrorResponse(err)).To(BeNil())
+			},
+			Entry("for a bad request", telegramErrorResponse("editMessageText", 400, "Bad Request: message is not modified")),
+			Entry("for a forbidden request wrapped by both versions", fmt.Errorf("%w, %s", errors.New("forbidden"), "Forbidden: bot was kicked from the group chat")),
+			Entry("for a bad request wrapped by v1.5.0", fmt.Errorf("%w, %s", errors.New("bad request"), "Bad Request: chat not found")),
+			Entry("for a network error", errors.New("Post \"https://api.telegram.org\": dial tcp: i/o timeout")),
+		)
+
+		It("should parse the status code and a multiline description", func() {
+			telegramError := ParseTelegramError(telegramErrorResponse("sendMessage", 400, "Bad Request: can't parse entities:\nunexpected end tag"))
+
+			Expect(telegramError).To(Not(BeNil()))
+			Expect(telegramError.StatusCode).To(Equal(400))
+			Expect(telegramError.Description).To(Equal("Bad Request: can't parse entities:\nunexpected end tag"))
+		})
+	})
+
+	Describe("retrying", func() {
+		It("should not retry a call that succeeded", func() {
+			calls := 0
+
+			err := sender.Send(context.Background(), PRIVATE_CHAT_ID, func(context.Context) error {
+				calls++
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should not retry an error that isn't retryable", func() {
+			calls := 0
+			badRequest := telegramErrorResponse("sendMessage", 400, "Bad Request: chat not found")
+
+			err := sender.Call(context.Background(), func(context.Context) error {
+				calls++
+				return badRequest
+			})
+
+			Expect(err).To(Equal(badRequest))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should retry a server error", func() {
+			calls := 0
+
+			err := sender.Call(context.Background(), func(context.Context) error {
+				calls++
+				if calls == 1 {
+					return telegramErrorResponse("sendMessage", 502, "Bad Gateway")
+				}
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(calls).To(Equal(2))
+		})
+
+		It("should pause the chat until the retry after and give up when it's past the deadline", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			calls := 0
+			startedAt := time.Now()
+
+			err := sender.Send(ctx, GROUP_CHAT_ID, func(context.Context) error {
+				calls++
+				return telegramErrorResponse("sendMessage", 429, "Too Many Requests: retry after 30")
+			})
+
+			var retryableError *TelegramRetryableError
+			Expect(errors.As(err, &retryableError)).To(BeTrue())
+			Expect(retryableError.RetryAfter).To(Equal(30 * time.Second))
+			Expect(calls).To(Equal(1))
+			Expect(time.Since(startedAt)).To(BeNumerically("<", time.Second))
+			Expect(sender.chatLimits[GROUP_CHAT_ID].pausedUntil).To(BeTemporally("~", startedAt.Add(30*time.Second), time.Second))
+		})
+
+		It("should wait for the chat's pause before the next call", func() {
+			sender.pause(GROUP_CHAT_ID, time.Now().Add(time.Minute))
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			calls := 0
+
+			err := sender.Send(ctx, GROUP_CHAT_ID, func(context.Context) error {
+				calls++
+				return nil
+			})
+
+			Expect(err).To(MatchError(ErrRateLimitExceedsDeadline))
+			Expect(calls).To(Equal(0))
+		})
+
+		It("should give up when the backoff would end past the deadline", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), TELEGRAM_MIN_BACKOFF/2)
+			defer cancel()
+			calls := 0
+
+			err := sender.Call(ctx, func(context.Context) error {
+				calls++
+				return telegramErrorResponse("sendMessage", 500, "Internal Server Error")
+			})
+
+			var retryableError *TelegramRetryableError
+			Expect(errors.As(err, &retryableError)).To(BeTrue())
+			Expect(retryableError.StatusCode).To(Equal(500))
+			Expect(calls).To(Equal(1))
+		})
+
+		DescribeTable("should double the backoff with every attempt up to the maximum",
+			func(attempt int, expectedBackoff time.Duration) {
+				Expect(getTelegramBackoff(attempt)).To(Equal(expectedBackoff))
+			},
+			Entry("for the first attempt", 1, TELEGRAM_MIN_BACKOFF),
+			Entry("for the second attempt", 2, 2*TELEGRAM_MIN_BACKOFF),
+			Entry("when doubling would exceed the maximum", 10, TELEGRAM_MAX_BACKOFF),
+			Entry("for many attempts", 100, TELEGRAM_MAX_BACKOFF),
+		)
+	})
+
+	Describe("rate limiting", func() {
+		It("should not wait within the limit of a group", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			for range TELEGRAM_GROUP_MESSAGES_PER_MINUTE {
+				Expect(sender.Send(ctx, GROUP_CHAT_ID, func(context.Context) error { return nil })).To(BeNil())
+			}
+		})
+
+		It("should refuse to wait for the limit of a group past the deadline and give the reserved tokens back", func() {
+			for range TELEGRAM_GROUP_MESSAGES_PER_MINUTE {
+				sender.Send(context.Background(), GROUP_CHAT_ID, func(context.Context) error { return nil })
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			globalTokens := sender.globalLimit.tokens
+
+			err := sender.Send(ctx, GROUP_CHAT_ID, func(context.Context) error { return nil })
+
+			Expect(err).To(MatchError(ErrRateLimitExceedsDeadline))
+			Expect(sender.globalLimit.tokens).To(BeNumerically("~", globalTokens, 0.1))
+			Expect(sender.chatLimits[GROUP_CHAT_ID].tokens).To(BeNumerically("<", 1))
+		})
+
+		It("should not count calls against the limits", func() {
+			for range TELEGRAM_GLOBAL_MESSAGES_PER_SECOND * 2 {
+				sender.Call(context.Background(), func(context.Context) error { return nil })
+			}
+
+			Expect(sender.globalLimit.tokens).To(Equal(float64(TELEGRAM_GLOBAL_MESSAGES_PER_SECOND)))
+		})
+
+		It("should make the caller wait once the tokens run out until they refill", func() {
+			bucket := newTokenBucket(2, 1)
+			now := bucket.updatedAt
+
+			Expect(bucket.reserve(now)).To(Equal(time.Duration(0)))
+			Expect(bucket.reserve(now)).To(Equal(time.Duration(0)))
+			Expect(bucket.reserve(now)).To(Equal(time.Second))
+			Expect(bucket.reserve(now)).To(Equal(2 * time.Second))
+			Expect(bucket.reserve(now.Add(3 * time.Second))).To(Equal(time.Duration(0)))
+		})
+
+		It("should refill the tokens only up to the capacity", func() {
+			bucket := newTokenBucket(2, 1)
+			now := bucket.updatedAt
+			bucket.reserve(now)
+
+			bucket.reserve(now.Add(time.Hour))
+
+			Expect(bucket.tokens).To(Equal(1.0))
+		})
+
+		It("should make the caller wait until the pause ends", func() {
+			bucket := newTokenBucket(2, 1)
+			now := bucket.updatedAt
+			bucket.pause(now.Add(time.Minute))
+
+			Expect(bucket.reserve(now)).To(Equal(time.Minute))
+		})
+	})
+
+	Describe("evicting idle chat limits", func() {
+		It("should drop the limits of chats that refilled and weren't used for a while", func() {
+			now := time.Now()
+			sender.getChatLimit(GROUP_CHAT_ID).reserve(now)
+			sender.getChatLimit(PRIVATE_CHAT_ID).reserve(now.Add(TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT))
+
+			sender.evictIdleChatLimits(now.Add(TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT + time.Second))
+
+			Expect(sender.chatLimits).To(HaveLen(1))
+			Expect(sender.chatLimits).To(HaveKey(int64(PRIVATE_CHAT_ID)))
+		})
+
+		It("should keep the limits of paused chats", func() {
+			now := time.Now()
+			sender.getChatLimit(GROUP_CHAT_ID).reserve(now)
+			sender.pause(GROUP_CHAT_ID, now.Add(time.Hour))
+
+			sender.evictIdleChatLimits(now.Add(TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT + time.Second))
+
+			Expect(sender.chatLimits).To(HaveKey(int64(GROUP_CHAT_ID)))
+		})
+
+		It("should keep the limits of chats that didn't refill", func() {
+			now := time.Now()
+			chatLimit := sender.getChatLimit(GROUP_CHAT_ID)
+			chatLimit.updatedAt = now
+			chatLimit.tokens = -1000
+
+			sender.evictIdleChatLimits(now.Add(TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT + time.Second))
+
+			Expect(sender.chatLimits).To(HaveKey(int64(GROUP_CHAT_ID)))
+		})
+
+		It("should look through the limits at most once per idle timeout", func() {
+			now := time.Now()
+			sender.getChatLimit(GROUP_CHAT_ID).reserve(now)
+			sender.evictIdleChatLimits(now.Add(TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT))
+			sender.getChatLimit(PRIVATE_CHAT_ID).reserve(now)
+
+			sender.evictIdleChatLimits(now.Add(TELEGRAM_CHAT_LIMIT_IDLE_TIMEOUT + time.Second))
+
+			Expect(sender.chatLimits).To(HaveKey(int64(PRIVATE_CHAT_ID)))
+		})
+	})
+})
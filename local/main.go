@@ -21,6 +21,6 @@ func main() {
 	if err != nil {
 		log.Fatalln(err)
 	}
-	videoGenerator := adapters.NewVideoGenerator(resourcesDir)
+	videoGenerator := adapters.NewVideoGenerator(resourcesDir, adapters.DEFAULT_VIDEO_TEMPLATE)
 	videoGenerator.CreateVideo(imagePath)
 }
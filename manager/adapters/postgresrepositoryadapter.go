@@ -23,10 +23,10 @@ func NewSqlRepositoryAdapter(database *pgxpool.Pool, clock *common.SystemClock)
 
 func (adapter *PostgresRepositoryAdapter) SaveBirthday(ctx context.Context, birthday birthday_bot.Birthday) error {
 	log.Printf("Inserting birthday into the database: %v\n", birthday)
-	statement := `INSERT INTO birthdays (chat_id, user_id, date, adjusted_day_of_year, username, first_name, last_name)
-						VALUES ($1, $2, $3, $4, $5, $6, $7)
+	statement := `INSERT INTO birthdays (chat_id, user_id, date, adjusted_day_of_year, username, first_name, last_name, birth_year)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 						ON CONFLICT (chat_id, user_id) DO UPDATE SET
-						date = $3, adjusted_day_of_year = $4, username = $5, first_name = $6, last_name = $7`
+						date = $3, adjusted_day_of_year = $4, username = $5, first_name = $6, last_name = $7, birth_year = $8`
 	if _, err := adapter.database.Exec(
 		ctx,
 		statement,
@@ -37,6 +37,7 @@ func (adapter *PostgresRepositoryAdapter) SaveBirthday(ctx context.Context, birt
 		birthday.Username,
 		birthday.UserFirstName,
 		birthday.UserLastName,
+		nullableYear(birthday.Year),
 	); err != nil {
 		common.ErrorLogger.Printf("Failed to insert a birthday: %v into the database: %v\n", birthday, err)
 		return err
@@ -44,32 +45,47 @@ func (adapter *PostgresRepositoryAdapter) SaveBirthday(ctx context.Context, birt
 	return nil
 }
 
-func (adapter *PostgresRepositoryAdapter) GetBirthdayDate(ctx context.Context, chatId int64, userId int64) (*time.Time, error) {
+func (adapter *PostgresRepositoryAdapter) GetBirthday(ctx context.Context, chatId int64, userId int64) (*birthday_bot.Birthday, error) {
 	log.Printf("Getting birthday from the database for chatId: %v, userId: %v\n", chatId, userId)
-	statement := `SELECT date FROM birthdays WHERE chat_id = $1 AND user_id = $2`
+	statement := `SELECT chat_id, user_id, date, birth_year, username, first_name, last_name
+					FROM birthdays
+					WHERE chat_id = $1 AND user_id = $2`
 	result := adapter.database.QueryRow(ctx, statement, chatId, userId)
 
-	date := time.Time{}
-	if err := result.Scan(&date); err != nil {
+	var birthday birthday_bot.Birthday
+	var year *int
+	if err := result.Scan(
+		&birthday.ChatId,
+		&birthday.UserId,
+		&birthday.Date,
+		&year,
+		&birthday.Username,
+		&birthday.UserFirstName,
+		&birthday.UserLastName,
+	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
-		common.ErrorLogger.Printf("Failed to get a birthday date for chat: %v, userId: %v from the database: %v\n", chatId, userId, err)
+		common.ErrorLogger.Printf("Failed to get a birthday for chat: %v, userId: %v from the database: %v\n", chatId, userId, err)
 		return nil, err
 	}
-	return &date, nil
+	if year != nil {
+		birthday.Year = *year
+	}
+	return &birthday, nil
 }
 
 func (adapter *PostgresRepositoryAdapter) GetNextBirthdays(ctx context.Context, chatId int64) ([]birthday_bot.Birthday, error) {
-	currentAdjustedDayOfYear := getAdjustedDayOfYear(adapter.clock.Now())
+	now := adapter.clock.Now()
+	currentAdjustedDayOfYear := getAdjustedDayOfYear(now)
 	var birthdaysThisYear []birthday_bot.Birthday
 	var err error
-	birthdaysThisYear, err = adapter.getClosestBirthdaysAfterAdjustedDay(ctx, chatId, currentAdjustedDayOfYear)
+	birthdaysThisYear, err = adapter.getClosestBirthdaysAfterAdjustedDay(ctx, chatId, currentAdjustedDayOfYear, isLeapYear(now.Year()))
 	if err != nil {
 		return nil, err
 	}
 	if len(birthdaysThisYear) == 0 {
-		return adapter.getClosestBirthdaysAfterAdjustedDay(ctx, chatId, 0)
+		return adapter.getClosestBirthdaysAfterAdjustedDay(ctx, chatId, 0, isLeapYear(now.Year()+1))
 	}
 	return birthdaysThisYear, nil
 }
@@ -105,6 +121,62 @@ func (adapter *PostgresRepositoryAdapter) GetBirthdaysForDate(ctx context.Contex
 	return birthdays, nil
 }
 
+func (adapter *PostgresRepositoryAdapter) GetBirthdayCountsPerDay(ctx context.Context, chatId int64) ([]birthday_bot.BirthdayDayCount, error) {
+	log.Printf("Getting birthday counts per day from the database for chatId: %v\n", chatId)
+	statement := `SELECT adjusted_day_of_year, COUNT(*)
+					FROM birthdays
+					WHERE chat_id = $1
+					GROUP BY adjusted_day_of_year
+					ORDER BY adjusted_day_of_year`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to get birthday counts for chatId: %v from the database: %v\n", chatId, err)
+		return nil, err
+	}
+	var counts []birthday_bot.BirthdayDayCount
+	for rows.Next() {
+		var count birthday_bot.BirthdayDayCount
+		if err = rows.Scan(&count.DayOfYear, &count.Count); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for birthday counts for chatId: %v due to: %v\n", chatId, err)
+			return counts, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetChatBirthdaysBetween(ctx context.Context, chatId int64, from time.Time, to time.Time) ([]birthday_bot.Birthday, error) {
+	log.Printf("Getting birthdays from the database for chatId: %v between: %v and: %v\n", chatId, from, to)
+	statement := `SELECT chat_id, user_id, date, username, first_name, last_name
+					FROM birthdays
+					WHERE chat_id = $1 AND adjusted_day_of_year BETWEEN $2 AND $3
+					ORDER BY adjusted_day_of_year, first_name, username`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, chatId, getAdjustedDayOfYear(from), getAdjustedDayOfYear(to)); err != nil {
+		common.ErrorLogger.Printf("Failed to get birthdays for chatId: %v between: %v and: %v from the database: %v\n", chatId, from, to, err)
+		return nil, err
+	}
+	var birthdays []birthday_bot.Birthday
+	for rows.Next() {
+		var birthday birthday_bot.Birthday
+		if err = rows.Scan(
+			&birthday.ChatId,
+			&birthday.UserId,
+			&birthday.Date,
+			&birthday.Username,
+			&birthday.UserFirstName,
+			&birthday.UserLastName,
+		); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for birthdays for chatId: %v between: %v and: %v due to: %v\n", chatId, from, to, err)
+			return birthdays, err
+		}
+		birthdays = append(birthdays, birthday)
+	}
+	return birthdays, nil
+}
+
 func (adapter *PostgresRepositoryAdapter) DeleteBirthday(ctx context.Context, chatId int64, userId int64) error {
 	log.Printf("Deleting birthday from the database for chatId: %v, userId: %v\n", chatId, userId)
 	statement := `DELETE FROM birthdays WHERE chat_id = $1 AND user_id = $2`
@@ -125,28 +197,1145 @@ func (adapter *PostgresRepositoryAdapter) DeleteAllChatBirthdays(ctx context.Con
 	return nil
 }
 
-func (adapter *PostgresRepositoryAdapter) DeleteAllUserBirthdays(ctx context.Context, userId int64) error {
+func (adapter *PostgresRepositoryAdapter) DeleteAllUserBirthdays(ctx context.Context, userId int64) ([]int64, error) {
 	log.Printf("Deleting birthday from the database for userId: %v\n", userId)
-	statement := `DELETE FROM birthdays WHERE user_id = $1`
-	if _, err := adapter.database.Exec(ctx, statement, userId); err != nil {
+	statement := `DELETE FROM birthdays WHERE user_id = $1 RETURNING chat_id`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, userId); err != nil {
 		common.ErrorLogger.Printf("Failed to delete all birthdays for userId: %v from the database: %v\n", userId, err)
+		return nil, err
+	}
+	var chatIds []int64
+	for rows.Next() {
+		var chatId int64
+		if err = rows.Scan(&chatId); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for chat ids of deleted birthdays due to: %v\n", err)
+			return chatIds, err
+		}
+		chatIds = append(chatIds, chatId)
+	}
+	return chatIds, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveBoardMessageId(ctx context.Context, chatId int64, messageId int) error {
+	log.Printf("Saving board message id: %v for chatId: %v in the database\n", messageId, chatId)
+	statement := `INSERT INTO chat_settings (chat_id, board_message_id)
+						VALUES ($1, $2)
+						ON CONFLICT (chat_id) DO UPDATE SET board_message_id = $2`
+	if _, err := adapter.database.Exec(ctx, statement, chatId, nullableMessageId(messageId)); err != nil {
+		common.ErrorLogger.Printf("Failed to save board message id: %v for chatId: %v in the database: %v\n", messageId, chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetBoardMessageId(ctx context.Context, chatId int64) (int, error) {
+	log.Printf("Getting board message id from the database for chatId: %v\n", chatId)
+	statement := `SELECT board_message_id FROM chat_settings WHERE chat_id = $1`
+	var messageId *int
+	if err := adapter.database.QueryRow(ctx, statement, chatId).Scan(&messageId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return birthday_bot.NO_BOARD_MESSAGE_ID, nil
+		}
+		common.ErrorLogger.Printf("Failed to get board message id for chatId: %v from the database: %v\n", chatId, err)
+		return birthday_bot.NO_BOARD_MESSAGE_ID, err
+	}
+	if messageId == nil {
+		return birthday_bot.NO_BOARD_MESSAGE_ID, nil
+	}
+	return *messageId, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetChatIdsWithBoards(ctx context.Context) ([]int64, error) {
+	log.Println("Getting chat ids with boards from the database")
+	statement := `SELECT chat_id FROM chat_settings WHERE board_message_id IS NOT NULL`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement); err != nil {
+		common.ErrorLogger.Printf("Failed to get chat ids with boards from the database: %v\n", err)
+		return nil, err
+	}
+	var chatIds []int64
+	for rows.Next() {
+		var chatId int64
+		if err = rows.Scan(&chatId); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for chat ids with boards due to: %v\n", err)
+			return chatIds, err
+		}
+		chatIds = append(chatIds, chatId)
+	}
+	return chatIds, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteChatSettings(ctx context.Context, chatId int64) error {
+	log.Printf("Deleting chat settings from the database for chatId: %v\n", chatId)
+	statement := `DELETE FROM chat_settings WHERE chat_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete chat settings for chatId: %v from the database: %v\n", chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SetDigestEnabled(ctx context.Context, chatId int64, enabled bool) error {
+	log.Printf("Saving digest setting: %v for chatId: %v in the database\n", enabled, chatId)
+	statement := `INSERT INTO chat_settings (chat_id, digest_enabled)
+						VALUES ($1, $2)
+						ON CONFLICT (chat_id) DO UPDATE SET digest_enabled = $2`
+	if _, err := adapter.database.Exec(ctx, statement, chatId, enabled); err != nil {
+		common.ErrorLogger.Printf("Failed to save digest setting: %v for chatId: %v in the database: %v\n", enabled, chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetDigestBirthdaysBetween(ctx context.Context, from time.Time, to time.Time) ([]birthday_bot.Birthday, error) {
+	log.Printf("Getting digest birthdays from the database between: %v and: %v\n", from, to)
+	statement := `SELECT b.chat_id, b.user_id, b.date, b.username, b.first_name, b.last_name
+					FROM birthdays b
+					JOIN chat_settings s ON s.chat_id = b.chat_id
+					WHERE s.digest_enabled AND b.adjusted_day_of_year BETWEEN $1 AND $2
+					ORDER BY b.chat_id, b.adjusted_day_of_year, b.first_name, b.username`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, getAdjustedDayOfYear(from), getAdjustedDayOfYear(to)); err != nil {
+		common.ErrorLogger.Printf("Failed to get digest birthdays between: %v and: %v from the database: %v\n", from, to, err)
+		return nil, err
+	}
+	var birthdays []birthday_bot.Birthday
+	for rows.Next() {
+		var birthday birthday_bot.Birthday
+		if err = rows.Scan(
+			&birthday.ChatId,
+			&birthday.UserId,
+			&birthday.Date,
+			&birthday.Username,
+			&birthday.UserFirstName,
+			&birthday.UserLastName,
+		); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for digest birthdays between: %v and: %v due to: %v\n", from, to, err)
+			return birthdays, err
+		}
+		birthdays = append(birthdays, birthday)
+	}
+	return birthdays, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SetLeapDayPolicy(ctx context.Context, chatId int64, policy birthday_bot.LeapDayPolicy) error {
+	log.Printf("Saving leap day policy: %v for chatId: %v in the database\n", policy, chatId)
+	statement := `INSERT INTO chat_settings (chat_id, leap_day_policy)
+						VALUES ($1, $2)
+						ON CONFLICT (chat_id) DO UPDATE SET leap_day_policy = $2`
+	if _, err := adapter.database.Exec(ctx, statement, chatId, policy); err != nil {
+		common.ErrorLogger.Printf("Failed to save leap day policy: %v for chatId: %v in the database: %v\n", policy, chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetLeapDayPolicy(ctx context.Context, chatId int64) (birthday_bot.LeapDayPolicy, error) {
+	log.Printf("Getting leap day policy from the database for chatId: %v\n", chatId)
+	statement := `SELECT leap_day_policy FROM chat_settings WHERE chat_id = $1`
+	var policy birthday_bot.LeapDayPolicy
+	if err := adapter.database.QueryRow(ctx, statement, chatId).Scan(&policy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return birthday_bot.DEFAULT_LEAP_DAY_POLICY, nil
+		}
+		common.ErrorLogger.Printf("Failed to get leap day policy for chatId: %v from the database: %v\n", chatId, err)
+		return birthday_bot.DEFAULT_LEAP_DAY_POLICY, err
+	}
+	return policy, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetLeapDayBirthdays(ctx context.Context, policy birthday_bot.LeapDayPolicy) ([]birthday_bot.Birthday, error) {
+	log.Printf("Getting leap day birthdays from the database for chats with policy: %v\n", policy)
+	statement := `SELECT b.chat_id, b.user_id, b.date, b.username, b.first_name, b.last_name
+					FROM birthdays b
+					LEFT JOIN chat_settings s ON s.chat_id = b.chat_id
+					WHERE b.adjusted_day_of_year = $1 AND COALESCE(s.leap_day_policy, $2) = $3`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, FEBRUARY_29TH_YEAR_DAY, birthday_bot.DEFAULT_LEAP_DAY_POLICY, policy); err != nil {
+		common.ErrorLogger.Printf("Failed to get leap day birthdays for policy: %v from the database: %v\n", policy, err)
+		return nil, err
+	}
+	var birthdays []birthday_bot.Birthday
+	for rows.Next() {
+		var birthday birthday_bot.Birthday
+		if err = rows.Scan(
+			&birthday.ChatId,
+			&birthday.UserId,
+			&birthday.Date,
+			&birthday.Username,
+			&birthday.UserFirstName,
+			&birthday.UserLastName,
+		); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for leap day birthdays for policy: %v due to: %v\n", policy, err)
+			return birthdays, err
+		}
+		birthdays = append(birthdays, birthday)
+	}
+	return birthdays, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveEvent(ctx context.Context, event birthday_bot.Event) error {
+	log.Printf("Inserting event into the database: %v\n", event)
+	statement := `INSERT INTO events (chat_id, name, kind, date, adjusted_day_of_year, event_year)
+						VALUES ($1, $2, $3, $4, $5, $6)
+						ON CONFLICT (chat_id, LOWER(name)) DO UPDATE SET
+						name = $2, kind = $3, date = $4, adjusted_day_of_year = $5, event_year = $6`
+	if _, err := adapter.database.Exec(
+		ctx,
+		statement,
+		event.ChatId,
+		event.Name,
+		event.Kind,
+		event.Date,
+		getAdjustedDayOfYear(event.Date),
+		nullableYear(event.Year),
+	); err != nil {
+		common.ErrorLogger.Printf("Failed to insert an event: %v into the database: %v\n", event, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetChatEvents(ctx context.Context, chatId int64) ([]birthday_bot.Event, error) {
+	log.Printf("Getting events from the database for chatId: %v\n", chatId)
+	statement := `SELECT chat_id, name, kind, date, event_year
+					FROM events
+					WHERE chat_id = $1
+					ORDER BY adjusted_day_of_year, name`
+	rows, err := adapter.database.Query(ctx, statement, chatId)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get events for chatId: %v from the database: %v\n", chatId, err)
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) GetEventsForDate(ctx context.Context, date time.Time) ([]birthday_bot.Event, error) {
+	log.Printf("Getting events from the database for date: %v\n", date)
+	statement := `SELECT chat_id, name, kind, date, event_year
+					FROM events
+					WHERE adjusted_day_of_year = $1`
+	rows, err := adapter.database.Query(ctx, statement, getAdjustedDayOfYear(date))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get events for date: %v from the database: %v\n", date, err)
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) GetLeapDayEvents(ctx context.Context, policy birthday_bot.LeapDayPolicy) ([]birthday_bot.Event, error) {
+	log.Printf("Getting leap day events from the database for chats with policy: %v\n", policy)
+	statement := `SELECT e.chat_id, e.name, e.kind, e.date, e.event_year
+					FROM events e
+					LEFT JOIN chat_settings s ON s.chat_id = e.chat_id
+					WHERE e.adjusted_day_of_year = $1 AND COALESCE(s.leap_day_policy, $2) = $3`
+	rows, err := adapter.database.Query(ctx, statement, FEBRUARY_29TH_YEAR_DAY, birthday_bot.DEFAULT_LEAP_DAY_POLICY, policy)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get leap day events for policy: %v from the database: %v\n", policy, err)
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteEvent(ctx context.Context, chatId int64, name string) (bool, error) {
+	log.Printf("Deleting event from the database for chatId: %v, name: %v\n", chatId, name)
+	statement := `DELETE FROM events WHERE chat_id = $1 AND LOWER(name) = LOWER($2)`
+	result, err := adapter.database.Exec(ctx, statement, chatId, name)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to delete event for chatId: %v, name: %v from the database: %v\n", chatId, name, err)
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllChatEvents(ctx context.Context, chatId int64) error {
+	log.Printf("Deleting events from the database for chatId: %v\n", chatId)
+	statement := `DELETE FROM events WHERE chat_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete all events for chatId: %v from the database: %v\n", chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SetNameDayCountry(ctx context.Context, chatId int64, country string) error {
+	log.Printf("Saving name day country: %v for chatId: %v in the database\n", country, chatId)
+	statement := `INSERT INTO chat_settings (chat_id, name_day_country)
+						VALUES ($1, $2)
+						ON CONFLICT (chat_id) DO UPDATE SET name_day_country = $2`
+	if _, err := adapter.database.Exec(ctx, statement, chatId, nullableCountry(country)); err != nil {
+		common.ErrorLogger.Printf("Failed to save name day country: %v for chatId: %v in the database: %v\n", country, chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetNameDayCountry(ctx context.Context, chatId int64) (string, error) {
+	log.Printf("Getting name day country from the database for chatId: %v\n", chatId)
+	statement := `SELECT name_day_country FROM chat_settings WHERE chat_id = $1`
+	var country *string
+	if err := adapter.database.QueryRow(ctx, statement, chatId).Scan(&country); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return birthday_bot.NAME_DAY_COUNTRY_NONE, nil
+		}
+		common.ErrorLogger.Printf("Failed to get name day country for chatId: %v from the database: %v\n", chatId, err)
+		return birthday_bot.NAME_DAY_COUNTRY_NONE, err
+	}
+	if country == nil {
+		return birthday_bot.NAME_DAY_COUNTRY_NONE, nil
+	}
+	return *country, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveNameDay(ctx context.Context, nameDay birthday_bot.NameDay) error {
+	log.Printf("Inserting name day into the database: %v\n", nameDay)
+	statement := `INSERT INTO name_days (chat_id, user_id, name, username, first_name, last_name)
+						VALUES ($1, $2, $3, $4, $5, $6)
+						ON CONFLICT (chat_id, user_id) DO UPDATE SET
+						name = $3, username = $4, first_name = $5, last_name = $6`
+	if _, err := adapter.database.Exec(
+		ctx,
+		statement,
+		nameDay.ChatId,
+		nameDay.UserId,
+		nameDay.Name,
+		nameDay.Username,
+		nameDay.UserFirstName,
+		nameDay.UserLastName,
+	); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a name day: %v into the database: %v\n", nameDay, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetNameDaysForNames(ctx context.Context, country string, names []string) ([]birthday_bot.NameDay, error) {
+	log.Printf("Getting name days from the database for country: %v, names: %v\n", country, names)
+	statement := `SELECT n.chat_id, n.user_id, n.name, n.username, n.first_name, n.last_name
+					FROM name_days n
+					JOIN chat_settings s ON s.chat_id = n.chat_id
+					WHERE s.name_day_country = $1 AND n.name = ANY($2)`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, country, names); err != nil {
+		common.ErrorLogger.Printf("Failed to get name days for country: %v, names: %v from the database: %v\n", country, names, err)
+		return nil, err
+	}
+	var nameDays []birthday_bot.NameDay
+	for rows.Next() {
+		var nameDay birthday_bot.NameDay
+		if err = rows.Scan(
+			&nameDay.ChatId,
+			&nameDay.UserId,
+			&nameDay.Name,
+			&nameDay.Username,
+			&nameDay.UserFirstName,
+			&nameDay.UserLastName,
+		); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for name days for country: %v due to: %v\n", country, err)
+			return nameDays, err
+		}
+		nameDays = append(nameDays, nameDay)
+	}
+	return nameDays, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteNameDay(ctx context.Context, chatId int64, userId int64) error {
+	log.Printf("Deleting name day from the database for chatId: %v, userId: %v\n", chatId, userId)
+	statement := `DELETE FROM name_days WHERE chat_id = $1 AND user_id = $2`
+	if _, err := adapter.database.Exec(ctx, statement, chatId, userId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete name day for chatId: %v, userId: %v from the database: %v\n", chatId, userId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllChatNameDays(ctx context.Context, chatId int64) error {
+	log.Printf("Deleting name days from the database for chatId: %v\n", chatId)
+	statement := `DELETE FROM name_days WHERE chat_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete all name days for chatId: %v from the database: %v\n", chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllUserNameDays(ctx context.Context, userId int64) error {
+	log.Printf("Deleting name days from the database for userId: %v\n", userId)
+	statement := `DELETE FROM name_days WHERE user_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, userId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete all name days for userId: %v from the database: %v\n", userId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveWishContributor(ctx context.Context, userId int64) error {
+	log.Printf("Inserting wish contributor into the database: %v\n", userId)
+	statement := `INSERT INTO wish_contributors (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING`
+	if _, err := adapter.database.Exec(ctx, statement, userId); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a wish contributor: %v into the database: %v\n", userId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetWishContributors(ctx context.Context, chatId int64) ([]int64, error) {
+	log.Printf("Getting wish contributors from the database for chatId: %v\n", chatId)
+	statement := `SELECT c.user_id
+					FROM wish_contributors c
+					WHERE EXISTS (SELECT 1 FROM birthdays b WHERE b.chat_id = $1 AND b.user_id = c.user_id)
+					OR EXISTS (SELECT 1 FROM name_days n WHERE n.chat_id = $1 AND n.user_id = c.user_id)`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to get wish contributors for chatId: %v from the database: %v\n", chatId, err)
+		return nil, err
+	}
+	var userIds []int64
+	for rows.Next() {
+		var userId int64
+		if err = rows.Scan(&userId); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for wish contributors for chatId: %v due to: %v\n", chatId, err)
+			return userIds, err
+		}
+		userIds = append(userIds, userId)
+	}
+	return userIds, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SavePendingWish(ctx context.Context, pendingWish birthday_bot.PendingWish) error {
+	log.Printf("Inserting pending wish into the database: %v\n", pendingWish)
+	statement := `INSERT INTO pending_wishes (author_user_id, chat_id, birthday_user_id, birthday_date, birthday_person_name)
+						VALUES ($1, $2, $3, $4, $5)
+						ON CONFLICT (author_user_id, chat_id, birthday_user_id, birthday_date) DO NOTHING`
+	if _, err := adapter.database.Exec(
+		ctx,
+		statement,
+		pendingWish.AuthorUserId,
+		pendingWish.ChatId,
+		pendingWish.BirthdayUserId,
+		pendingWish.Date,
+		pendingWish.BirthdayPersonName,
+	); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a pending wish: %v into the database: %v\n", pendingWish, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetPendingWishes(ctx context.Context, authorUserId int64, from time.Time) ([]birthday_bot.PendingWish, error) {
+	log.Printf("Getting pending wishes from the database for authorUserId: %v, from: %v\n", authorUserId, from)
+	statement := `SELECT author_user_id, chat_id, birthday_user_id, birthday_date, birthday_person_name
+					FROM pending_wishes
+					WHERE author_user_id = $1 AND birthday_date >= $2
+					ORDER BY birthday_date, created_at`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, authorUserId, from); err != nil {
+		common.ErrorLogger.Printf("Failed to get pending wishes for authorUserId: %v from the database: %v\n", authorUserId, err)
+		return nil, err
+	}
+	var pendingWishes []birthday_bot.PendingWish
+	for rows.Next() {
+		var pendingWish birthday_bot.PendingWish
+		if err = rows.Scan(
+			&pendingWish.AuthorUserId,
+			&pendingWish.ChatId,
+			&pendingWish.BirthdayUserId,
+			&pendingWish.Date,
+			&pendingWish.BirthdayPersonName,
+		); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for pending wishes for authorUserId: %v due to: %v\n", authorUserId, err)
+			return pendingWishes, err
+		}
+		pendingWishes = append(pendingWishes, pendingWish)
+	}
+	return pendingWishes, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeletePendingWish(ctx context.Context, pendingWish birthday_bot.PendingWish) error {
+	log.Printf("Deleting pending wish from the database: %v\n", pendingWish)
+	statement := `DELETE FROM pending_wishes
+					WHERE author_user_id = $1 AND chat_id = $2 AND birthday_user_id = $3 AND birthday_date = $4`
+	if _, err := adapter.database.Exec(
+		ctx,
+		statement,
+		pendingWish.AuthorUserId,
+		pendingWish.ChatId,
+		pendingWish.BirthdayUserId,
+		pendingWish.Date,
+	); err != nil {
+		common.ErrorLogger.Printf("Failed to delete pending wish: %v from the database: %v\n", pendingWish, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveWish(ctx context.Context, wish birthday_bot.Wish) error {
+	log.Printf("Inserting wish into the database: %v\n", wish)
+	statement := `INSERT INTO wishes (chat_id, birthday_user_id, birthday_date, author_user_id, username, first_name, last_name, text, voice_file_id)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+						ON CONFLICT (chat_id, birthday_user_id, birthday_date, author_user_id) DO UPDATE SET
+						username = $5, first_name = $6, last_name = $7, text = $8, voice_file_id = $9`
+	if _, err := adapter.database.Exec(
+		ctx,
+		statement,
+		wish.ChatId,
+		wish.BirthdayUserId,
+		wish.Date,
+		wish.AuthorUserId,
+		wish.AuthorUsername,
+		wish.AuthorFirstName,
+		wish.AuthorLastName,
+		wish.Text,
+		wish.VoiceFileId,
+	); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a wish: %v into the database: %v\n", wish, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetWishes(ctx context.Context, chatId int64, birthdayUserId int64, date time.Time) ([]birthday_bot.Wish, error) {
+	log.Printf("Getting wishes from the database for chatId: %v, birthdayUserId: %v, date: %v\n", chatId, birthdayUserId, date)
+	statement := `SELECT chat_id, birthday_user_id, birthday_date, author_user_id, username, first_name, last_name, text, voice_file_id
+					FROM wishes
+					WHERE chat_id = $1 AND birthday_user_id = $2 AND birthday_date = $3
+					ORDER BY created_at`
+	var rows pgx.Rows
+	var err error
+	if rows, err = adapter.database.Query(ctx, statement, chatId, birthdayUserId, date); err != nil {
+		common.ErrorLogger.Printf("Failed to get wishes for chatId: %v, birthdayUserId: %v from the database: %v\n", chatId, birthdayUserId, err)
+		return nil, err
+	}
+	var wishes []birthday_bot.Wish
+	for rows.Next() {
+		var wish birthday_bot.Wish
+		if err = rows.Scan(
+			&wish.ChatId,
+			&wish.BirthdayUserId,
+			&wish.Date,
+			&wish.AuthorUserId,
+			&wish.AuthorUsername,
+			&wish.AuthorFirstName,
+			&wish.AuthorLastName,
+			&wish.Text,
+			&wish.VoiceFileId,
+		); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for wishes for chatId: %v, birthdayUserId: %v due to: %v\n", chatId, birthdayUserId, err)
+			return wishes, err
+		}
+		wishes = append(wishes, wish)
+	}
+	return wishes, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteWishesBefore(ctx context.Context, date time.Time) error {
+	log.Printf("Deleting wishes from the database before: %v\n", date)
+	for _, statement := range []string{
+		`DELETE FROM wishes WHERE birthday_date < $1`,
+		`DELETE FROM pending_wishes WHERE birthday_date < $1`,
+	} {
+		if _, err := adapter.database.Exec(ctx, statement, date); err != nil {
+			common.ErrorLogger.Printf("Failed to delete wishes before: %v from the database: %v\n", date, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllChatWishes(ctx context.Context, chatId int64) error {
+	log.Printf("Deleting wishes from the database for chatId: %v\n", chatId)
+	for _, statement := range []string{
+		`DELETE FROM wishes WHERE chat_id = $1`,
+		`DELETE FROM pending_wishes WHERE chat_id = $1`,
+	} {
+		if _, err := adapter.database.Exec(ctx, statement, chatId); err != nil {
+			common.ErrorLogger.Printf("Failed to delete all wishes for chatId: %v from the database: %v\n", chatId, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllUserWishes(ctx context.Context, userId int64) error {
+	log.Printf("Deleting wishes from the database for userId: %v\n", userId)
+	for _, statement := range []string{
+		`DELETE FROM wishes WHERE author_user_id = $1 OR birthday_user_id = $1`,
+		`DELETE FROM pending_wishes WHERE author_user_id = $1 OR birthday_user_id = $1`,
+		`DELETE FROM wish_contributors WHERE user_id = $1`,
+	} {
+		if _, err := adapter.database.Exec(ctx, statement, userId); err != nil {
+			common.ErrorLogger.Printf("Failed to delete all wishes for userId: %v from the database: %v\n", userId, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveWishlistItem(ctx context.Context, item birthday_bot.WishlistItem) error {
+	log.Printf("Inserting wishlist item into the database: %v\n", item)
+	statement := `INSERT INTO wishlist_items (user_id, chat_id, text, username, first_name, last_name)
+						VALUES ($1, $2, $3, $4, $5, $6)`
+	if _, err := adapter.database.Exec(
+		ctx,
+		statement,
+		item.UserId,
+		item.ChatId,
+		item.Text,
+		item.Username,
+		item.UserFirstName,
+		item.UserLastName,
+	); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a wishlist item: %v into the database: %v\n", item, err)
 		return err
 	}
 	return nil
 }
 
-func (adapter *PostgresRepositoryAdapter) getClosestBirthdaysAfterAdjustedDay(ctx context.Context, chatId int64, day int) ([]birthday_bot.Birthday, error) {
+func (adapter *PostgresRepositoryAdapter) GetWishlistItems(ctx context.Context, userId int64, chatId int64) ([]birthday_bot.WishlistItem, error) {
+	log.Printf("Getting wishlist items from the database for userId: %v, chatId: %v\n", userId, chatId)
+	statement := `SELECT id, user_id, chat_id, text, claimed_by, username, first_name, last_name
+					FROM wishlist_items
+					WHERE user_id = $1 AND chat_id IN ($2, $3)
+					ORDER BY id`
+	rows, err := adapter.database.Query(ctx, statement, userId, chatId, birthday_bot.WISHLIST_SCOPE_GLOBAL)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get wishlist items for userId: %v, chatId: %v from the database: %v\n", userId, chatId, err)
+		return nil, err
+	}
+	return scanWishlistItems(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) GetAllUserWishlistItems(ctx context.Context, userId int64) ([]birthday_bot.WishlistItem, error) {
+	log.Printf("Getting all wishlist items from the database for userId: %v\n", userId)
+	statement := `SELECT id, user_id, chat_id, text, claimed_by, username, first_name, last_name
+					FROM wishlist_items
+					WHERE user_id = $1
+					ORDER BY id`
+	rows, err := adapter.database.Query(ctx, statement, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get all wishlist items for userId: %v from the database: %v\n", userId, err)
+		return nil, err
+	}
+	return scanWishlistItems(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) GetWishlistItem(ctx context.Context, itemId int64) (*birthday_bot.WishlistItem, error) {
+	log.Printf("Getting wishlist item from the database for itemId: %v\n", itemId)
+	statement := `SELECT id, user_id, chat_id, text, claimed_by, username, first_name, last_name
+					FROM wishlist_items
+					WHERE id = $1`
+	rows, err := adapter.database.Query(ctx, statement, itemId)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get wishlist item: %v from the database: %v\n", itemId, err)
+		return nil, err
+	}
+	items, err := scanWishlistItems(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+func (adapter *PostgresRepositoryAdapter) UpdateWishlistItemClaim(ctx context.Context, itemId int64, expectedClaimedBy int64, claimedBy int64) (bool, error) {
+	log.Printf("Updating claim of wishlist item: %v in the database\n", itemId)
+	statement := `UPDATE wishlist_items SET claimed_by = $3
+					WHERE id = $1 AND claimed_by IS NOT DISTINCT FROM $2`
+	result, err := adapter.database.Exec(ctx, statement, itemId, nullableClaimedBy(expectedClaimedBy), nullableClaimedBy(claimedBy))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to update claim of wishlist item: %v in the database: %v\n", itemId, err)
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteWishlistItem(ctx context.Context, itemId int64) error {
+	log.Printf("Deleting wishlist item from the database: %v\n", itemId)
+	statement := `DELETE FROM wishlist_items WHERE id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, itemId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete wishlist item: %v from the database: %v\n", itemId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteChatUserWishlistItems(ctx context.Context, chatId int64, userId int64) error {
+	log.Printf("Deleting wishlist items from the database for chatId: %v, userId: %v\n", chatId, userId)
+	statement := `DELETE FROM wishlist_items WHERE chat_id = $1 AND user_id = $2`
+	if _, err := adapter.database.Exec(ctx, statement, chatId, userId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete wishlist items for chatId: %v, userId: %v from the database: %v\n", chatId, userId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllChatWishlistItems(ctx context.Context, chatId int64) error {
+	log.Printf("Deleting wishlist items from the database for chatId: %v\n", chatId)
+	statement := `DELETE FROM wishlist_items WHERE chat_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete all wishlist items for chatId: %v from the database: %v\n", chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllUserWishlistItems(ctx context.Context, userId int64) error {
+	log.Printf("Deleting wishlist items from the database for userId: %v\n", userId)
+	for _, statement := range []string{
+		`DELETE FROM wishlist_items WHERE user_id = $1`,
+		`UPDATE wishlist_items SET claimed_by = NULL WHERE claimed_by = $1`,
+	} {
+		if _, err := adapter.database.Exec(ctx, statement, userId); err != nil {
+			common.ErrorLogger.Printf("Failed to delete all wishlist items for userId: %v from the database: %v\n", userId, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveBirthdayVideo(ctx context.Context, video birthday_bot.BirthdayVideo) error {
+	log.Printf("Inserting birthday video into the database: %v\n", video)
+	statement := `INSERT INTO birthday_videos (user_id, chat_id, year, file_id)
+						VALUES ($1, $2, $3, $4)
+						ON CONFLICT (user_id, chat_id, year)
+						DO UPDATE SET file_id = EXCLUDED.file_id, created_at = NOW()`
+	if _, err := adapter.database.Exec(ctx, statement, video.UserId, video.ChatId, video.Year, video.FileId); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a birthday video: %v into the database: %v\n", video, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetUserBirthdayVideos(ctx context.Context, userId int64) ([]birthday_bot.BirthdayVideo, error) {
+	log.Printf("Getting birthday videos from the database for userId: %v\n", userId)
+	statement := `SELECT id, chat_id, user_id, year, file_id
+					FROM birthday_videos
+					WHERE user_id = $1
+					ORDER BY year DESC, id DESC`
+	rows, err := adapter.database.Query(ctx, statement, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get birthday videos for userId: %v from the database: %v\n", userId, err)
+		return nil, err
+	}
+	return scanBirthdayVideos(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) GetBirthdayVideo(ctx context.Context, videoId int64) (*birthday_bot.BirthdayVideo, error) {
+	log.Printf("Getting birthday video from the database for videoId: %v\n", videoId)
+	statement := `SELECT id, chat_id, user_id, year, file_id
+					FROM birthday_videos
+					WHERE id = $1`
+	rows, err := adapter.database.Query(ctx, statement, videoId)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get birthday video: %v from the database: %v\n", videoId, err)
+		return nil, err
+	}
+	videos, err := scanBirthdayVideos(rows)
+	if err != nil || len(videos) == 0 {
+		return nil, err
+	}
+	return &videos[0], nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllChatBirthdayVideos(ctx context.Context, chatId int64) error {
+	log.Printf("Deleting birthday videos from the database for chatId: %v\n", chatId)
+	statement := `DELETE FROM birthday_videos WHERE chat_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete all birthday videos for chatId: %v from the database: %v\n", chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllUserBirthdayVideos(ctx context.Context, userId int64) error {
+	log.Printf("Deleting birthday videos from the database for userId: %v\n", userId)
+	for _, statement := range []string{
+		`DELETE FROM birthday_videos WHERE user_id = $1`,
+		`DELETE FROM cached_videos WHERE user_id = $1`,
+	} {
+		if _, err := adapter.database.Exec(ctx, statement, userId); err != nil {
+			common.ErrorLogger.Printf("Failed to delete all birthday videos for userId: %v from the database: %v\n", userId, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveCachedVideo(ctx context.Context, cachedVideo birthday_bot.CachedVideo) error {
+	log.Printf("Inserting cached video into the database: %v\n", cachedVideo)
+	statement := `INSERT INTO cached_videos (user_id, picture_unique_id, file_id)
+						VALUES ($1, $2, $3)
+						ON CONFLICT (user_id, picture_unique_id)
+						DO UPDATE SET file_id = EXCLUDED.file_id, created_at = NOW()`
+	if _, err := adapter.database.Exec(ctx, statement, cachedVideo.UserId, cachedVideo.PictureUniqueId, cachedVideo.FileId); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a cached video: %v into the database: %v\n", cachedVideo, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetCachedVideoFileId(ctx context.Context, userId int64, pictureUniqueId string) (string, error) {
+	log.Printf("Getting cached video from the database for userId: %v, pictureUniqueId: %v\n", userId, pictureUniqueId)
+	statement := `SELECT file_id FROM cached_videos WHERE user_id = $1 AND picture_unique_id = $2`
+	var fileId string
+	err := adapter.database.QueryRow(ctx, statement, userId, pictureUniqueId).Scan(&fileId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get cached video for userId: %v from the database: %v\n", userId, err)
+		return "", err
+	}
+	return fileId, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveCustomPicture(ctx context.Context, picture birthday_bot.CustomPicture) error {
+	log.Printf("Inserting custom picture into the database: %v\n", picture)
+	statement := `INSERT INTO custom_pictures (user_id, chat_id, file_id, file_unique_id)
+						VALUES ($1, $2, $3, $4)
+						ON CONFLICT (user_id, chat_id)
+						DO UPDATE SET file_id = EXCLUDED.file_id, file_unique_id = EXCLUDED.file_unique_id, created_at = NOW()`
+	if _, err := adapter.database.Exec(ctx, statement, picture.UserId, picture.ChatId, picture.FileId, picture.FileUniqueId); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a custom picture: %v into the database: %v\n", picture, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetCustomPicture(ctx context.Context, userId int64, chatId int64) (*birthday_bot.CustomPicture, error) {
+	log.Printf("Getting custom picture from the database for userId: %v, chatId: %v\n", userId, chatId)
+	statement := `SELECT user_id, chat_id, file_id, file_unique_id
+					FROM custom_pictures
+					WHERE user_id = $1 AND chat_id IN ($2, $3)
+					ORDER BY chat_id = $3
+					LIMIT 1`
+	var picture birthday_bot.CustomPicture
+	err := adapter.database.QueryRow(ctx, statement, userId, chatId, birthday_bot.CUSTOM_PICTURE_SCOPE_GLOBAL).
+		Scan(&picture.UserId, &picture.ChatId, &picture.FileId, &picture.FileUniqueId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get custom picture for userId: %v, chatId: %v from the database: %v\n", userId, chatId, err)
+		return nil, err
+	}
+	return &picture, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteCustomPicture(ctx context.Context, userId int64, chatId int64) error {
+	log.Printf("Deleting custom picture from the database for userId: %v, chatId: %v\n", userId, chatId)
+	statement := `DELETE FROM custom_pictures WHERE user_id = $1 AND chat_id = $2`
+	if _, err := adapter.database.Exec(ctx, statement, userId, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete custom picture for userId: %v, chatId: %v from the database: %v\n", userId, chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllChatCustomPictures(ctx context.Context, chatId int64) error {
+	log.Printf("Deleting custom pictures from the database for chatId: %v\n", chatId)
+	statement := `DELETE FROM custom_pictures WHERE chat_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, chatId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete custom pictures for chatId: %v from the database: %v\n", chatId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllUserCustomPictures(ctx context.Context, userId int64) error {
+	log.Printf("Deleting custom pictures from the database for userId: %v\n", userId)
+	statement := `DELETE FROM custom_pictures WHERE user_id = $1`
+	if _, err := adapter.database.Exec(ctx, statement, userId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete custom pictures for userId: %v from the database: %v\n", userId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveDeliveryStep(ctx context.Context, step birthday_bot.DeliveryStep) error {
+	log.Printf("Inserting delivery step into the database: %v\n", step)
+	statement := `INSERT INTO delivery_steps (chat_id, user_id, date, kind, step, completed_at)
+						VALUES ($1, $2, $3, $4, $5, $6)
+						ON CONFLICT (chat_id, user_id, date, kind, step) DO NOTHING`
+	if _, err := adapter.database.Exec(ctx, statement, step.ChatId, step.UserId, step.Date, step.Kind, step.Step, step.CompletedAt); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a delivery step: %v into the database: %v\n", step, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetDeliverySteps(ctx context.Context, query birthday_bot.DeliveryQuery) ([]birthday_bot.DeliveryStep, error) {
+	log.Printf("Getting delivery steps from the database for query: %v\n", query)
+	statement := `SELECT chat_id, user_id, date, kind, step, completed_at
+					FROM delivery_steps
+					WHERE date = $1
+					AND ($2::BIGINT = 0 OR chat_id = $2)
+					AND ($3::BIGINT = 0 OR user_id = $3)
+					AND ($4::TEXT = '' OR kind = $4)
+					ORDER BY completed_at, chat_id, user_id`
+	rows, err := adapter.database.Query(ctx, statement, query.Date, query.ChatId, query.UserId, query.Kind)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get delivery steps for query: %v from the database: %v\n", query, err)
+		return nil, err
+	}
+	defer rows.Close()
+	var steps []birthday_bot.DeliveryStep
+	for rows.Next() {
+		var step birthday_bot.DeliveryStep
+		if err := rows.Scan(&step.ChatId, &step.UserId, &step.Date, &step.Kind, &step.Step, &step.CompletedAt); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for delivery steps due to: %v\n", err)
+			return steps, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteAllUserDeliverySteps(ctx context.Context, userId int64) error {
+	log.Printf("Deleting delivery steps from the database for userId: %v\n", userId)
+	if _, err := adapter.database.Exec(ctx, `DELETE FROM delivery_steps WHERE user_id = $1`, userId); err != nil {
+		common.ErrorLogger.Printf("Failed to delete all delivery steps for userId: %v from the database: %v\n", userId, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetLastScheduledDate(ctx context.Context) (time.Time, error) {
+	log.Printf("Getting the last scheduled date from the database\n")
+	var date *time.Time
+	if err := adapter.database.QueryRow(ctx, `SELECT MAX(date) FROM scheduled_dates`).Scan(&date); err != nil {
+		common.ErrorLogger.Printf("Failed to get the last scheduled date from the database: %v\n", err)
+		return time.Time{}, err
+	}
+	if date == nil {
+		return time.Time{}, nil
+	}
+	return *date, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveScheduledDate(ctx context.Context, date time.Time) error {
+	log.Printf("Inserting scheduled date into the database: %v\n", date)
+	statement := `INSERT INTO scheduled_dates (date) VALUES ($1) ON CONFLICT (date) DO NOTHING`
+	if _, err := adapter.database.Exec(ctx, statement, date); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a scheduled date: %v into the database: %v\n", date, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) SaveNotificationJob(ctx context.Context, job birthday_bot.NotificationJob) error {
+	log.Printf("Inserting notification job into the database: %v\n", job)
+	statement := `INSERT INTO notification_jobs (task_id, notification, status, run_at)
+						VALUES ($1, $2, $3, $4)
+						ON CONFLICT (task_id) DO NOTHING`
+	if _, err := adapter.database.Exec(ctx, statement, job.TaskId, job.Notification, birthday_bot.JOB_STATUS_PENDING, job.RunAt); err != nil {
+		common.ErrorLogger.Printf("Failed to insert a notification job: %v into the database: %v\n", job, err)
+		return err
+	}
+	return nil
+}
+
+func (adapter *PostgresRepositoryAdapter) ClaimNotificationJobs(ctx context.Context, now time.Time, lockedUntil time.Time, limit int, maxAttempts int) ([]birthday_bot.NotificationJob, error) {
+	log.Printf("Claiming up to %v notification jobs in the database\n", limit)
+	statement := `WITH expired_jobs AS (
+						UPDATE notification_jobs
+						SET status = $6, last_error = $8, locked_until = NULL, updated_at = $3
+						WHERE status = $1 AND locked_until <= $3 AND attempts >= $7
+					)
+					UPDATE notification_jobs
+					SET status = $1, attempts = attempts + 1, locked_until = $2, updated_at = $3
+					WHERE id IN (
+						SELECT id FROM notification_jobs
+						WHERE (status = $4 AND run_at <= $3) OR (status = $1 AND locked_until <= $3 AND attempts < $7)
+						ORDER BY run_at
+						LIMIT $5
+						FOR UPDATE SKIP LOCKED
+					)
+					RETURNING id, task_id, notification, status, attempts, run_at, COALESCE(last_error, '')`
+	rows, err := adapter.database.Query(
+		ctx,
+		statement,
+		birthday_bot.JOB_STATUS_RUNNING,
+		lockedUntil,
+		now,
+		birthday_bot.JOB_STATUS_PENDING,
+		limit,
+		birthday_bot.JOB_STATUS_DEAD,
+		maxAttempts,
+		birthday_bot.JOB_LEASE_EXPIRED_ERROR,
+	)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to claim notification jobs in the database: %v\n", err)
+		return nil, err
+	}
+	return scanNotificationJobs(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) CompleteNotificationJob(ctx context.Context, jobId int64, attempts int) (bool, error) {
+	log.Printf("Completing notification job: %v after %v attempts in the database\n", jobId, attempts)
+	statement := `UPDATE notification_jobs
+					SET status = $1, locked_until = NULL, updated_at = NOW()
+					WHERE id = $2 AND status = $3 AND attempts = $4`
+	result, err := adapter.database.Exec(ctx, statement, birthday_bot.JOB_STATUS_DONE, jobId, birthday_bot.JOB_STATUS_RUNNING, attempts)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to complete notification job: %v in the database: %v\n", jobId, err)
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) FailNotificationJob(ctx context.Context, failure birthday_bot.JobFailure) (bool, error) {
+	log.Printf("Failing notification job in the database: %v\n", failure)
+	status, runAt := birthday_bot.JOB_STATUS_PENDING, &failure.RetryAt
+	if failure.Dead {
+		status, runAt = birthday_bot.JOB_STATUS_DEAD, nil
+	}
+	statement := `UPDATE notification_jobs
+					SET status = $1, run_at = COALESCE($2, run_at), last_error = $3, locked_until = NULL, updated_at = NOW()
+					WHERE id = $4 AND status = $5 AND attempts = $6`
+	result, err := adapter.database.Exec(ctx, statement, status, runAt, failure.Error, failure.Id, birthday_bot.JOB_STATUS_RUNNING, failure.Attempts)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to fail notification job: %v in the database: %v\n", failure.Id, err)
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (adapter *PostgresRepositoryAdapter) GetNotificationJobs(ctx context.Context, status birthday_bot.JobStatus) ([]birthday_bot.NotificationJob, error) {
+	log.Printf("Getting notification jobs with status: %v from the database\n", status)
+	statement := `SELECT id, task_id, notification, status, attempts, run_at, COALESCE(last_error, '')
+					FROM notification_jobs
+					WHERE status = $1
+					ORDER BY run_at`
+	rows, err := adapter.database.Query(ctx, statement, status)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get notification jobs with status: %v from the database: %v\n", status, err)
+		return nil, err
+	}
+	return scanNotificationJobs(rows)
+}
+
+func (adapter *PostgresRepositoryAdapter) DeleteNotificationJobsFinishedBefore(ctx context.Context, date time.Time) error {
+	log.Printf("Deleting notification jobs finished before: %v from the database\n", date)
+	statement := `DELETE FROM notification_jobs WHERE status IN ($1, $2) AND updated_at < $3`
+	if _, err := adapter.database.Exec(ctx, statement, birthday_bot.JOB_STATUS_DONE, birthday_bot.JOB_STATUS_DEAD, date); err != nil {
+		common.ErrorLogger.Printf("Failed to delete notification jobs finished before: %v from the database: %v\n", date, err)
+		return err
+	}
+	return nil
+}
+
+func scanNotificationJobs(rows pgx.Rows) ([]birthday_bot.NotificationJob, error) {
+	defer rows.Close()
+	var jobs []birthday_bot.NotificationJob
+	for rows.Next() {
+		var job birthday_bot.NotificationJob
+		if err := rows.Scan(&job.Id, &job.TaskId, &job.Notification, &job.Status, &job.Attempts, &job.RunAt, &job.LastError); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for notification jobs due to: %v\n", err)
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanBirthdayVideos(rows pgx.Rows) ([]birthday_bot.BirthdayVideo, error) {
+	defer rows.Close()
+	var videos []birthday_bot.BirthdayVideo
+	for rows.Next() {
+		var video birthday_bot.BirthdayVideo
+		if err := rows.Scan(&video.Id, &video.ChatId, &video.UserId, &video.Year, &video.FileId); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for birthday videos due to: %v\n", err)
+			return videos, err
+		}
+		videos = append(videos, video)
+	}
+	return videos, rows.Err()
+}
+
+func scanWishlistItems(rows pgx.Rows) ([]birthday_bot.WishlistItem, error) {
+	defer rows.Close()
+	var items []birthday_bot.WishlistItem
+	for rows.Next() {
+		var item birthday_bot.WishlistItem
+		var claimedBy *int64
+		if err := rows.Scan(
+			&item.Id,
+			&item.UserId,
+			&item.ChatId,
+			&item.Text,
+			&claimedBy,
+			&item.Username,
+			&item.UserFirstName,
+			&item.UserLastName,
+		); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for wishlist items due to: %v\n", err)
+			return items, err
+		}
+		if claimedBy != nil {
+			item.ClaimedBy = *claimedBy
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func scanEvents(rows pgx.Rows) ([]birthday_bot.Event, error) {
+	defer rows.Close()
+	var events []birthday_bot.Event
+	for rows.Next() {
+		var event birthday_bot.Event
+		var year *int
+		if err := rows.Scan(&event.ChatId, &event.Name, &event.Kind, &event.Date, &year); err != nil {
+			common.ErrorLogger.Printf("Failed to scan rows for events due to: %v\n", err)
+			return events, err
+		}
+		if year != nil {
+			event.Year = *year
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// getClosestBirthdaysAfterAdjustedDay moves leap day birthdays according to the chat's leap day policy
+// when the year of the searched birthdays has no February 29th.
+func (adapter *PostgresRepositoryAdapter) getClosestBirthdaysAfterAdjustedDay(ctx context.Context, chatId int64, day int, isLeapYear bool) ([]birthday_bot.Birthday, error) {
 	log.Printf("Getting closest birthdays from the database for chatId: %v, day: %v\n", chatId, day)
-	statement := `WITH closest_birthday AS (
-						SELECT adjusted_day_of_year
-						FROM birthdays
-						WHERE chat_id = $1 AND adjusted_day_of_year > $2 ORDER BY adjusted_day_of_year LIMIT 1)
+	statement := `WITH celebrated_birthdays AS (
+						SELECT b.*,
+							CASE WHEN b.adjusted_day_of_year = $3 AND NOT $4 THEN
+								CASE COALESCE(s.leap_day_policy, $5) WHEN $6 THEN $3 + 1 ELSE $3 - 1 END
+							ELSE b.adjusted_day_of_year END AS celebrated_day
+						FROM birthdays b
+						LEFT JOIN chat_settings s ON s.chat_id = b.chat_id
+						WHERE b.chat_id = $1),
+					closest_birthday AS (
+						SELECT celebrated_day
+						FROM celebrated_birthdays
+						WHERE celebrated_day > $2 ORDER BY celebrated_day LIMIT 1)
 				SELECT chat_id, user_id, date, username, first_name, last_name
-				FROM birthdays
-				WHERE chat_id = $1 AND adjusted_day_of_year = (SELECT adjusted_day_of_year FROM closest_birthday)`
+				FROM celebrated_birthdays
+				WHERE celebrated_day = (SELECT celebrated_day FROM closest_birthday)`
 	var rows pgx.Rows
 	var err error
-	if rows, err = adapter.database.Query(ctx, statement, chatId, day); err != nil {
+	if rows, err = adapter.database.Query(
+		ctx,
+		statement,
+		chatId,
+		day,
+		FEBRUARY_29TH_YEAR_DAY,
+		isLeapYear,
+		birthday_bot.DEFAULT_LEAP_DAY_POLICY,
+		birthday_bot.LEAP_DAY_POLICY_MARCH_1,
+	); err != nil {
 		common.ErrorLogger.Printf("Failed to get closest birthdays for chat: %v, day: %v from the database: %v\n", chatId, day, err)
 		return nil, err
 	}
@@ -177,8 +1366,39 @@ func getAdjustedDayOfYear(date time.Time) int {
 	return yearDay
 }
 
+func nullableYear(year int) *int {
+	if year == birthday_bot.UNKNOWN_YEAR {
+		return nil
+	}
+	return &year
+}
+
+func nullableMessageId(messageId int) *int {
+	if messageId == birthday_bot.NO_BOARD_MESSAGE_ID {
+		return nil
+	}
+	return &messageId
+}
+
+func nullableCountry(country string) *string {
+	if country == birthday_bot.NAME_DAY_COUNTRY_NONE {
+		return nil
+	}
+	return &country
+}
+
+func nullableClaimedBy(userId int64) *int64 {
+	if userId == birthday_bot.NOT_CLAIMED {
+		return nil
+	}
+	return &userId
+}
+
 func isLeapYear(year int) bool {
 	return year%4 == 0 && year%100 != 0 || year%400 == 0
 }
 
-const FEBRUARY_28TH_YEAR_DAY = 59
+const (
+	FEBRUARY_28TH_YEAR_DAY = 59
+	FEBRUARY_29TH_YEAR_DAY = 60
+)
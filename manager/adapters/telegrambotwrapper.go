@@ -3,15 +3,29 @@ package adapters
 import (
 	"context"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/4Kaze/birthdaybot/common"
+	"github.com/4Kaze/birthdaybot/manager/core"
 	telegram "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
+const (
+	INLINE_BUTTONS_PER_ROW = 5
+)
+
+// Telegram answers both with 400 Bad Request, the description is the only thing that tells them apart.
+const (
+	DESCRIPTION_MESSAGE_NOT_MODIFIED      = "Bad Request: message is not modified"
+	DESCRIPTION_MESSAGE_TO_EDIT_NOT_FOUND = "Bad Request: message to edit not found"
+)
+
 type TelegramBotWrapper struct {
-	bot   *telegram.Bot
-	BotId int64
+	bot    *telegram.Bot
+	sender *common.TelegramSender
+	BotId  int64
 }
 
 func NewTelegramWrapper(ctx context.Context, bot *telegram.Bot) *TelegramBotWrapper {
@@ -20,17 +34,21 @@ func NewTelegramWrapper(ctx context.Context, bot *telegram.Bot) *TelegramBotWrap
 		panic(err)
 	}
 	return &TelegramBotWrapper{
-		bot:   bot,
-		BotId: botUser.ID,
+		bot:    bot,
+		sender: common.NewTelegramSender(common.ParseTelegramErrorResponse),
+		BotId:  botUser.ID,
 	}
 }
 
 func (wrapper *TelegramBotWrapper) SendMessage(ctx context.Context, chatId int64, text string) error {
 	log.Printf("Sending message to chatId: %v, text: %v\n", chatId, text)
-	_, err := wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
-		ChatID:    chatId,
-		Text:      text,
-		ParseMode: models.ParseModeHTML,
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
+			ChatID:    chatId,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		return err
 	})
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to send message: %v to chatId: %v due to: %v\n", text, chatId, err)
@@ -38,16 +56,130 @@ func (wrapper *TelegramBotWrapper) SendMessage(ctx context.Context, chatId int64
 	return err
 }
 
-func (wrapper *TelegramBotWrapper) SendReply(ctx context.Context, chatId int64, messageId int, text string) error {
-	log.Printf("Sending replay to chatId: %v, messageId: %v, text: %v\n", chatId, messageId, text)
-	_, err := wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
+func (wrapper *TelegramBotWrapper) SendMessageAndGetId(ctx context.Context, chatId int64, text string) (int, error) {
+	log.Printf("Sending message to chatId: %v, text: %v\n", chatId, text)
+	var message *models.Message
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) (err error) {
+		message, err = wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
+			ChatID:    chatId,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send message: %v to chatId: %v due to: %v\n", text, chatId, err)
+		return 0, err
+	}
+	return message.ID, nil
+}
+
+func (wrapper *TelegramBotWrapper) EditMessage(ctx context.Context, chatId int64, messageId int, text string) error {
+	log.Printf("Editing messageId: %v in chatId: %v, text: %v\n", messageId, chatId, text)
+	return wrapper.editMessage(ctx, chatId, &telegram.EditMessageTextParams{
 		ChatID:    chatId,
+		MessageID: messageId,
 		Text:      text,
 		ParseMode: models.ParseModeHTML,
-		ReplyParameters: &models.ReplyParameters{
+	})
+}
+
+func (wrapper *TelegramBotWrapper) EditMessageWithButtons(ctx context.Context, chatId int64, messageId int, text string, buttons []core.InlineButton) error {
+	log.Printf("Editing messageId: %v in chatId: %v, text: %v, buttons: %v\n", messageId, chatId, text, buttons)
+	return wrapper.editMessage(ctx, chatId, &telegram.EditMessageTextParams{
+		ChatID:      chatId,
+		MessageID:   messageId,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: createInlineKeyboard(buttons),
+	})
+}
+
+func (wrapper *TelegramBotWrapper) editMessage(ctx context.Context, chatId int64, params *telegram.EditMessageTextParams) error {
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.EditMessageText(ctx, params)
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if telegramError := common.ParseTelegramError(err); telegramError != nil && telegramError.StatusCode == http.StatusBadRequest {
+		if strings.HasPrefix(telegramError.Description, DESCRIPTION_MESSAGE_NOT_MODIFIED) {
+			return nil
+		}
+		if strings.HasPrefix(telegramError.Description, DESCRIPTION_MESSAGE_TO_EDIT_NOT_FOUND) {
+			return core.ErrMessageNotFound
+		}
+	}
+	common.ErrorLogger.Printf("Failed to edit messageId: %v in chatId: %v due to: %v\n", params.MessageID, params.ChatID, err)
+	return err
+}
+
+func (wrapper *TelegramBotWrapper) PinMessage(ctx context.Context, chatId int64, messageId int) error {
+	log.Printf("Pinning messageId: %v in chatId: %v\n", messageId, chatId)
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.PinChatMessage(ctx, &telegram.PinChatMessageParams{
+			ChatID:              chatId,
+			MessageID:           messageId,
+			DisableNotification: true,
+		})
+		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to pin messageId: %v in chatId: %v due to: %v\n", messageId, chatId, err)
+	}
+	return err
+}
+
+func (wrapper *TelegramBotWrapper) UnpinMessage(ctx context.Context, chatId int64, messageId int) error {
+	log.Printf("Unpinning messageId: %v in chatId: %v\n", messageId, chatId)
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.UnpinChatMessage(ctx, &telegram.UnpinChatMessageParams{
 			ChatID:    chatId,
 			MessageID: messageId,
-		},
+		})
+		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to unpin messageId: %v in chatId: %v due to: %v\n", messageId, chatId, err)
+	}
+	return err
+}
+
+func (wrapper *TelegramBotWrapper) SendReply(ctx context.Context, chatId int64, messageId int, text string) error {
+	log.Printf("Sending replay to chatId: %v, messageId: %v, text: %v\n", chatId, messageId, text)
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
+			ChatID:    chatId,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+			ReplyParameters: &models.ReplyParameters{
+				ChatID:    chatId,
+				MessageID: messageId,
+			},
+		})
+		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send reply: %v to messageId: %v in chatId: %v due to: %v\n", text, messageId, chatId, err)
+	}
+	return err
+}
+
+func (wrapper *TelegramBotWrapper) SendReplyWithButtons(ctx context.Context, chatId int64, messageId int, text string, buttons []core.InlineButton) error {
+	log.Printf("Sending replay to chatId: %v, messageId: %v, text: %v, buttons: %v\n", chatId, messageId, text, buttons)
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
+			ChatID:    chatId,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+			ReplyParameters: &models.ReplyParameters{
+				ChatID:    chatId,
+				MessageID: messageId,
+			},
+			ReplyMarkup: createInlineKeyboard(buttons),
+		})
+		return err
 	})
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to send reply: %v to messageId: %v in chatId: %v due to: %v\n", text, messageId, chatId, err)
@@ -55,22 +187,86 @@ func (wrapper *TelegramBotWrapper) SendReply(ctx context.Context, chatId int64,
 	return err
 }
 
+func (wrapper *TelegramBotWrapper) AnswerCallback(ctx context.Context, callbackId string, text string) error {
+	log.Printf("Answering callbackId: %v, text: %v\n", callbackId, text)
+	err := wrapper.sender.Call(ctx, func(ctx context.Context) error {
+		_, err := wrapper.bot.AnswerCallbackQuery(ctx, &telegram.AnswerCallbackQueryParams{
+			CallbackQueryID: callbackId,
+			Text:            text,
+		})
+		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to answer callbackId: %v due to: %v\n", callbackId, err)
+	}
+	return err
+}
+
 func (wrapper *TelegramBotWrapper) SendReaction(ctx context.Context, chatId int64, messageId int, reaction string) error {
 	log.Printf("Sending reaction to chatId: %v, messageId: %v, reaction: %v\n", chatId, messageId, reaction)
-	_, err := wrapper.bot.SetMessageReaction(ctx, &telegram.SetMessageReactionParams{
-		ChatID:    chatId,
-		MessageID: messageId,
-		Reaction: []models.ReactionType{
-			{
-				Type: models.ReactionTypeTypeEmoji,
-				ReactionTypeEmoji: &models.ReactionTypeEmoji{
-					Emoji: reaction,
+	err := wrapper.sender.Call(ctx, func(ctx context.Context) error {
+		_, err := wrapper.bot.SetMessageReaction(ctx, &telegram.SetMessageReactionParams{
+			ChatID:    chatId,
+			MessageID: messageId,
+			Reaction: []models.ReactionType{
+				{
+					Type: models.ReactionTypeTypeEmoji,
+					ReactionTypeEmoji: &models.ReactionTypeEmoji{
+						Emoji: reaction,
+					},
 				},
 			},
-		},
+		})
+		return err
 	})
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to send reaction: %v to messageId: %v in chatId: %v due to: %v\n", reaction, messageId, chatId, err)
 	}
 	return err
 }
+
+func (wrapper *TelegramBotWrapper) SendVideoFromFileId(ctx context.Context, chatId int64, fileId string) error {
+	log.Printf("Sending video to chatId: %v, fileId: %v\n", chatId, fileId)
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.SendVideo(ctx, &telegram.SendVideoParams{
+			ChatID: chatId,
+			Video:  &models.InputFileString{Data: fileId},
+		})
+		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send video: %v to chatId: %v due to: %v\n", fileId, chatId, err)
+	}
+	return err
+}
+
+func (wrapper *TelegramBotWrapper) IsChatAdmin(ctx context.Context, chatId int64, userId int64) (bool, error) {
+	log.Printf("Checking if userId: %v is an admin in chatId: %v\n", userId, chatId)
+	var member *models.ChatMember
+	err := wrapper.sender.Call(ctx, func(ctx context.Context) (err error) {
+		member, err = wrapper.bot.GetChatMember(ctx, &telegram.GetChatMemberParams{
+			ChatID: chatId,
+			UserID: userId,
+		})
+		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to get chat member: %v in chatId: %v due to: %v\n", userId, chatId, err)
+		return false, err
+	}
+	return member.Type == models.ChatMemberTypeOwner || member.Type == models.ChatMemberTypeAdministrator, nil
+}
+
+func createInlineKeyboard(buttons []core.InlineButton) *models.InlineKeyboardMarkup {
+	var rows [][]models.InlineKeyboardButton
+	for index, button := range buttons {
+		if index%INLINE_BUTTONS_PER_ROW == 0 {
+			rows = append(rows, []models.InlineKeyboardButton{})
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], models.InlineKeyboardButton{
+			Text:         button.Text,
+			CallbackData: button.Data,
+		})
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+const NO_BOARD_MESSAGE_ID = 0
+
+func (birthdayBot *BirthdayManager) createBoard(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	if isAdmin, err := birthdayBot.ensureAdmin(ctx, update, MESSAGE_BOARD_ADMIN_ONLY); !isAdmin {
+		return err
+	}
+
+	previousBoardMessageId, err := birthdayBot.repository.GetBoardMessageId(ctx, chatId)
+	if err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	text, err := birthdayBot.createBoardText(ctx, chatId)
+	if err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	boardMessageId, err := birthdayBot.telegram.SendMessageAndGetId(ctx, chatId, text)
+	if err != nil {
+		return err
+	}
+	if err = birthdayBot.repository.SaveBoardMessageId(ctx, chatId, boardMessageId); err != nil {
+		common.ErrorLogger.Printf("could not save board message id for chat: %v due to: %v\n", chatId, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	birthdayBot.unpinPreviousBoard(ctx, chatId, previousBoardMessageId)
+	if err = birthdayBot.telegram.PinMessage(ctx, chatId, boardMessageId); err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_BOARD_PIN_FAILURE)
+	}
+	return nil
+}
+
+// unpinPreviousBoard unpins the board replaced by a new one. The old board is no longer refreshed,
+// so a failure is only logged, e.g. when the message was already deleted.
+func (birthdayBot *BirthdayManager) unpinPreviousBoard(ctx context.Context, chatId int64, boardMessageId int) {
+	if boardMessageId == NO_BOARD_MESSAGE_ID {
+		return
+	}
+	if err := birthdayBot.telegram.UnpinMessage(ctx, chatId, boardMessageId); err != nil {
+		common.ErrorLogger.Printf("could not unpin previous board message: %v in chat: %v due to: %v\n", boardMessageId, chatId, err)
+	}
+}
+
+// RefreshBoards updates pinned birthday boards of every chat that has one.
+// A failure in one chat doesn't stop the other boards from being refreshed.
+func (birthdayBot *BirthdayManager) RefreshBoards(ctx context.Context) error {
+	chatIds, err := birthdayBot.repository.GetChatIdsWithBoards(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, chatId := range chatIds {
+		if err := birthdayBot.refreshBoard(ctx, chatId); err != nil {
+			errs = append(errs, fmt.Errorf("could not refresh board in chat: %v due to: %w", chatId, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (birthdayBot *BirthdayManager) refreshBoard(ctx context.Context, chatId int64) error {
+	boardMessageId, err := birthdayBot.repository.GetBoardMessageId(ctx, chatId)
+	if err != nil {
+		return err
+	}
+	if boardMessageId == NO_BOARD_MESSAGE_ID {
+		return nil
+	}
+	text, err := birthdayBot.createBoardText(ctx, chatId)
+	if err != nil {
+		return err
+	}
+	err = birthdayBot.telegram.EditMessage(ctx, chatId, boardMessageId, text)
+	if errors.Is(err, ErrMessageNotFound) {
+		common.ErrorLogger.Printf("board message: %v in chat: %v was deleted, forgetting about it\n", boardMessageId, chatId)
+		return birthdayBot.repository.SaveBoardMessageId(ctx, chatId, NO_BOARD_MESSAGE_ID)
+	}
+	return err
+}
+
+// refreshBoardAfterChange is used after birthdays change, when the change itself already succeeded,
+// so a failure is only logged.
+func (birthdayBot *BirthdayManager) refreshBoardAfterChange(ctx context.Context, chatId int64) {
+	if err := birthdayBot.refreshBoard(ctx, chatId); err != nil {
+		common.ErrorLogger.Printf("could not refresh board in chat: %v due to: %v\n", chatId, err)
+	}
+}
+
+func (birthdayBot *BirthdayManager) createBoardText(ctx context.Context, chatId int64) (string, error) {
+	now := birthdayBot.clock.Now()
+	thisMonth := now.Month()
+	nextMonth := thisMonth%12 + 1
+	thisMonthBirthdays, err := birthdayBot.getMonthBirthdays(ctx, chatId, thisMonth)
+	if err != nil {
+		return "", err
+	}
+	nextMonthBirthdays, err := birthdayBot.getMonthBirthdays(ctx, chatId, nextMonth)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(
+		MESSAGE_BOARD,
+		thisMonth,
+		createBoardMonthList(thisMonthBirthdays),
+		nextMonth,
+		createBoardMonthList(nextMonthBirthdays),
+		formatDateForOutput(now),
+	), nil
+}
+
+func (birthdayBot *BirthdayManager) getMonthBirthdays(ctx context.Context, chatId int64, month time.Month) ([]Birthday, error) {
+	firstDay := time.Date(DEFAULT_YEAR, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1)
+	birthdays, err := birthdayBot.repository.GetChatBirthdaysBetween(ctx, chatId, firstDay, lastDay)
+	if err != nil {
+		common.ErrorLogger.Printf("could not get birthdays in %v for chat: %v from the database due to: %v\n", month, chatId, err)
+	}
+	return birthdays, err
+}
+
+func createBoardMonthList(birthdays []Birthday) string {
+	if len(birthdays) == 0 {
+		return MESSAGE_BOARD_NO_BIRTHDAYS
+	}
+	lines := make([]string, len(birthdays))
+	for index, birthday := range birthdays {
+		lines[index] = fmt.Sprintf("🎂 <b>%v</b> - %v", formatDateForOutput(birthday.Date), createBirthdayPersonName(birthday))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type BirthdayDetails struct {
+	NextBirthday time.Time
+	DaysLeft     int
+	Age          int
+	ZodiacSign   ZodiacSign
+}
+
+type ZodiacSign struct {
+	Name   string
+	Symbol string
+	// the first day of the sign, signs are listed in the order they start within a year
+	startMonth time.Month
+	startDay   int
+}
+
+var zodiacSigns = []ZodiacSign{
+	{Name: "Capricorn", Symbol: "♑", startMonth: time.January, startDay: 1},
+	{Name: "Aquarius", Symbol: "♒", startMonth: time.January, startDay: 20},
+	{Name: "Pisces", Symbol: "♓", startMonth: time.February, startDay: 19},
+	{Name: "Aries", Symbol: "♈", startMonth: time.March, startDay: 21},
+	{Name: "Taurus", Symbol: "♉", startMonth: time.April, startDay: 20},
+	{Name: "Gemini", Symbol: "♊", startMonth: time.May, startDay: 21},
+	{Name: "Cancer", Symbol: "♋", startMonth: time.June, startDay: 21},
+	{Name: "Leo", Symbol: "♌", startMonth: time.July, startDay: 23},
+	{Name: "Virgo", Symbol: "♍", startMonth: time.August, startDay: 23},
+	{Name: "Libra", Symbol: "♎", startMonth: time.September, startDay: 23},
+	{Name: "Scorpio", Symbol: "♏", startMonth: time.October, startDay: 23},
+	{Name: "Sagittarius", Symbol: "♐", startMonth: time.November, startDay: 22},
+	{Name: "Capricorn", Symbol: "♑", startMonth: time.December, startDay: 22},
+}
+
+// describeBirthday calculates everything there is to know about the next occurrence of a birthday.
+// Age is 0 when the birth year is unknown.
+func describeBirthday(birthday Birthday, now time.Time, leapDayPolicy LeapDayPolicy) BirthdayDetails {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	nextBirthday := getBirthdayInYear(birthday.Date, today.Year(), leapDayPolicy)
+	if nextBirthday.Before(today) {
+		nextBirthday = getBirthdayInYear(birthday.Date, today.Year()+1, leapDayPolicy)
+	}
+	details := BirthdayDetails{
+		NextBirthday: nextBirthday,
+		DaysLeft:     int(nextBirthday.Sub(today).Hours() / 24),
+		ZodiacSign:   getZodiacSign(birthday.Date),
+	}
+	if birthday.Year != UNKNOWN_YEAR {
+		details.Age = nextBirthday.Year() - birthday.Year
+	}
+	return details
+}
+
+func getBirthdayInYear(date time.Time, year int, leapDayPolicy LeapDayPolicy) time.Time {
+	if isLeapDay(date) && !isLeapYear(year) {
+		if leapDayPolicy == LEAP_DAY_POLICY_MARCH_1 {
+			return time.Date(year, time.March, 1, 0, 0, 0, 0, time.UTC)
+		}
+		return time.Date(year, time.February, 28, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func getZodiacSign(date time.Time) ZodiacSign {
+	sign := zodiacSigns[0]
+	for _, candidate := range zodiacSigns {
+		if date.Month() > candidate.startMonth || (date.Month() == candidate.startMonth && date.Day() >= candidate.startDay) {
+			sign = candidate
+		}
+	}
+	return sign
+}
+
+func createBirthdayDetailsMessage(details BirthdayDetails) string {
+	lines := make([]string, 0, 3)
+	if details.DaysLeft == 0 {
+		lines = append(lines, MESSAGE_BIRTHDAY_TODAY)
+	} else {
+		lines = append(lines, fmt.Sprintf(MESSAGE_BIRTHDAY_COUNTDOWN, formatDaysLeft(details.DaysLeft), details.NextBirthday.Weekday()))
+	}
+	if details.Age > 0 && details.DaysLeft == 0 {
+		lines = append(lines, fmt.Sprintf(MESSAGE_BIRTHDAY_AGE_TODAY, details.Age))
+	} else if details.Age > 0 {
+		lines = append(lines, fmt.Sprintf(MESSAGE_BIRTHDAY_AGE, details.Age))
+	}
+	lines = append(lines, fmt.Sprintf(MESSAGE_BIRTHDAY_ZODIAC, details.ZodiacSign.Symbol, details.ZodiacSign.Name))
+	return strings.Join(lines, "\n")
+}
+
+func formatDaysLeft(daysLeft int) string {
+	if daysLeft == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%v days", daysLeft)
+}
@@ -12,15 +12,20 @@ import (
 )
 
 type BirthdayManager struct {
-	repository Repository
-	telegram   Telegram
-	id         int64
+	repository  Repository
+	telegram    Telegram
+	rateLimiter *RateLimiter
+	clock       Clock
+	id          int64
 }
 
 type BirthdayPerson struct {
-	ChatId int64
-	UserId int64
-	Name   string
+	ChatId    int64
+	UserId    int64
+	Name      string
+	FirstName string
+	LastName  string
+	Date      time.Time
 }
 
 const (
@@ -28,26 +33,66 @@ const (
 	CHAT_TYPE_GROUP      = "group"
 	CHAT_TYPE_SUPERGROUP = "supergroup"
 
-	COMMAND_SET_BIRTHDAY   = "/setbirthday"
-	COMMAND_UNSET_BIRTHDAY = "/unsetbirthday"
-	COMMAND_GET_BIRTHDAY   = "/getbirthday"
-	COMMAND_MY_BIRTHDAY    = "/mybirthday"
-	COMMAND_NEXT_BIRTHDAY  = "/nextbirthday"
-	COMMAND_START          = "/start"
-	COMMAND_HELP           = "/help"
-	COMMAND_PRIVACY        = "/privacy"
-	COMMAND_SOURCE         = "/source"
-	COMMAND_CLEAR          = "/clear"
-	COMMAND_CLEAR_FULL     = "/clear all data"
-	REACTION_THUMBS_UP     = "👍"
-
-	DEFAULT_YEAR       = 2000
-	INPUT_DATE_LAYOUT  = "2.1"
-	OUTPUT_DATE_LAYOUT = "January 2"
+	COMMAND_SET_BIRTHDAY     = "/setbirthday"
+	COMMAND_UNSET_BIRTHDAY   = "/unsetbirthday"
+	COMMAND_GET_BIRTHDAY     = "/getbirthday"
+	COMMAND_MY_BIRTHDAY      = "/mybirthday"
+	COMMAND_NEXT_BIRTHDAY    = "/nextbirthday"
+	COMMAND_BIRTHDAY_STATS   = "/birthdaystats"
+	COMMAND_BOARD            = "/board"
+	COMMAND_DIGEST           = "/digest"
+	COMMAND_LEAP_DAY         = "/leapday"
+	COMMAND_ADD_EVENT        = "/addevent"
+	COMMAND_EVENTS           = "/events"
+	COMMAND_REMOVE_EVENT     = "/removeevent"
+	COMMAND_SET_NAME_DAY     = "/setnameday"
+	COMMAND_UNSET_NAME_DAY   = "/unsetnameday"
+	COMMAND_NAME_DAY_COUNTRY = "/namedaycountry"
+	COMMAND_WISHLIST         = "/wishlist"
+	COMMAND_SKIP_WISH        = "/skipwish"
+	COMMAND_MY_VIDEOS        = "/myvideos"
+	COMMAND_SET_PHOTO        = "/setphoto"
+	COMMAND_RESET_PHOTO      = "/resetphoto"
+	COMMAND_START            = "/start"
+	COMMAND_HELP             = "/help"
+	COMMAND_PRIVACY          = "/privacy"
+	COMMAND_SOURCE           = "/source"
+	COMMAND_CLEAR            = "/clear"
+	COMMAND_CLEAR_FULL       = "/clear all data"
+	REACTION_THUMBS_UP       = "👍"
+	REACTION_SLOW_DOWN       = "🥱"
+
+	DEFAULT_YEAR                = 2000
+	UNKNOWN_YEAR                = 0
+	MIN_BIRTH_YEAR              = 1900
+	INPUT_DATE_LAYOUT           = "2.1"
+	INPUT_DATE_WITH_YEAR_LAYOUT = "2.1.2006"
+	OUTPUT_DATE_LAYOUT          = "January 2"
 )
 
-func NewBirthdayManager(repository Repository, telegram Telegram, botId int64) *BirthdayManager {
-	return &BirthdayManager{repository: repository, telegram: telegram, id: botId}
+var groupCommandHandlers = map[string]func(*BirthdayManager, context.Context, *models.Update) error{
+	COMMAND_SET_BIRTHDAY:     (*BirthdayManager).saveBirthday,
+	COMMAND_UNSET_BIRTHDAY:   (*BirthdayManager).deleteBirthday,
+	COMMAND_GET_BIRTHDAY:     (*BirthdayManager).getBirthday,
+	COMMAND_MY_BIRTHDAY:      (*BirthdayManager).getBirthday,
+	COMMAND_NEXT_BIRTHDAY:    (*BirthdayManager).getNextBirthday,
+	COMMAND_BIRTHDAY_STATS:   (*BirthdayManager).getBirthdayStats,
+	COMMAND_BOARD:            (*BirthdayManager).createBoard,
+	COMMAND_DIGEST:           (*BirthdayManager).setDigest,
+	COMMAND_LEAP_DAY:         (*BirthdayManager).setLeapDayPolicy,
+	COMMAND_ADD_EVENT:        (*BirthdayManager).addEvent,
+	COMMAND_EVENTS:           (*BirthdayManager).listEvents,
+	COMMAND_REMOVE_EVENT:     (*BirthdayManager).removeEvent,
+	COMMAND_SET_NAME_DAY:     (*BirthdayManager).setNameDay,
+	COMMAND_UNSET_NAME_DAY:   (*BirthdayManager).unsetNameDay,
+	COMMAND_NAME_DAY_COUNTRY: (*BirthdayManager).setNameDayCountry,
+	COMMAND_WISHLIST:         (*BirthdayManager).handleWishlist,
+	COMMAND_SET_PHOTO:        (*BirthdayManager).setCustomPicture,
+	COMMAND_RESET_PHOTO:      (*BirthdayManager).resetCustomPicture,
+}
+
+func NewBirthdayManager(repository Repository, telegram Telegram, rateLimiter *RateLimiter, clock Clock, botId int64) *BirthdayManager {
+	return &BirthdayManager{repository: repository, telegram: telegram, rateLimiter: rateLimiter, clock: clock, id: botId}
 }
 
 func (birthdayBot *BirthdayManager) HandleUpdate(ctx context.Context, update *models.Update) error {
@@ -62,8 +107,10 @@ func (birthdayBot *BirthdayManager) HandleUpdate(ctx context.Context, update *mo
 		if isCommand(update) {
 			return birthdayBot.handlePrivateChatCommand(ctx, update)
 		} else {
-			return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_SHORT_HELP)
+			return birthdayBot.handlePrivateChatMessage(ctx, update)
 		}
+	} else if update.CallbackQuery != nil {
+		return birthdayBot.handleCallbackQuery(ctx, update.CallbackQuery)
 	}
 	return nil
 }
@@ -73,15 +120,40 @@ func (birthdayBot *BirthdayManager) GetBirthdays(ctx context.Context, date time.
 	if err != nil {
 		return nil, err
 	}
+	if policy, isCelebrated := getLeapDayPolicyCelebratedOn(date); isCelebrated {
+		leapDayBirthdays, err := birthdayBot.repository.GetLeapDayBirthdays(ctx, policy)
+		if err != nil {
+			return nil, err
+		}
+		birthdays = append(birthdays, leapDayBirthdays...)
+	}
+	return mapToBirthdayPeople(birthdays), nil
+}
+
+// GetDigestBirthdays returns birthdays in the month of the given date for every chat that enabled the monthly digest.
+func (birthdayBot *BirthdayManager) GetDigestBirthdays(ctx context.Context, date time.Time) ([]BirthdayPerson, error) {
+	firstDay := time.Date(DEFAULT_YEAR, date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1)
+	birthdays, err := birthdayBot.repository.GetDigestBirthdaysBetween(ctx, firstDay, lastDay)
+	if err != nil {
+		return nil, err
+	}
+	return mapToBirthdayPeople(birthdays), nil
+}
+
+func mapToBirthdayPeople(birthdays []Birthday) []BirthdayPerson {
 	birthdayPeople := make([]BirthdayPerson, len(birthdays))
 	for index, birthday := range birthdays {
 		birthdayPeople[index] = BirthdayPerson{
-			ChatId: birthday.ChatId,
-			UserId: birthday.UserId,
-			Name:   createBirthdayPersonName(birthday),
+			ChatId:    birthday.ChatId,
+			UserId:    birthday.UserId,
+			Name:      createBirthdayPersonName(birthday),
+			FirstName: birthday.UserFirstName,
+			LastName:  birthday.UserLastName,
+			Date:      birthday.Date,
 		}
 	}
-	return birthdayPeople, nil
+	return birthdayPeople
 }
 
 func isGroupUpdate(update *models.Update) bool {
@@ -94,7 +166,15 @@ func isPrivateChatUpdate(update *models.Update) bool {
 }
 
 func isCommand(update *models.Update) bool {
-	return update.Message != nil && strings.HasPrefix(update.Message.Text, "/")
+	return update.Message != nil && strings.HasPrefix(getCommandText(update.Message), "/")
+}
+
+// getCommandText returns the text of the message or, for photos sent with a command, their caption.
+func getCommandText(message *models.Message) string {
+	if len(message.Text) == 0 {
+		return message.Caption
+	}
+	return message.Text
 }
 
 func hasLeftMember(update *models.Update) bool {
@@ -108,32 +188,46 @@ func extractCommand(text string) string {
 }
 
 func (birthdayBot *BirthdayManager) handleGroupCommand(ctx context.Context, update *models.Update) error {
-	command := extractCommand(update.Message.Text)
-	switch command {
-	case COMMAND_SET_BIRTHDAY:
-		return birthdayBot.saveBirthday(ctx, update)
-	case COMMAND_UNSET_BIRTHDAY:
-		return birthdayBot.deleteBirthday(ctx, update)
-	case COMMAND_GET_BIRTHDAY, COMMAND_MY_BIRTHDAY:
-		return birthdayBot.getBirthday(ctx, update)
-	case COMMAND_NEXT_BIRTHDAY:
-		return birthdayBot.getNextBirthday(ctx, update)
+	command := extractCommand(getCommandText(update.Message))
+	handler, isKnownCommand := groupCommandHandlers[command]
+	if !isKnownCommand {
+		return nil
 	}
-	return nil
+	chatId := update.Message.Chat.ID
+	switch birthdayBot.rateLimiter.Allow(chatId, update.Message.From.ID) {
+	case RATE_LIMIT_WARNED:
+		return birthdayBot.telegram.SendReaction(ctx, chatId, update.Message.ID, REACTION_SLOW_DOWN)
+	case RATE_LIMIT_DROPPED:
+		return nil
+	}
+	return handler(birthdayBot, ctx, update)
 }
 
 func (birthdayBot *BirthdayManager) handlePrivateChatCommand(ctx context.Context, update *models.Update) error {
-	command := extractCommand(update.Message.Text)
+	command := extractCommand(getCommandText(update.Message))
 	switch command {
-	case COMMAND_HELP, COMMAND_START:
+	case COMMAND_START:
+		return birthdayBot.startPrivateChat(ctx, update)
+	case COMMAND_HELP:
 		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_FULL_HELP)
+	case COMMAND_SKIP_WISH:
+		return birthdayBot.skipWish(ctx, update)
+	case COMMAND_WISHLIST:
+		return birthdayBot.handleWishlist(ctx, update)
+	case COMMAND_MY_VIDEOS:
+		return birthdayBot.listBirthdayVideos(ctx, update)
+	case COMMAND_SET_PHOTO:
+		return birthdayBot.setCustomPicture(ctx, update)
+	case COMMAND_RESET_PHOTO:
+		return birthdayBot.resetCustomPicture(ctx, update)
 	case COMMAND_PRIVACY:
 		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_PRIVACY)
 	case COMMAND_SOURCE:
 		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_SOURCE)
 	case COMMAND_CLEAR:
 		return birthdayBot.deleteAllUserBirthdays(ctx, update)
-	case COMMAND_SET_BIRTHDAY, COMMAND_UNSET_BIRTHDAY, COMMAND_GET_BIRTHDAY, COMMAND_NEXT_BIRTHDAY:
+	case COMMAND_SET_BIRTHDAY, COMMAND_UNSET_BIRTHDAY, COMMAND_GET_BIRTHDAY, COMMAND_NEXT_BIRTHDAY, COMMAND_BIRTHDAY_STATS, COMMAND_BOARD, COMMAND_DIGEST, COMMAND_LEAP_DAY,
+		COMMAND_ADD_EVENT, COMMAND_EVENTS, COMMAND_REMOVE_EVENT, COMMAND_SET_NAME_DAY, COMMAND_UNSET_NAME_DAY, COMMAND_NAME_DAY_COUNTRY:
 		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_GROUP_COMMAND)
 	default:
 		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_SHORT_HELP)
@@ -145,15 +239,61 @@ func (birthdayBot *BirthdayManager) handleMemberLeaving(ctx context.Context, upd
 	chatId := update.Message.Chat.ID
 
 	if memberThatLeft.ID == birthdayBot.id {
-		err := birthdayBot.repository.DeleteAllChatBirthdays(ctx, chatId)
-		if err != nil {
-			return fmt.Errorf("could not delete all chat birthdays from the database due to: %v", err)
-		}
+		return birthdayBot.deleteAllChatData(ctx, chatId)
 	} else {
 		err := birthdayBot.repository.DeleteBirthday(ctx, chatId, memberThatLeft.ID)
 		if err != nil {
 			return fmt.Errorf("could not delete birthday from the database due to: %v", err)
 		}
+		err = birthdayBot.repository.DeleteNameDay(ctx, chatId, memberThatLeft.ID)
+		if err != nil {
+			return fmt.Errorf("could not delete name day from the database due to: %v", err)
+		}
+		err = birthdayBot.repository.DeleteChatUserWishlistItems(ctx, chatId, memberThatLeft.ID)
+		if err != nil {
+			return fmt.Errorf("could not delete wishlist items from the database due to: %v", err)
+		}
+		err = birthdayBot.repository.DeleteCustomPicture(ctx, memberThatLeft.ID, chatId)
+		if err != nil {
+			return fmt.Errorf("could not delete custom picture from the database due to: %v", err)
+		}
+		birthdayBot.refreshBoardAfterChange(ctx, chatId)
+	}
+	return nil
+}
+
+func (birthdayBot *BirthdayManager) deleteAllChatData(ctx context.Context, chatId int64) error {
+	err := birthdayBot.repository.DeleteAllChatBirthdays(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete all chat birthdays from the database due to: %v", err)
+	}
+	err = birthdayBot.repository.DeleteAllChatEvents(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete all chat events from the database due to: %v", err)
+	}
+	err = birthdayBot.repository.DeleteAllChatNameDays(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete all chat name days from the database due to: %v", err)
+	}
+	err = birthdayBot.repository.DeleteAllChatWishlistItems(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete all chat wishlist items from the database due to: %v", err)
+	}
+	err = birthdayBot.repository.DeleteAllChatCustomPictures(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete all chat custom pictures from the database due to: %v", err)
+	}
+	err = birthdayBot.repository.DeleteAllChatWishes(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete all chat wishes from the database due to: %v", err)
+	}
+	err = birthdayBot.repository.DeleteAllChatBirthdayVideos(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete all chat birthday videos from the database due to: %v", err)
+	}
+	err = birthdayBot.repository.DeleteChatSettings(ctx, chatId)
+	if err != nil {
+		return fmt.Errorf("could not delete chat settings from the database due to: %v", err)
 	}
 	return nil
 }
@@ -166,23 +306,29 @@ func (birthdayBot *BirthdayManager) sendPrivateChatMessage(ctx context.Context,
 func (birthdayBot *BirthdayManager) saveBirthday(ctx context.Context, update *models.Update) error {
 	chatId := update.Message.Chat.ID
 	messageId := update.Message.ID
-	userId := update.Message.From.ID
-	firstName := update.Message.From.FirstName
-	lastName := update.Message.From.LastName
-	userName := update.Message.From.Username
 
-	messagesParts := strings.Fields(update.Message.Text)
+	subject, refusal := birthdayBot.getSubjectUser(ctx, update)
+	if subject == nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, refusal)
+	}
+	userId := subject.ID
+	firstName := subject.FirstName
+	lastName := subject.LastName
+	userName := subject.Username
+
+	messagesParts := strings.Fields(getCommandText(update.Message))
 	if len(messagesParts) < 2 {
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_WRONG_FORMAT)
 	}
 
-	date, err := parseDate(messagesParts[1:])
-	if err != nil {
+	date, year, err := parseDate(messagesParts[1:])
+	if err != nil || !birthdayBot.isValidBirthYear(year) {
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_WRONG_FORMAT)
 	}
 
 	err = birthdayBot.repository.SaveBirthday(ctx, Birthday{
 		Date:          date,
+		Year:          year,
 		ChatId:        chatId,
 		UserId:        userId,
 		Username:      userName,
@@ -194,20 +340,73 @@ func (birthdayBot *BirthdayManager) saveBirthday(ctx context.Context, update *mo
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
 	}
 
+	birthdayBot.refreshBoardAfterChange(ctx, chatId)
 	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
 }
 
-func parseDate(parts []string) (time.Time, error) {
-	date, err := time.Parse(INPUT_DATE_LAYOUT, parts[0])
+// getSubjectUser returns the user whose birthday a command should manage.
+// Admins can manage other members' birthdays by replying to their messages.
+// When the command is refused, the user is nil and the reply explaining why is returned instead.
+func (birthdayBot *BirthdayManager) getSubjectUser(ctx context.Context, update *models.Update) (*models.User, string) {
+	author := update.Message.From
+	if !isReplyToAnotherUser(update) {
+		return author, ""
+	}
+	subject := update.Message.ReplyToMessage.From
+	if subject.ID == birthdayBot.id || subject.IsBot {
+		return nil, MESSAGE_SUBJECT_IS_BOT
+	}
+	isAdmin, err := birthdayBot.telegram.IsChatAdmin(ctx, update.Message.Chat.ID, author.ID)
+	if err != nil {
+		common.ErrorLogger.Printf("could not check if user: %v is an admin due to: %v\n", author.ID, err)
+		return nil, MESSAGE_ADMIN_CHECK_FAILURE
+	}
+	if !isAdmin {
+		return nil, MESSAGE_NOT_ADMIN
+	}
+	return subject, ""
+}
+
+// ensureAdmin replies with the refusal and returns false when the author of the command isn't a chat admin.
+func (birthdayBot *BirthdayManager) ensureAdmin(ctx context.Context, update *models.Update, refusal string) (bool, error) {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+	isAdmin, err := birthdayBot.telegram.IsChatAdmin(ctx, chatId, update.Message.From.ID)
+	if err != nil {
+		common.ErrorLogger.Printf("could not check if user: %v is an admin due to: %v\n", update.Message.From.ID, err)
+		return false, birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_ADMIN_CHECK_FAILURE)
+	}
+	if !isAdmin {
+		return false, birthdayBot.telegram.SendReply(ctx, chatId, messageId, refusal)
+	}
+	return true, nil
+}
+
+func isReplyToAnotherUser(update *models.Update) bool {
+	replyTo := update.Message.ReplyToMessage
+	return replyTo != nil && replyTo.From != nil && replyTo.From.ID != update.Message.From.ID
+}
+
+// parseDate returns the birth date sanitized to DEFAULT_YEAR and the birth year, which is 0 when it wasn't given.
+func parseDate(parts []string) (time.Time, int, error) {
+	date, err := time.Parse(INPUT_DATE_WITH_YEAR_LAYOUT, parts[0])
+	if err == nil {
+		return sanitizeDate(date), date.Year(), nil
+	}
+	date, err = time.Parse(INPUT_DATE_LAYOUT, parts[0])
 	if err == nil {
-		return sanitizeDate(date), nil
+		return sanitizeDate(date), UNKNOWN_YEAR, nil
 	}
 	datestr := strings.Join(parts, " ")
 	parsedDate, err := dateparse.ParseAny(datestr, dateparse.RetryAmbiguousDateWithSwap(true), dateparse.PreferMonthFirst(false))
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, UNKNOWN_YEAR, err
 	}
-	return sanitizeDate(parsedDate), nil
+	return sanitizeDate(parsedDate), parsedDate.Year(), nil
+}
+
+func (birthdayBot *BirthdayManager) isValidBirthYear(year int) bool {
+	return year == UNKNOWN_YEAR || (year >= MIN_BIRTH_YEAR && year <= birthdayBot.clock.Now().Year())
 }
 
 func sanitizeDate(date time.Time) time.Time {
@@ -226,16 +425,18 @@ func (birthdayBot *BirthdayManager) getOwnBirthday(ctx context.Context, update *
 	userId := update.Message.From.ID
 	messageId := update.Message.ID
 
-	date, err := birthdayBot.repository.GetBirthdayDate(ctx, chatId, userId)
+	birthday, err := birthdayBot.repository.GetBirthday(ctx, chatId, userId)
 	if err != nil {
 		common.ErrorLogger.Printf("could not get birthday from the database due to: %v\n", err)
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
 	}
-	if date == nil {
+	if birthday == nil {
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NO_OWN_BIRTHDAY_SET)
 	}
 
-	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, fmt.Sprintf(MESSAGE_GET_OWN_BIRTHDAY, formatDateForOutput(*date)))
+	details := describeBirthday(*birthday, birthdayBot.clock.Now(), birthdayBot.getLeapDayPolicy(ctx, chatId))
+	message := fmt.Sprintf(MESSAGE_GET_OWN_BIRTHDAY, formatDateForOutput(birthday.Date), createBirthdayDetailsMessage(details))
+	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, message)
 }
 
 func (birthdayBot *BirthdayManager) getSomeonesBirthday(ctx context.Context, update *models.Update) error {
@@ -247,16 +448,16 @@ func (birthdayBot *BirthdayManager) getSomeonesBirthday(ctx context.Context, upd
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_BOT_BIRTHDAY)
 	}
 
-	date, err := birthdayBot.repository.GetBirthdayDate(ctx, chatId, subjectUserId)
+	birthday, err := birthdayBot.repository.GetBirthday(ctx, chatId, subjectUserId)
 	if err != nil {
 		common.ErrorLogger.Printf("could not get birthday from the database due to: %v\n", err)
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
 	}
-	if date == nil {
+	if birthday == nil {
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NO_BIRTHDAY_SET)
 	}
 
-	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, fmt.Sprintf(MESSAGE_GET_BIRTHDAY, formatDateForOutput(*date)))
+	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, fmt.Sprintf(MESSAGE_GET_BIRTHDAY, formatDateForOutput(birthday.Date)))
 }
 
 func (birthdayBot *BirthdayManager) getNextBirthday(ctx context.Context, update *models.Update) error {
@@ -272,14 +473,32 @@ func (birthdayBot *BirthdayManager) getNextBirthday(ctx context.Context, update
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NO_BIRTHDAYS)
 	}
 
-	message := createNextBirthdayMessage(birthdays)
+	nextBirthday := describeBirthday(birthdays[0], birthdayBot.clock.Now(), birthdayBot.getLeapDayPolicy(ctx, chatId)).NextBirthday
+	message := createNextBirthdayMessage(birthdays, nextBirthday)
 	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, message)
 }
 
-func createNextBirthdayMessage(birthdays []Birthday) string {
+func (birthdayBot *BirthdayManager) getBirthdayStats(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	counts, err := birthdayBot.repository.GetBirthdayCountsPerDay(ctx, chatId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not get birthday counts from the database due to: %v\n", err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	if len(counts) == 0 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NO_BIRTHDAYS)
+	}
+
+	stats := calculateBirthdayStats(counts)
+	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, createBirthdayStatsMessage(stats))
+}
+
+func createNextBirthdayMessage(birthdays []Birthday, date time.Time) string {
 	name := createBirthdayPersonName(birthdays[0])
 	if len(birthdays) == 1 {
-		return fmt.Sprintf(MESSAGE_NEXT_BIRTHDAY, name, formatDateForOutput(birthdays[0].Date))
+		return fmt.Sprintf(MESSAGE_NEXT_BIRTHDAY, name, formatDateForOutput(date))
 	}
 	birthdayNames := name
 	for index, birthday := range birthdays[1:] {
@@ -290,7 +509,7 @@ func createNextBirthdayMessage(birthdays []Birthday) string {
 			birthdayNames = fmt.Sprintf("%v, %v", birthdayNames, name)
 		}
 	}
-	return fmt.Sprintf(MESSAGE_NEXT_BIRTHDAYS, len(birthdays), formatDateForOutput(birthdays[0].Date), birthdayNames)
+	return fmt.Sprintf(MESSAGE_NEXT_BIRTHDAYS, len(birthdays), formatDateForOutput(date), birthdayNames)
 }
 
 func createBirthdayPersonName(birthday Birthday) string {
@@ -306,15 +525,20 @@ func createBirthdayPersonName(birthday Birthday) string {
 
 func (birthdayBot *BirthdayManager) deleteBirthday(ctx context.Context, update *models.Update) error {
 	chatId := update.Message.Chat.ID
-	userId := update.Message.From.ID
 	messageId := update.Message.ID
 
-	err := birthdayBot.repository.DeleteBirthday(ctx, chatId, userId)
+	subject, refusal := birthdayBot.getSubjectUser(ctx, update)
+	if subject == nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, refusal)
+	}
+
+	err := birthdayBot.repository.DeleteBirthday(ctx, chatId, subject.ID)
 	if err != nil {
 		common.ErrorLogger.Printf("could not delete birthday from the database due to: %v\n", err)
 		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_UNSET_FAILURE)
 	}
 
+	birthdayBot.refreshBoardAfterChange(ctx, chatId)
 	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
 }
 
@@ -322,15 +546,48 @@ func (birthdayBot *BirthdayManager) deleteAllUserBirthdays(ctx context.Context,
 	chatId := update.Message.Chat.ID
 	userId := update.Message.From.ID
 
-	if update.Message.Text != COMMAND_CLEAR_FULL {
+	if getCommandText(update.Message) != COMMAND_CLEAR_FULL {
 		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_WRONG_CLEAR_DATA_COMMAND)
 	}
 
-	err := birthdayBot.repository.DeleteAllUserBirthdays(ctx, userId)
+	chatIds, err := birthdayBot.repository.DeleteAllUserBirthdays(ctx, userId)
 	if err != nil {
 		common.ErrorLogger.Printf("could not delete birthdays from the database due to: %v\n", err)
 		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_UNSET_FAILURE)
 	}
+	err = birthdayBot.repository.DeleteAllUserNameDays(ctx, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not delete name days from the database due to: %v\n", err)
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_UNSET_FAILURE)
+	}
+	err = birthdayBot.repository.DeleteAllUserWishes(ctx, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not delete wishes from the database due to: %v\n", err)
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_UNSET_FAILURE)
+	}
+	err = birthdayBot.repository.DeleteAllUserWishlistItems(ctx, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not delete wishlist items from the database due to: %v\n", err)
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_UNSET_FAILURE)
+	}
+	err = birthdayBot.repository.DeleteAllUserBirthdayVideos(ctx, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not delete birthday videos from the database due to: %v\n", err)
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_UNSET_FAILURE)
+	}
+	err = birthdayBot.repository.DeleteAllUserCustomPictures(ctx, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not delete custom pictures from the database due to: %v\n", err)
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_UNSET_FAILURE)
+	}
+	err = birthdayBot.repository.DeleteAllUserDeliverySteps(ctx, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not delete delivery steps from the database due to: %v\n", err)
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_UNSET_FAILURE)
+	}
+	for _, birthdayChatId := range chatIds {
+		birthdayBot.refreshBoardAfterChange(ctx, birthdayChatId)
+	}
 
 	return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_DATA_CLEARED)
 }
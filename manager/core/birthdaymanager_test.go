@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/4Kaze/birthdaybot/common"
 	"github.com/4Kaze/birthdaybot/manager/core"
 	"github.com/go-telegram/bot/models"
 	. "github.com/onsi/ginkgo/v2"
@@ -15,12 +18,14 @@ import (
 var _ = Describe("Birthday manager", func() {
 	var repository FakeRepository
 	var telegram FakeTelegram
-	var bot core.BirthdayManager
+	var clock FakeClock
+	var bot *core.BirthdayManager
 
 	BeforeEach(func() {
 		repository = FakeRepository{}
 		telegram = FakeTelegram{}
-		bot = *core.NewBirthdayManager(&repository, &telegram, BOT_ID)
+		clock = FakeClock{now: NOW}
+		bot = core.NewBirthdayManager(&repository, &telegram, core.NewRateLimiter(&clock, RATE_LIMIT_BURST, RATE_LIMIT_WINDOW), &clock, BOT_ID)
 	})
 
 	Describe("setting birthday", func() {
@@ -107,6 +112,63 @@ var _ = Describe("Birthday manager", func() {
 			Entry("for reverse order with different separator", "01/31", monthAndDay(1, 31)),
 		)
 
+		DescribeTable("should save birth year when given", func(dateString string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: fmt.Sprintf("/setbirthday %s", dateString),
+					},
+				},
+			)
+
+			Expect(repository.savedBirthdays).To(HaveLen(1))
+			Expect(repository.savedBirthdays[0].Date).To(Equal(monthAndDay(1, 31)))
+			Expect(repository.savedBirthdays[0].Year).To(Equal(1990))
+		},
+			Entry("for dotted format", "31.01.1990"),
+			Entry("for '/' separator", "31/01/1990"),
+			Entry("for month in full-text format", "January 31 1990"),
+		)
+
+		DescribeTable("should reply with a help message when birth year is not possible", func(dateString string) {
+			clock.now = time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: fmt.Sprintf("/setbirthday %s", dateString),
+					},
+				},
+			)
+
+			Expect(repository.savedBirthdays).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_WRONG_FORMAT,
+			}))
+		},
+			Entry("for a year in the future", "31.01.2990"),
+			Entry("for a year too long ago", "31.01.1800"),
+		)
+
 		DescribeTable("should reply with a help message when date is incorrect", func(incorrectDate string) {
 			bot.HandleUpdate(
 				context.Background(),
@@ -257,6 +319,92 @@ var _ = Describe("Birthday manager", func() {
 		})
 	})
 
+	Describe("getting own birthday details", func() {
+		sendMyBirthdayCommand := func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: "/mybirthday",
+					},
+				},
+			)
+		}
+
+		It("should reply with a countdown, weekday and zodiac sign", func() {
+			clock.now = time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC)
+			_ = repository.SaveBirthday(context.Background(), core.Birthday{Date: monthAndDay(1, 31)})
+
+			sendMyBirthdayCommand()
+
+			Expect(telegram.sentReplies).To(HaveLen(1))
+			text := telegram.sentReplies[0].text
+			Expect(text).To(ContainSubstring("Only <b>21 days</b> left until your next birthday! It's on a <b>Wednesday</b>"))
+			Expect(text).To(ContainSubstring("your zodiac sign is <b>♒ Aquarius</b>"))
+			Expect(text).To(Not(ContainSubstring("turning")))
+		})
+
+		It("should include the age when the birth year is known", func() {
+			clock.now = time.Date(2024, 1, 30, 8, 0, 0, 0, time.UTC)
+			_ = repository.SaveBirthday(context.Background(), core.Birthday{Date: monthAndDay(1, 31), Year: 1990})
+
+			sendMyBirthdayCommand()
+
+			text := telegram.sentReplies[0].text
+			Expect(text).To(ContainSubstring("Only <b>1 day</b> left"))
+			Expect(text).To(ContainSubstring("You'll be turning <b>34</b>!"))
+		})
+
+		It("should count down to the next year when the birthday has passed", func() {
+			clock.now = time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+			_ = repository.SaveBirthday(context.Background(), core.Birthday{Date: monthAndDay(1, 31), Year: 1990})
+
+			sendMyBirthdayCommand()
+
+			text := telegram.sentReplies[0].text
+			Expect(text).To(ContainSubstring("Only <b>336 days</b> left until your next birthday! It's on a <b>Friday</b>"))
+			Expect(text).To(ContainSubstring("You'll be turning <b>35</b>!"))
+		})
+
+		It("should celebrate when the birthday is today", func() {
+			clock.now = time.Date(2024, 1, 31, 7, 0, 0, 0, time.UTC)
+			_ = repository.SaveBirthday(context.Background(), core.Birthday{Date: monthAndDay(1, 31), Year: 1990})
+
+			sendMyBirthdayCommand()
+
+			text := telegram.sentReplies[0].text
+			Expect(text).To(ContainSubstring(core.MESSAGE_BIRTHDAY_TODAY))
+			Expect(text).To(ContainSubstring("You're turning <b>34</b> today!"))
+		})
+
+		DescribeTable("should reply with the right zodiac sign", func(birthDate time.Time, expectedSign string) {
+			clock.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			_ = repository.SaveBirthday(context.Background(), core.Birthday{Date: birthDate})
+
+			sendMyBirthdayCommand()
+
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring(fmt.Sprintf("your zodiac sign is <b>%v</b>", expectedSign)))
+		},
+			Entry("for the first day of the year", monthAndDay(1, 1), "♑ Capricorn"),
+			Entry("for the last day of Capricorn", monthAndDay(1, 19), "♑ Capricorn"),
+			Entry("for the first day of Aquarius", monthAndDay(1, 20), "♒ Aquarius"),
+			Entry("for February 29th", monthAndDay(2, 29), "♓ Pisces"),
+			Entry("for the first day of Aries", monthAndDay(3, 21), "♈ Aries"),
+			Entry("for the last day of Cancer", monthAndDay(7, 22), "♋ Cancer"),
+			Entry("for the first day of Virgo", monthAndDay(8, 23), "♍ Virgo"),
+			Entry("for the last day of Sagittarius", monthAndDay(12, 21), "♐ Sagittarius"),
+			Entry("for the last day of the year", monthAndDay(12, 31), "♑ Capricorn"),
+		)
+	})
+
 	Describe("getting someone's birthday", func() {
 		DescribeTable("should reply with birthday of an author of a message that was replied to", func(groupType string) {
 			_ = repository.SaveBirthday(context.Background(), core.Birthday{
@@ -733,208 +881,450 @@ var _ = Describe("Birthday manager", func() {
 		})
 	})
 
-	Describe("unsetting birthday of a leaving member", func() {
-		It("should delete a birthday without sending any message", func() {
+	Describe("managing someone else's birthday", func() {
+		It("should let an admin set a birthday of the person they reply to", func() {
+			telegram.adminIds = []int64{USER_ID_1}
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
 						ID: MESSAGE_ID,
+						From: &models.User{
+							ID:        USER_ID_1,
+							FirstName: FIRST_NAME_1,
+							Username:  USER_NAME_1,
+						},
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
 							Type: "supergroup",
 						},
-						LeftChatMember: &models.User{
-							ID: USER_ID_1,
+						ReplyToMessage: &models.Message{
+							From: &models.User{
+								ID:        USER_ID_2,
+								FirstName: FIRST_NAME_2,
+								LastName:  LAST_NAME,
+								Username:  USER_NAME_2,
+							},
 						},
+						Text: "/setbirthday 12.05",
 					},
 				},
 			)
 
-			Expect(repository.deletedBirthdays).To(HaveExactElements(DeletedBirthday{
-				userId: USER_ID_1,
+			Expect(telegram.adminChecks).To(HaveExactElements(AdminCheck{
 				chatId: CHAT_ID_1,
+				userId: USER_ID_1,
+			}))
+			Expect(repository.savedBirthdays).To(HaveExactElements(core.Birthday{
+				ChatId:        CHAT_ID_1,
+				UserId:        USER_ID_2,
+				Date:          monthAndDay(5, 12),
+				UserFirstName: FIRST_NAME_2,
+				UserLastName:  LAST_NAME,
+				Username:      USER_NAME_2,
+			}))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  "👍",
 			}))
-			Expect(telegram.sentReplies).To(BeEmpty())
-			Expect(telegram.sentMessages).To(BeEmpty())
 		})
 
-		It("should delete all birthdays when the bot is removed", func() {
+		It("should let an admin unset a birthday of the person they reply to", func() {
+			telegram.adminIds = []int64{USER_ID_1}
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
 						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
 							Type: "supergroup",
 						},
-						LeftChatMember: &models.User{
-							ID: BOT_ID,
+						ReplyToMessage: &models.Message{
+							From: &models.User{
+								ID: USER_ID_2,
+							},
 						},
+						Text: "/unsetbirthday",
 					},
 				},
 			)
 
-			Expect(repository.deletedGroupBirthdays).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedBirthdays).To(HaveExactElements(DeletedBirthday{
+				chatId: CHAT_ID_1,
+				userId: USER_ID_2,
+			}))
+			Expect(telegram.sentReactions).To(HaveLen(1))
 		})
 
-		It("should not send any message when deleting fails", func() {
-			repository.shouldFail = true
+		DescribeTable("should refuse when the author is not an admin", func(command string) {
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
 						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
 							Type: "supergroup",
 						},
-						LeftChatMember: &models.User{
-							ID: USER_ID_1,
+						ReplyToMessage: &models.Message{
+							From: &models.User{
+								ID: USER_ID_2,
+							},
 						},
+						Text: command,
 					},
 				},
 			)
 
-			Expect(telegram.sentReplies).To(BeEmpty())
-			Expect(telegram.sentMessages).To(BeEmpty())
-		})
-	})
+			Expect(repository.savedBirthdays).To(BeEmpty())
+			Expect(repository.deletedBirthdays).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_NOT_ADMIN,
+			}))
+		},
+			Entry("for /setbirthday", "/setbirthday 12.05"),
+			Entry("for /unsetbirthday", "/unsetbirthday"),
+		)
 
-	Describe("removing all user data", func() {
-		It("should remove user's data", func() {
+		DescribeTable("should refuse when replying to a bot", func(subject models.User) {
+			telegram.adminIds = []int64{USER_ID_1}
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
+						ID: MESSAGE_ID,
 						From: &models.User{
 							ID: USER_ID_1,
 						},
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
-							Type: "private",
+							Type: "supergroup",
 						},
-						Text: "/clear all data",
+						ReplyToMessage: &models.Message{
+							From: &subject,
+						},
+						Text: "/setbirthday 12.05",
 					},
 				},
 			)
 
-			Expect(repository.deletedUserBirthdays).To(HaveExactElements(USER_ID_1))
-			Expect(telegram.sentMessages).To(HaveExactElements(Message{
-				chatId: CHAT_ID_1,
-				text:   core.MESSAGE_DATA_CLEARED,
+			Expect(repository.savedBirthdays).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_SUBJECT_IS_BOT,
 			}))
-		})
+		},
+			Entry("for the birthday bot", models.User{ID: BOT_ID, IsBot: true}),
+			Entry("for another bot", models.User{ID: USER_ID_2, IsBot: true}),
+		)
 
-		DescribeTable("should not remove user's data when command is not complete", func(command string) {
+		It("should send an error reply when checking admin rights fails", func() {
+			telegram.shouldFailOnAdminCheck = true
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
+						ID: MESSAGE_ID,
 						From: &models.User{
 							ID: USER_ID_1,
 						},
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
-							Type: "private",
+							Type: "supergroup",
 						},
-						Text: command,
+						ReplyToMessage: &models.Message{
+							From: &models.User{
+								ID: USER_ID_2,
+							},
+						},
+						Text: "/setbirthday 12.05",
 					},
 				},
 			)
 
-			Expect(repository.deletedUserBirthdays).To(BeEmpty())
-			Expect(telegram.sentMessages).To(HaveExactElements(Message{
-				chatId: CHAT_ID_1,
-				text:   core.MESSAGE_WRONG_CLEAR_DATA_COMMAND,
+			Expect(repository.savedBirthdays).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_ADMIN_CHECK_FAILURE,
 			}))
-		},
-			Entry("no additional text", "/clear"),
-			Entry("additional text not complete", "/clear all"),
-			Entry("wrong additional text", "/clear something else"),
-			Entry("too long additional text", "/clear all data and more"),
-		)
+		})
 
-		It("should send message when deleting data fails", func() {
-			repository.shouldFail = true
+		It("should manage own birthday when replying to own message", func() {
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
+						ID: MESSAGE_ID,
 						From: &models.User{
 							ID: USER_ID_1,
 						},
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
-							Type: "private",
+							Type: "supergroup",
 						},
-						Text: "/clear all data",
+						ReplyToMessage: &models.Message{
+							From: &models.User{
+								ID: USER_ID_1,
+							},
+						},
+						Text: "/unsetbirthday",
 					},
 				},
 			)
 
-			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+			Expect(telegram.adminChecks).To(BeEmpty())
+			Expect(repository.deletedBirthdays).To(HaveExactElements(DeletedBirthday{
 				chatId: CHAT_ID_1,
-				text:   core.MESSAGE_UNSET_FAILURE,
+				userId: USER_ID_1,
 			}))
 		})
 	})
 
-	Describe("private chat", func() {
-		DescribeTable("should respond with help message", func(command string) {
+	Describe("unsetting birthday of a leaving member", func() {
+		It("should delete a birthday without sending any message", func() {
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
+						ID: MESSAGE_ID,
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
-							Type: "private",
+							Type: "supergroup",
+						},
+						LeftChatMember: &models.User{
+							ID: USER_ID_1,
 						},
-						Text: command,
 					},
 				},
 			)
 
-			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+			Expect(repository.deletedBirthdays).To(HaveExactElements(DeletedBirthday{
+				userId: USER_ID_1,
 				chatId: CHAT_ID_1,
-				text:   core.MESSAGE_FULL_HELP,
 			}))
-		},
-			Entry("/help command", "/help"),
-			Entry("/start command", "/start"),
-		)
+			Expect(repository.deletedNameDays).To(HaveExactElements(DeletedBirthday{
+				userId: USER_ID_1,
+				chatId: CHAT_ID_1,
+			}))
+			Expect(repository.deletedWishlistItems).To(HaveExactElements(DeletedBirthday{
+				userId: USER_ID_1,
+				chatId: CHAT_ID_1,
+			}))
+			Expect(telegram.sentReplies).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
 
-		It("should describe privacy policy", func() {
+		It("should delete all data of the chat when the bot is removed", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID, CHAT_ID_2: SENT_MESSAGE_ID + 1}
+			repository.pendingWishes = []core.PendingWish{
+				{AuthorUserId: USER_ID_2, ChatId: CHAT_ID_1, BirthdayUserId: USER_ID_1},
+				{AuthorUserId: USER_ID_2, ChatId: CHAT_ID_2, BirthdayUserId: USER_ID_1},
+			}
+			repository.savedWishes = []core.Wish{{ChatId: CHAT_ID_1, BirthdayUserId: USER_ID_1, AuthorUserId: USER_ID_2, Text: "Happy birthday!"}}
+			repository.birthdayVideos = []core.BirthdayVideo{{Id: 1, ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: 2024, FileId: VIDEO_FILE_ID}}
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
+						ID: MESSAGE_ID,
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
-							Type: "private",
+							Type: "supergroup",
+						},
+						LeftChatMember: &models.User{
+							ID: BOT_ID,
 						},
-						Text: "/privacy",
 					},
 				},
 			)
 
-			Expect(telegram.sentMessages).To(HaveExactElements(Message{
-				chatId: CHAT_ID_1,
-				text:   core.MESSAGE_PRIVACY,
-			}))
+			Expect(repository.deletedGroupBirthdays).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupEvents).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupNameDays).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupWishlistItems).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupCustomPictures).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupWishes).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupBirthdayVideos).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedChatSettings).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.pendingWishes).To(HaveExactElements(core.PendingWish{AuthorUserId: USER_ID_2, ChatId: CHAT_ID_2, BirthdayUserId: USER_ID_1}))
+			Expect(repository.savedWishes).To(BeEmpty())
+			Expect(repository.birthdayVideos).To(BeEmpty())
+			Expect(repository.boardMessageIds).To(Equal(map[int64]int{CHAT_ID_2: SENT_MESSAGE_ID + 1}))
 		})
 
-		It("should respond with a link to the git repository", func() {
+		It("should not send any message when deleting fails", func() {
+			repository.shouldFail = true
 			bot.HandleUpdate(
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
+						ID: MESSAGE_ID,
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
-							Type: "private",
+							Type: "supergroup",
 						},
-						Text: "/source",
+						LeftChatMember: &models.User{
+							ID: USER_ID_1,
+						},
+					},
+				},
+			)
+
+			Expect(telegram.sentReplies).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+	})
+
+	Describe("removing all user data", func() {
+		It("should remove user's data", func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "private",
+						},
+						Text: "/clear all data",
+					},
+				},
+			)
+
+			Expect(repository.deletedUserBirthdays).To(HaveExactElements(USER_ID_1))
+			Expect(repository.deletedUserNameDays).To(HaveExactElements(USER_ID_1))
+			Expect(repository.deletedUserWishes).To(HaveExactElements(USER_ID_1))
+			Expect(repository.deletedUserWishlistItems).To(HaveExactElements(USER_ID_1))
+			Expect(repository.deletedUserBirthdayVideos).To(HaveExactElements(USER_ID_1))
+			Expect(repository.deletedUserCustomPictures).To(HaveExactElements(USER_ID_1))
+			Expect(repository.deletedUserDeliverySteps).To(HaveExactElements(USER_ID_1))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: CHAT_ID_1,
+				text:   core.MESSAGE_DATA_CLEARED,
+			}))
+		})
+
+		DescribeTable("should not remove user's data when command is not complete", func(command string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "private",
+						},
+						Text: command,
+					},
+				},
+			)
+
+			Expect(repository.deletedUserBirthdays).To(BeEmpty())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: CHAT_ID_1,
+				text:   core.MESSAGE_WRONG_CLEAR_DATA_COMMAND,
+			}))
+		},
+			Entry("no additional text", "/clear"),
+			Entry("additional text not complete", "/clear all"),
+			Entry("wrong additional text", "/clear something else"),
+			Entry("too long additional text", "/clear all data and more"),
+		)
+
+		It("should send message when deleting data fails", func() {
+			repository.shouldFail = true
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "private",
+						},
+						Text: "/clear all data",
+					},
+				},
+			)
+
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: CHAT_ID_1,
+				text:   core.MESSAGE_UNSET_FAILURE,
+			}))
+		})
+	})
+
+	Describe("private chat", func() {
+		DescribeTable("should respond with help message", func(command string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "private",
+						},
+						Text: command,
+					},
+				},
+			)
+
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: CHAT_ID_1,
+				text:   core.MESSAGE_FULL_HELP,
+			}))
+		},
+			Entry("/help command", "/help"),
+		)
+
+		It("should describe privacy policy", func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "private",
+						},
+						Text: "/privacy",
+					},
+				},
+			)
+
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: CHAT_ID_1,
+				text:   core.MESSAGE_PRIVACY,
+			}))
+		})
+
+		It("should respond with a link to the git repository", func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "private",
+						},
+						Text: "/source",
 					},
 				},
 			)
@@ -1010,6 +1400,9 @@ var _ = Describe("Birthday manager", func() {
 				context.Background(),
 				&models.Update{
 					Message: &models.Message{
+						From: &models.User{
+							ID: USER_ID_1,
+						},
 						Chat: models.Chat{
 							ID:   CHAT_ID_1,
 							Type: "private",
@@ -1048,151 +1441,2837 @@ var _ = Describe("Birthday manager", func() {
 			Entry("unset birthday", "/unsetbirthday"),
 			Entry("get birthday", "/getbirthday"),
 			Entry("next birthday", "/nextbirthday"),
+			Entry("birthday stats", "/birthdaystats"),
 		)
 	})
 
-	Describe("getting birthday people", func() {
-		It("should return birthday people for a given date", func() {
-			_ = repository.SaveBirthday(context.Background(), core.Birthday{
-				ChatId:        CHAT_ID_1,
-				UserId:        USER_ID_1,
-				Date:          time.Date(DEFAULT_YEAR, 01, 31, 0, 0, 0, 0, time.UTC),
-				Username:      "hackergirl",
-				UserFirstName: "Iwakura",
-				UserLastName:  "Lain",
-			})
-			_ = repository.SaveBirthday(context.Background(), core.Birthday{
-				ChatId:   CHAT_ID_2,
-				UserId:   USER_ID_2,
-				Date:     time.Date(DEFAULT_YEAR, 01, 31, 0, 0, 0, 0, time.UTC),
-				Username: "mizukisan",
-			})
+	Describe("getting birthday stats", func() {
+		sendStatsCommand := func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: "/birthdaystats",
+					},
+				},
+			)
+		}
 
-			result, err := bot.GetBirthdays(context.Background(), NOW)
+		It("should reply with chat statistics", func() {
+			repository.dayCounts = []core.BirthdayDayCount{
+				{DayOfYear: 31, Count: 2},
+				{DayOfYear: 61, Count: 1},
+				{DayOfYear: 70, Count: 1},
+			}
 
-			Expect(repository.requestedBirthdaysForDates).To(HaveExactElements(NOW))
-			Expect(err).To(BeNil())
-			Expect(result).To(ConsistOf(
-				core.BirthdayPerson{
-					ChatId: CHAT_ID_1,
-					UserId: USER_ID_1,
-					Name:   fmt.Sprintf("<a href=\"tg://user?id=%v\">Iwakura Lain</a>", USER_ID_1),
-				},
-				core.BirthdayPerson{
-					ChatId: CHAT_ID_2,
-					UserId: USER_ID_2,
-					Name:   "@mizukisan",
-				},
+			sendStatsCommand()
+
+			Expect(repository.requestedDayCountChatIds).To(HaveExactElements(CHAT_ID_1))
+			Expect(telegram.sentReplies).To(HaveLen(1))
+			Expect(telegram.sentReplies[0].messageId).To(Equal(MESSAGE_ID))
+			text := telegram.sentReplies[0].text
+			Expect(text).To(ContainSubstring("<b>4</b> people"))
+			Expect(text).To(ContainSubstring("Jan ██ 2\nFeb  0\nMar ██ 2\nApr  0"))
+			Expect(text).To(ContainSubstring("The busiest month is <b>January and March</b>"))
+			Expect(text).To(ContainSubstring("Shared birthdays: January 31st (2 people)"))
+			Expect(text).To(ContainSubstring("<b>326 days</b>, between March 10th and January 31st"))
+		})
+
+		It("should scale the histogram for big chats", func() {
+			repository.dayCounts = []core.BirthdayDayCount{
+				{DayOfYear: 1, Count: 40},
+				{DayOfYear: 32, Count: 10},
+			}
+
+			sendStatsCommand()
+
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring(
+				fmt.Sprintf("Jan %v 40\nFeb %v 10", strings.Repeat("█", 20), strings.Repeat("█", 5)),
 			))
 		})
 
-		It("should return empty list when there are no birthdays", func() {
-			result, err := bot.GetBirthdays(context.Background(), NOW)
+		It("should tell when no one shares a birthday", func() {
+			repository.dayCounts = []core.BirthdayDayCount{{DayOfYear: 100, Count: 1}}
 
-			Expect(repository.requestedBirthdaysForDates).To(HaveExactElements(NOW))
-			Expect(err).To(BeNil())
-			Expect(result).To(BeEmpty())
+			sendStatsCommand()
+
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring(core.MESSAGE_STATS_NO_SHARED_BIRTHDAYS))
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring("<b>365 days</b>, between April 9th and April 9th"))
 		})
 
-		It("should pass error from repository", func() {
+		It("should reply when there are no birthdays set", func() {
+			sendStatsCommand()
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_NO_BIRTHDAYS,
+			}))
+		})
+
+		It("should send an error reply when getting stats fails", func() {
 			repository.shouldFail = true
 
-			result, err := bot.GetBirthdays(context.Background(), NOW)
+			sendStatsCommand()
 
-			Expect(err).To(Not(BeNil()))
-			Expect(result).To(BeNil())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_GET_FAILURE,
+			}))
 		})
 	})
-})
 
-// ===== FAKES =====
+	Describe("birthday board", func() {
+		sendBoardCommand := func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: "/board",
+					},
+				},
+			)
+		}
 
-type DeletedBirthday struct {
-	chatId int64
-	userId int64
-}
+		BeforeEach(func() {
+			clock.now = time.Date(2024, 12, 15, 7, 0, 0, 0, time.UTC)
+		})
 
-type RequestedBirthday struct {
-	chatId int64
-	userId int64
-}
+		It("should send and pin a board with this and next month's birthdays", func() {
+			telegram.adminIds = []int64{USER_ID_1}
+			repository.savedBirthdays = []core.Birthday{
+				{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: monthAndDay(12, 24), UserFirstName: FIRST_NAME_1},
+				{ChatId: CHAT_ID_1, UserId: USER_ID_2, Date: monthAndDay(3, 1), UserFirstName: FIRST_NAME_2},
+			}
 
-type FakeRepository struct {
-	savedBirthdays               []core.Birthday
-	deletedBirthdays             []DeletedBirthday
-	deletedGroupBirthdays        []int64
-	deletedUserBirthdays         []int64
-	requestedBirthdays           []RequestedBirthday
-	requestedNextBirthdayChatIds []int64
-	requestedBirthdaysForDates   []time.Time
-	shouldFail                   bool
-}
+			sendBoardCommand()
+
+			Expect(repository.requestedBirthdayRanges).To(HaveExactElements(
+				BirthdayRange{chatId: CHAT_ID_1, from: monthAndDay(12, 1), to: monthAndDay(12, 31)},
+				BirthdayRange{chatId: CHAT_ID_1, from: monthAndDay(1, 1), to: monthAndDay(1, 31)},
+			))
+			Expect(telegram.sentMessages).To(HaveLen(1))
+			Expect(telegram.sentMessages[0].chatId).To(Equal(CHAT_ID_1))
+			Expect(telegram.sentMessages[0].text).To(ContainSubstring(
+				fmt.Sprintf("<b>December</b>\n🎂 <b>December 24th</b> - <a href=\"tg://user?id=%v\">%v</a>\n\n<b>January</b>\n%v", USER_ID_1, FIRST_NAME_1, core.MESSAGE_BOARD_NO_BIRTHDAYS),
+			))
+			Expect(telegram.sentMessages[0].text).To(ContainSubstring("Last updated on December 15th"))
+			Expect(repository.boardMessageIds).To(Equal(map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}))
+			Expect(telegram.pinnedMessages).To(HaveExactElements(Reaction{chatId: CHAT_ID_1, messageId: SENT_MESSAGE_ID}))
+		})
+
+		It("should unpin the previous board when a new one is created", func() {
+			telegram.adminIds = []int64{USER_ID_1}
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID - 1}
+
+			sendBoardCommand()
+
+			Expect(repository.boardMessageIds).To(Equal(map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}))
+			Expect(telegram.unpinnedMessages).To(HaveExactElements(Reaction{chatId: CHAT_ID_1, messageId: SENT_MESSAGE_ID - 1}))
+			Expect(telegram.pinnedMessages).To(HaveExactElements(Reaction{chatId: CHAT_ID_1, messageId: SENT_MESSAGE_ID}))
+		})
+
+		It("should refuse to create a board for non-admins", func() {
+			sendBoardCommand()
+
+			Expect(telegram.sentMessages).To(BeEmpty())
+			Expect(repository.boardMessageIds).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_BOARD_ADMIN_ONLY,
+			}))
+		})
+
+		It("should let the admin know when the board can't be pinned", func() {
+			telegram.adminIds = []int64{USER_ID_1}
+			telegram.shouldFailOnPin = true
+
+			sendBoardCommand()
+
+			Expect(repository.boardMessageIds).To(Equal(map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}))
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_BOARD_PIN_FAILURE,
+			}))
+		})
+
+		It("should edit the board when a birthday changes", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID:        USER_ID_1,
+							FirstName: FIRST_NAME_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: "/setbirthday 20.12",
+					},
+				},
+			)
+
+			Expect(telegram.editedMessages).To(HaveLen(1))
+			Expect(telegram.editedMessages[0].chatId).To(Equal(CHAT_ID_1))
+			Expect(telegram.editedMessages[0].messageId).To(Equal(SENT_MESSAGE_ID))
+			Expect(telegram.editedMessages[0].text).To(ContainSubstring("<b>December 20th</b>"))
+		})
+
+		It("should not edit anything when the chat has no board", func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: "/unsetbirthday",
+					},
+				},
+			)
+
+			Expect(telegram.editedMessages).To(BeEmpty())
+			Expect(telegram.sentReactions).To(HaveLen(1))
+		})
+
+		It("should still confirm a birthday change when refreshing the board fails", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}
+			telegram.editError = errors.New("test")
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: "/setbirthday 20.12",
+					},
+				},
+			)
+
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  core.REACTION_THUMBS_UP,
+			}))
+		})
+
+		It("should edit the boards of every chat when a user clears their data", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID, CHAT_ID_2: SENT_MESSAGE_ID + 1}
+			repository.savedBirthdays = []core.Birthday{
+				{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: monthAndDay(12, 24)},
+				{ChatId: CHAT_ID_2, UserId: USER_ID_1, Date: monthAndDay(12, 24)},
+			}
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   USER_ID_1,
+							Type: "private",
+						},
+						Text: "/clear all data",
+					},
+				},
+			)
+
+			Expect(telegram.editedMessages).To(HaveLen(2))
+			Expect(telegram.editedMessages[0].chatId).To(Equal(CHAT_ID_1))
+			Expect(telegram.editedMessages[1].chatId).To(Equal(CHAT_ID_2))
+		})
+
+		It("should refresh every board", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID, CHAT_ID_2: SENT_MESSAGE_ID + 1}
+
+			err := bot.RefreshBoards(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(telegram.editedMessages).To(HaveLen(2))
+			editedChatIds := []int64{telegram.editedMessages[0].chatId, telegram.editedMessages[1].chatId}
+			Expect(editedChatIds).To(ConsistOf(CHAT_ID_1, CHAT_ID_2))
+		})
+
+		It("should forget a board that was deleted", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}
+			telegram.editError = core.ErrMessageNotFound
+
+			err := bot.RefreshBoards(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(repository.boardMessageIds).To(Equal(map[int64]int{CHAT_ID_1: core.NO_BOARD_MESSAGE_ID}))
+		})
+
+		It("should return an error when refreshing a board fails", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}
+			telegram.editError = errors.New("test")
+
+			err := bot.RefreshBoards(context.Background())
+
+			Expect(err).To(Not(BeNil()))
+		})
+	})
+
+	Describe("monthly digest", func() {
+		sendDigestCommand := func(command string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: command,
+					},
+				},
+			)
+		}
+
+		DescribeTable("should let admins turn the digest on and off", func(command string, expectedSetting bool) {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendDigestCommand(command)
+
+			Expect(repository.digestSettings).To(Equal(map[int64]bool{CHAT_ID_1: expectedSetting}))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  core.REACTION_THUMBS_UP,
+			}))
+		},
+			Entry("for on", "/digest on", true),
+			Entry("for off", "/digest off", false),
+			Entry("for upper case", "/digest ON", true),
+		)
+
+		It("should refuse to change the digest setting for non-admins", func() {
+			sendDigestCommand("/digest on")
+
+			Expect(repository.digestSettings).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_SETTINGS_ADMIN_ONLY,
+			}))
+		})
+
+		DescribeTable("should reply with a help message when the setting is incorrect", func(command string) {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendDigestCommand(command)
+
+			Expect(repository.digestSettings).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_DIGEST_WRONG_FORMAT,
+			}))
+		},
+			Entry("for no setting", "/digest"),
+			Entry("for unknown setting", "/digest maybe"),
+		)
+
+		It("should return birthdays of the whole month for digests", func() {
+			repository.savedBirthdays = []core.Birthday{
+				{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: monthAndDay(2, 29), UserFirstName: FIRST_NAME_1},
+			}
+
+			result, err := bot.GetDigestBirthdays(context.Background(), time.Date(2025, 2, 1, 7, 0, 0, 0, time.UTC))
+
+			Expect(err).To(BeNil())
+			Expect(repository.requestedDigestRanges).To(HaveExactElements(BirthdayRange{from: monthAndDay(2, 1), to: monthAndDay(2, 29)}))
+			Expect(result).To(HaveExactElements(core.BirthdayPerson{
+				ChatId:    CHAT_ID_1,
+				UserId:    USER_ID_1,
+				Name:      fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", USER_ID_1, FIRST_NAME_1),
+				FirstName: FIRST_NAME_1,
+				Date:      monthAndDay(2, 29),
+			}))
+		})
+
+		It("should pass error from repository when getting digest birthdays", func() {
+			repository.shouldFail = true
+
+			result, err := bot.GetDigestBirthdays(context.Background(), NOW)
+
+			Expect(err).To(Not(BeNil()))
+			Expect(result).To(BeNil())
+		})
+	})
+
+	Describe("leap day birthdays", func() {
+		sendCommand := func(command string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: command,
+					},
+				},
+			)
+		}
+		leapDayBirthday := core.Birthday{
+			ChatId:        CHAT_ID_1,
+			UserId:        USER_ID_1,
+			Date:          monthAndDay(2, 29),
+			UserFirstName: FIRST_NAME_1,
+		}
+
+		DescribeTable("should let admins choose the leap day policy", func(command string, expectedPolicy core.LeapDayPolicy) {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendCommand(command)
+
+			Expect(repository.leapDayPolicies).To(Equal(map[int64]core.LeapDayPolicy{CHAT_ID_1: expectedPolicy}))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  core.REACTION_THUMBS_UP,
+			}))
+		},
+			Entry("for February 28th", "/leapday feb28", core.LEAP_DAY_POLICY_FEBRUARY_28),
+			Entry("for March 1st", "/leapday mar1", core.LEAP_DAY_POLICY_MARCH_1),
+			Entry("for upper case", "/leapday MAR1", core.LEAP_DAY_POLICY_MARCH_1),
+		)
+
+		It("should refuse to change the leap day policy for non-admins", func() {
+			sendCommand("/leapday mar1")
+
+			Expect(repository.leapDayPolicies).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_SETTINGS_ADMIN_ONLY,
+			}))
+		})
+
+		DescribeTable("should reply with a help message when the policy is incorrect", func(command string) {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendCommand(command)
+
+			Expect(repository.leapDayPolicies).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_LEAP_DAY_WRONG_FORMAT,
+			}))
+		},
+			Entry("for no policy", "/leapday"),
+			Entry("for unknown policy", "/leapday feb29"),
+			Entry("for too many arguments", "/leapday feb28 mar1"),
+		)
+
+		DescribeTable("should include leap day birthdays celebrated on a given day of a non-leap year",
+			func(date time.Time, expectedPolicy core.LeapDayPolicy) {
+				repository.leapDayBirthdays = map[core.LeapDayPolicy][]core.Birthday{expectedPolicy: {leapDayBirthday}}
+
+				result, err := bot.GetBirthdays(context.Background(), date)
+
+				Expect(err).To(BeNil())
+				Expect(repository.requestedLeapDayPolicies).To(HaveExactElements(expectedPolicy))
+				Expect(result).To(HaveExactElements(core.BirthdayPerson{
+					ChatId:    CHAT_ID_1,
+					UserId:    USER_ID_1,
+					Name:      fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", USER_ID_1, FIRST_NAME_1),
+					FirstName: FIRST_NAME_1,
+					Date:      monthAndDay(2, 29),
+				}))
+			},
+			Entry("for February 28th", time.Date(2025, 2, 28, 7, 0, 0, 0, time.UTC), core.LEAP_DAY_POLICY_FEBRUARY_28),
+			Entry("for March 1st", time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC), core.LEAP_DAY_POLICY_MARCH_1),
+			Entry("for March 1st of a century year", time.Date(2100, 3, 1, 7, 0, 0, 0, time.UTC), core.LEAP_DAY_POLICY_MARCH_1),
+		)
+
+		DescribeTable("should not look for leap day birthdays on other days",
+			func(date time.Time) {
+				_, err := bot.GetBirthdays(context.Background(), date)
+
+				Expect(err).To(BeNil())
+				Expect(repository.requestedLeapDayPolicies).To(BeEmpty())
+			},
+			Entry("for February 28th of a leap year", time.Date(2024, 2, 28, 7, 0, 0, 0, time.UTC)),
+			Entry("for March 1st of a leap year", time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)),
+			Entry("for March 2nd of a non-leap year", time.Date(2025, 3, 2, 7, 0, 0, 0, time.UTC)),
+		)
+
+		It("should pass error from repository when getting leap day birthdays", func() {
+			repository.shouldFailOnLeapDayBirthdays = true
+
+			result, err := bot.GetBirthdays(context.Background(), time.Date(2025, 2, 28, 7, 0, 0, 0, time.UTC))
+
+			Expect(err).To(Not(BeNil()))
+			Expect(result).To(BeNil())
+		})
+
+		DescribeTable("should count down to the celebration day in a non-leap year",
+			func(policy core.LeapDayPolicy, expectedCountdown string) {
+				clock.now = time.Date(2025, 2, 20, 8, 0, 0, 0, time.UTC)
+				repository.leapDayPolicies = map[int64]core.LeapDayPolicy{CHAT_ID_1: policy}
+				_ = repository.SaveBirthday(context.Background(), leapDayBirthday)
+
+				sendCommand("/mybirthday")
+
+				Expect(telegram.sentReplies).To(HaveLen(1))
+				Expect(telegram.sentReplies[0].text).To(ContainSubstring(expectedCountdown))
+			},
+			Entry("for February 28th", core.LEAP_DAY_POLICY_FEBRUARY_28, "Only <b>8 days</b> left until your next birthday! It's on a <b>Friday</b>"),
+			Entry("for March 1st", core.LEAP_DAY_POLICY_MARCH_1, "Only <b>9 days</b> left until your next birthday! It's on a <b>Saturday</b>"),
+		)
+
+		It("should count down to February 28th by default", func() {
+			clock.now = time.Date(2025, 2, 20, 8, 0, 0, 0, time.UTC)
+			_ = repository.SaveBirthday(context.Background(), leapDayBirthday)
+
+			sendCommand("/mybirthday")
+
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring("Only <b>8 days</b> left"))
+		})
+
+		It("should celebrate on February 28th of a non-leap year", func() {
+			clock.now = time.Date(2025, 2, 28, 8, 0, 0, 0, time.UTC)
+			_ = repository.SaveBirthday(context.Background(), leapDayBirthday)
+
+			sendCommand("/mybirthday")
+
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring(core.MESSAGE_BIRTHDAY_TODAY))
+		})
+
+		It("should count down to February 29th in a leap year regardless of the policy", func() {
+			clock.now = time.Date(2028, 2, 20, 8, 0, 0, 0, time.UTC)
+			repository.leapDayPolicies = map[int64]core.LeapDayPolicy{CHAT_ID_1: core.LEAP_DAY_POLICY_MARCH_1}
+			_ = repository.SaveBirthday(context.Background(), leapDayBirthday)
+
+			sendCommand("/mybirthday")
+
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring("Only <b>9 days</b> left until your next birthday! It's on a <b>Tuesday</b>"))
+		})
+
+		It("should fall back to the default policy when the chat's policy can't be read", func() {
+			clock.now = time.Date(2025, 2, 20, 8, 0, 0, 0, time.UTC)
+			repository.shouldFailOnLeapDayPolicy = true
+			_ = repository.SaveBirthday(context.Background(), leapDayBirthday)
+
+			sendCommand("/mybirthday")
+
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring("Only <b>8 days</b> left"))
+		})
+
+		DescribeTable("should show the celebration day of the next leap day birthday",
+			func(now time.Time, policy core.LeapDayPolicy, expectedDate string) {
+				clock.now = now
+				repository.leapDayPolicies = map[int64]core.LeapDayPolicy{CHAT_ID_1: policy}
+				_ = repository.SaveBirthday(context.Background(), leapDayBirthday)
+
+				sendCommand("/nextbirthday")
+
+				Expect(telegram.sentReplies).To(HaveLen(1))
+				Expect(telegram.sentReplies[0].text).To(ContainSubstring(fmt.Sprintf("'s birthday on <b>%v</b>!", expectedDate)))
+			},
+			Entry("for February 28th policy", time.Date(2025, 2, 20, 8, 0, 0, 0, time.UTC), core.LEAP_DAY_POLICY_FEBRUARY_28, "February 28th"),
+			Entry("for March 1st policy", time.Date(2025, 2, 20, 8, 0, 0, 0, time.UTC), core.LEAP_DAY_POLICY_MARCH_1, "March 1st"),
+			Entry("for a leap year", time.Date(2028, 2, 20, 8, 0, 0, 0, time.UTC), core.LEAP_DAY_POLICY_MARCH_1, "February 29th"),
+			Entry("for a leap year coming after the turn of the year", time.Date(2027, 12, 20, 8, 0, 0, 0, time.UTC), core.LEAP_DAY_POLICY_MARCH_1, "February 29th"),
+		)
+	})
+
+	Describe("custom events", func() {
+		sendCommand := func(command string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: USER_ID_1,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: command,
+					},
+				},
+			)
+		}
+
+		DescribeTable("should let admins add an event", func(command string, expectedEvent core.Event) {
+			clock.now = time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendCommand(command)
+
+			Expect(repository.savedEvents).To(HaveExactElements(expectedEvent))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  core.REACTION_THUMBS_UP,
+			}))
+		},
+			Entry("with a kind", "/addevent Rex 14.05 pet",
+				core.Event{ChatId: CHAT_ID_1, Name: "Rex", Kind: core.EVENT_KIND_PET, Date: monthAndDay(5, 14)}),
+			Entry("with a multi-word name and a year", "/addevent Team anniversary 01.09.2019 Anniversary",
+				core.Event{ChatId: CHAT_ID_1, Name: "Team anniversary", Kind: core.EVENT_KIND_ANNIVERSARY, Date: monthAndDay(9, 1), Year: 2019}),
+			Entry("without a kind", "/addevent Founding day 3.2",
+				core.Event{ChatId: CHAT_ID_1, Name: "Founding day", Kind: core.DEFAULT_EVENT_KIND, Date: monthAndDay(2, 3)}),
+			Entry("with a name that looks like a kind", "/addevent pet 3.2",
+				core.Event{ChatId: CHAT_ID_1, Name: "pet", Kind: core.DEFAULT_EVENT_KIND, Date: monthAndDay(2, 3)}),
+		)
+
+		DescribeTable("should reply with a help message when the event is incorrect", func(command string) {
+			clock.now = time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendCommand(command)
+
+			Expect(repository.savedEvents).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_ADD_EVENT_WRONG_FORMAT,
+			}))
+		},
+			Entry("for no arguments", "/addevent"),
+			Entry("for no name", "/addevent 14.05"),
+			Entry("for no name with a kind", "/addevent 14.05 pet"),
+			Entry("for an incorrect date", "/addevent Rex 31.02 pet"),
+			Entry("for a year in the future", "/addevent Rex 14.05.2030 pet"),
+			Entry("for a too long name", fmt.Sprintf("/addevent %v 14.05", strings.Repeat("a", core.MAX_EVENT_NAME_LENGTH+1))),
+		)
+
+		It("should read the event from the caption of a photo", func() {
+			clock.now = time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+			telegram.adminIds = []int64{USER_ID_1}
+
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID:      MESSAGE_ID,
+						From:    &models.User{ID: USER_ID_1},
+						Chat:    models.Chat{ID: CHAT_ID_1, Type: "supergroup"},
+						Photo:   []models.PhotoSize{{FileID: "photo_file_id"}},
+						Caption: "/addevent Rex 14.05 pet",
+					},
+				},
+			)
+
+			Expect(repository.savedEvents).To(HaveExactElements(
+				core.Event{ChatId: CHAT_ID_1, Name: "Rex", Kind: core.EVENT_KIND_PET, Date: monthAndDay(5, 14)},
+			))
+		})
+
+		It("should reply with a help message when a photo is captioned with the command only", func() {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID:      MESSAGE_ID,
+						From:    &models.User{ID: USER_ID_1},
+						Chat:    models.Chat{ID: CHAT_ID_1, Type: "supergroup"},
+						Photo:   []models.PhotoSize{{FileID: "photo_file_id"}},
+						Caption: "/addevent",
+					},
+				},
+			)
+
+			Expect(repository.savedEvents).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_ADD_EVENT_WRONG_FORMAT,
+			}))
+		})
+
+		It("should refuse to add an event for non-admins", func() {
+			sendCommand("/addevent Rex 14.05 pet")
+
+			Expect(repository.savedEvents).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_EVENTS_ADMIN_ONLY,
+			}))
+		})
+
+		It("should reply with a failure message when saving an event fails", func() {
+			telegram.adminIds = []int64{USER_ID_1}
+			repository.shouldFail = true
+
+			sendCommand("/addevent Rex 14.05 pet")
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_SAVE_FAILURE,
+			}))
+		})
+
+		It("should list events of the chat with escaped names", func() {
+			repository.savedEvents = []core.Event{
+				{ChatId: CHAT_ID_1, Name: "Founding <day>", Kind: core.EVENT_KIND_FOUNDING, Date: monthAndDay(2, 3)},
+				{ChatId: CHAT_ID_1, Name: "Rex", Kind: core.EVENT_KIND_PET, Date: monthAndDay(5, 14)},
+			}
+
+			sendCommand("/events")
+
+			Expect(repository.requestedEventChatIds).To(HaveExactElements(CHAT_ID_1))
+			Expect(telegram.sentReplies).To(HaveLen(1))
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring(
+				"🎉 <b>Founding &lt;day&gt;</b> - February 3rd (founding)\n🎉 <b>Rex</b> - May 14th (pet)",
+			))
+		})
+
+		It("should reply when there are no events", func() {
+			sendCommand("/events")
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_NO_EVENTS,
+			}))
+		})
+
+		It("should let admins remove an event", func() {
+			telegram.adminIds = []int64{USER_ID_1}
+			repository.savedEvents = []core.Event{{ChatId: CHAT_ID_1, Name: "Team anniversary"}}
+
+			sendCommand("/removeevent team anniversary")
+
+			Expect(repository.deletedEvents).To(HaveExactElements(DeletedEvent{chatId: CHAT_ID_1, name: "team anniversary"}))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  core.REACTION_THUMBS_UP,
+			}))
+		})
+
+		It("should reply when the removed event doesn't exist", func() {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendCommand("/removeevent Rex")
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_EVENT_NOT_FOUND,
+			}))
+		})
+
+		It("should refuse to remove an event for non-admins", func() {
+			sendCommand("/removeevent Rex")
+
+			Expect(repository.deletedEvents).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_EVENTS_ADMIN_ONLY,
+			}))
+		})
+
+		It("should reply with a help message when the removed event has no name", func() {
+			sendCommand("/removeevent")
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_REMOVE_EVENT_WRONG_FORMAT,
+			}))
+		})
+
+		It("should return events for a given date including leap day events", func() {
+			date := time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC)
+			event := core.Event{ChatId: CHAT_ID_1, Name: "Rex", Kind: core.EVENT_KIND_PET, Date: monthAndDay(3, 1)}
+			leapDayEvent := core.Event{ChatId: CHAT_ID_2, Name: "Leap", Kind: core.EVENT_KIND_OTHER, Date: monthAndDay(2, 29)}
+			repository.savedEvents = []core.Event{event}
+			repository.leapDayEvents = map[core.LeapDayPolicy][]core.Event{core.LEAP_DAY_POLICY_MARCH_1: {leapDayEvent}}
+
+			result, err := bot.GetEvents(context.Background(), date)
+
+			Expect(err).To(BeNil())
+			Expect(repository.requestedEventDates).To(HaveExactElements(date))
+			Expect(result).To(HaveExactElements(event, leapDayEvent))
+		})
+
+		It("should pass error from repository when getting events", func() {
+			repository.shouldFail = true
+
+			result, err := bot.GetEvents(context.Background(), NOW)
+
+			Expect(err).To(Not(BeNil()))
+			Expect(result).To(BeNil())
+		})
+	})
+
+	Describe("name days", func() {
+		sendCommand := func(command string, firstName string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID:        USER_ID_1,
+							Username:  USER_NAME_1,
+							FirstName: firstName,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: command,
+					},
+				},
+			)
+		}
+
+		DescribeTable("should let admins choose the name day calendar", func(command string, expectedCountry string) {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendCommand(command, FIRST_NAME_1)
+
+			Expect(repository.nameDayCountries).To(Equal(map[int64]string{CHAT_ID_1: expectedCountry}))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  core.REACTION_THUMBS_UP,
+			}))
+		},
+			Entry("for Poland", "/namedaycountry pl", "pl"),
+			Entry("for Czechia", "/namedaycountry cs", "cs"),
+			Entry("for Hungary", "/namedaycountry HU", "hu"),
+			Entry("for Greece", "/namedaycountry el", "el"),
+			Entry("for turning name days off", "/namedaycountry off", core.NAME_DAY_COUNTRY_NONE),
+		)
+
+		DescribeTable("should reply with the available calendars when the country is incorrect", func(command string) {
+			telegram.adminIds = []int64{USER_ID_1}
+
+			sendCommand(command, FIRST_NAME_1)
+
+			Expect(repository.nameDayCountries).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveLen(1))
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring("<code>/namedaycountry cs|el|hu|pl</code>"))
+		},
+			Entry("for no country", "/namedaycountry"),
+			Entry("for unknown country", "/namedaycountry xx"),
+		)
+
+		It("should refuse to change the name day calendar for non-admins", func() {
+			sendCommand("/namedaycountry pl", FIRST_NAME_1)
+
+			Expect(repository.nameDayCountries).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_SETTINGS_ADMIN_ONLY,
+			}))
+		})
+
+		DescribeTable("should save the name day from the chat's calendar",
+			func(country string, command string, expectedName string, expectedDate string) {
+				repository.nameDayCountries = map[int64]string{CHAT_ID_1: country}
+
+				sendCommand(command, FIRST_NAME_1)
+
+				Expect(repository.savedNameDays).To(HaveExactElements(core.NameDay{
+					ChatId:        CHAT_ID_1,
+					UserId:        USER_ID_1,
+					Name:          expectedName,
+					Username:      USER_NAME_1,
+					UserFirstName: FIRST_NAME_1,
+				}))
+				Expect(telegram.sentReplies).To(HaveLen(1))
+				Expect(telegram.sentReplies[0].text).To(ContainSubstring(fmt.Sprintf("as <b>%v</b> on <b>%v</b>", expectedName, expectedDate)))
+			},
+			Entry("for Polish calendar", "pl", "/setnameday anna", "Anna", "July 26th"),
+			Entry("for Czech calendar", "cs", "/setnameday Václav", "Václav", "September 28th"),
+			Entry("for Hungarian calendar", "hu", "/setnameday istván", "István", "August 20th"),
+			Entry("for Greek calendar", "el", "/setnameday Νίκος", "Νίκος", "December 6th"),
+			Entry("for transliterated Greek name", "el", "/setnameday nikos", "Nikos", "December 6th"),
+		)
+
+		It("should refuse to save a name day when the chat has no calendar", func() {
+			sendCommand("/setnameday Anna", FIRST_NAME_1)
+
+			Expect(repository.savedNameDays).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_NAME_DAY_NO_COUNTRY,
+			}))
+		})
+
+		It("should refuse to save a name that isn't in the calendar", func() {
+			repository.nameDayCountries = map[int64]string{CHAT_ID_1: "pl"}
+
+			sendCommand("/setnameday Kyaa", FIRST_NAME_1)
+
+			Expect(repository.savedNameDays).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_NAME_DAY_UNKNOWN_NAME,
+			}))
+		})
+
+		It("should suggest a name day based on the first name and wait for confirmation", func() {
+			repository.nameDayCountries = map[int64]string{CHAT_ID_1: "pl"}
+
+			sendCommand("/setnameday", "katarzyna")
+
+			Expect(repository.savedNameDays).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveLen(1))
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring("on <b>November 25th</b> as <b>Katarzyna</b>"))
+			Expect(telegram.sentReplies[0].text).To(ContainSubstring("<code>/setnameday Katarzyna</code>"))
+		})
+
+		It("should ask for a name when the first name isn't in the calendar", func() {
+			repository.nameDayCountries = map[int64]string{CHAT_ID_1: "pl"}
+
+			sendCommand("/setnameday", "Lain")
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_NAME_DAY_WRONG_FORMAT,
+			}))
+		})
+
+		It("should reply with a failure message when reading the calendar of the chat fails", func() {
+			repository.shouldFail = true
+
+			sendCommand("/setnameday Anna", FIRST_NAME_1)
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      core.MESSAGE_GET_FAILURE,
+			}))
+		})
+
+		It("should unset the name day", func() {
+			sendCommand("/unsetnameday", FIRST_NAME_1)
+
+			Expect(repository.deletedNameDays).To(HaveExactElements(DeletedBirthday{chatId: CHAT_ID_1, userId: USER_ID_1}))
+			Expect(telegram.sentReactions).To(HaveLen(1))
+		})
+
+		It("should return name day people of every calendar celebrating on a given date", func() {
+			repository.nameDays = []core.NameDay{
+				{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: "Anna", UserFirstName: FIRST_NAME_1},
+			}
+
+			result, err := bot.GetNameDays(context.Background(), time.Date(2025, 7, 26, 7, 0, 0, 0, time.UTC))
+
+			Expect(err).To(BeNil())
+			Expect(repository.requestedNameDays).To(HaveExactElements(
+				RequestedNameDays{country: "cs", names: []string{"Anna"}},
+				RequestedNameDays{country: "el", names: []string{"Παρασκευή", "Paraskevi"}},
+				RequestedNameDays{country: "hu", names: []string{"Anna"}},
+				RequestedNameDays{country: "pl", names: []string{"Anna"}},
+			))
+			Expect(result).To(ContainElement(core.NameDayPerson{
+				ChatId:      CHAT_ID_1,
+				UserId:      USER_ID_1,
+				Name:        fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", USER_ID_1, FIRST_NAME_1),
+				NameDayName: "Anna",
+				Country:     "pl",
+			}))
+		})
+
+		It("should not query the repository when no calendar has names on a given date", func() {
+			result, err := bot.GetNameDays(context.Background(), time.Date(2025, 8, 28, 7, 0, 0, 0, time.UTC))
+
+			Expect(err).To(BeNil())
+			Expect(result).To(BeEmpty())
+			Expect(repository.requestedNameDays).To(BeEmpty())
+		})
+
+		It("should pass error from repository when getting name days", func() {
+			repository.shouldFail = true
+
+			result, err := bot.GetNameDays(context.Background(), time.Date(2025, 7, 26, 7, 0, 0, 0, time.UTC))
+
+			Expect(err).To(Not(BeNil()))
+			Expect(result).To(BeNil())
+		})
+	})
+
+	Describe("wishes", func() {
+		sendPrivateMessage := func(message *models.Message) {
+			message.From = &models.User{
+				ID:        USER_ID_2,
+				Username:  USER_NAME_2,
+				FirstName: FIRST_NAME_2,
+			}
+			message.Chat = models.Chat{
+				ID:   USER_ID_2,
+				Type: "private",
+			}
+			bot.HandleUpdate(context.Background(), &models.Update{Message: message})
+		}
+		birthday := core.Birthday{
+			ChatId:        CHAT_ID_1,
+			UserId:        USER_ID_1,
+			Username:      USER_NAME_1,
+			UserFirstName: FIRST_NAME_1,
+		}
+		today := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+		birthdayDate := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+		birthdayPersonName := fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", USER_ID_1, FIRST_NAME_1)
+		pendingWish := core.PendingWish{
+			AuthorUserId:       USER_ID_2,
+			ChatId:             CHAT_ID_1,
+			BirthdayUserId:     USER_ID_1,
+			BirthdayPersonName: birthdayPersonName,
+			Date:               birthdayDate,
+		}
+
+		BeforeEach(func() {
+			clock.now = time.Date(2024, 3, 11, 11, 0, 0, 0, time.UTC)
+		})
+
+		It("should let users who start a private chat be invited to send wishes", func() {
+			sendPrivateMessage(&models.Message{Text: "/start"})
+
+			Expect(repository.savedWishContributors).To(HaveExactElements(USER_ID_2))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: USER_ID_2,
+				text:   core.MESSAGE_FULL_HELP,
+			}))
+		})
+
+		It("should invite contributors to send a wish a few days before the birthday", func() {
+			repository.savedBirthdays = []core.Birthday{birthday}
+			repository.wishContributors = map[int64][]int64{CHAT_ID_1: {USER_ID_1, USER_ID_2}}
+
+			err := bot.SendWishInvitations(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(repository.deletedWishesBefore).To(HaveExactElements(today))
+			Expect(repository.requestedBirthdaysForDates).To(HaveExactElements(birthdayDate))
+			Expect(repository.pendingWishes).To(HaveExactElements(pendingWish))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: USER_ID_2,
+				text:   fmt.Sprintf(core.MESSAGE_WISH_INVITATION, birthdayPersonName, "March 14th"),
+			}))
+		})
+
+		It("should not invite a contributor again before they answer the previous invitation", func() {
+			repository.savedBirthdays = []core.Birthday{birthday}
+			repository.wishContributors = map[int64][]int64{CHAT_ID_1: {USER_ID_2}}
+			earlierPendingWish := core.PendingWish{
+				AuthorUserId:   USER_ID_2,
+				ChatId:         CHAT_ID_2,
+				BirthdayUserId: USER_ID_1,
+				Date:           today.AddDate(0, 0, 1),
+			}
+			repository.pendingWishes = []core.PendingWish{earlierPendingWish}
+
+			err := bot.SendWishInvitations(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(repository.pendingWishes).To(HaveExactElements(earlierPendingWish, pendingWish))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error when inviting fails", func() {
+			repository.savedBirthdays = []core.Birthday{birthday}
+			repository.shouldFailOnWishes = true
+
+			err := bot.SendWishInvitations(context.Background())
+
+			Expect(err).To(Not(BeNil()))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should save a text answer as a wish and ask for the next one", func() {
+			nextPendingWish := core.PendingWish{
+				AuthorUserId:       USER_ID_2,
+				ChatId:             CHAT_ID_2,
+				BirthdayUserId:     USER_ID_1,
+				BirthdayPersonName: birthdayPersonName,
+				Date:               birthdayDate.AddDate(0, 0, 1),
+			}
+			repository.pendingWishes = []core.PendingWish{pendingWish, nextPendingWish}
+
+			sendPrivateMessage(&models.Message{Text: "  Happy birthday!  "})
+
+			Expect(repository.savedWishes).To(HaveExactElements(core.Wish{
+				ChatId:          CHAT_ID_1,
+				BirthdayUserId:  USER_ID_1,
+				Date:            birthdayDate,
+				AuthorUserId:    USER_ID_2,
+				AuthorUsername:  USER_NAME_2,
+				AuthorFirstName: FIRST_NAME_2,
+				Text:            "Happy birthday!",
+			}))
+			Expect(repository.pendingWishes).To(HaveExactElements(nextPendingWish))
+			Expect(telegram.sentMessages).To(HaveExactElements(
+				Message{chatId: USER_ID_2, text: core.MESSAGE_WISH_SAVED},
+				Message{chatId: USER_ID_2, text: fmt.Sprintf(core.MESSAGE_WISH_INVITATION, birthdayPersonName, "March 15th")},
+			))
+		})
+
+		It("should save a voice answer as a wish", func() {
+			repository.pendingWishes = []core.PendingWish{pendingWish}
+
+			sendPrivateMessage(&models.Message{Voice: &models.Voice{FileID: VOICE_FILE_ID}})
+
+			Expect(repository.savedWishes).To(HaveLen(1))
+			Expect(repository.savedWishes[0].VoiceFileId).To(Equal(VOICE_FILE_ID))
+			Expect(repository.savedWishes[0].Text).To(BeEmpty())
+			Expect(repository.pendingWishes).To(BeEmpty())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: USER_ID_2, text: core.MESSAGE_WISH_SAVED}))
+		})
+
+		It("should ignore invitations for birthdays that already passed", func() {
+			repository.pendingWishes = []core.PendingWish{{AuthorUserId: USER_ID_2, ChatId: CHAT_ID_1, BirthdayUserId: USER_ID_1, Date: today.AddDate(0, 0, -1)}}
+
+			sendPrivateMessage(&models.Message{Text: "Happy birthday!"})
+
+			Expect(repository.savedWishes).To(BeEmpty())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: USER_ID_2, text: core.MESSAGE_SHORT_HELP}))
+		})
+
+		It("should refuse answers that can't be delivered as wishes", func() {
+			repository.pendingWishes = []core.PendingWish{pendingWish}
+
+			sendPrivateMessage(&models.Message{Sticker: &models.Sticker{FileID: "sticker"}})
+
+			Expect(repository.savedWishes).To(BeEmpty())
+			Expect(repository.pendingWishes).To(HaveExactElements(pendingWish))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: USER_ID_2, text: core.MESSAGE_WISH_UNSUPPORTED}))
+		})
+
+		It("should refuse wishes that are too long", func() {
+			repository.pendingWishes = []core.PendingWish{pendingWish}
+
+			sendPrivateMessage(&models.Message{Text: strings.Repeat("a", core.MAX_WISH_LENGTH+1)})
+
+			Expect(repository.savedWishes).To(BeEmpty())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: USER_ID_2,
+				text:   fmt.Sprintf(core.MESSAGE_WISH_TOO_LONG, core.MAX_WISH_LENGTH),
+			}))
+		})
+
+		It("should let the contributor skip a wish", func() {
+			repository.pendingWishes = []core.PendingWish{pendingWish}
+
+			sendPrivateMessage(&models.Message{Text: "/skipwish"})
+
+			Expect(repository.savedWishes).To(BeEmpty())
+			Expect(repository.pendingWishes).To(BeEmpty())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: USER_ID_2, text: core.MESSAGE_WISH_SKIPPED}))
+		})
+
+		It("should tell the contributor when there is no wish to skip", func() {
+			sendPrivateMessage(&models.Message{Text: "/skipwish"})
+
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: USER_ID_2, text: core.MESSAGE_NO_PENDING_WISHES}))
+		})
+
+		It("should return collected wishes with author names", func() {
+			repository.savedWishes = []core.Wish{
+				{ChatId: CHAT_ID_1, BirthdayUserId: USER_ID_1, Date: birthdayDate, AuthorUserId: USER_ID_2, AuthorFirstName: FIRST_NAME_2, Text: "Happy birthday!"},
+				{ChatId: CHAT_ID_1, BirthdayUserId: USER_ID_1, Date: birthdayDate, AuthorUserId: USER_ID_2, AuthorUsername: USER_NAME_2, VoiceFileId: VOICE_FILE_ID},
+			}
+
+			wishes, err := bot.GetWishes(context.Background(), CHAT_ID_1, USER_ID_1, birthdayDate)
+
+			Expect(err).To(BeNil())
+			Expect(repository.requestedWishes).To(HaveExactElements(RequestedWishes{chatId: CHAT_ID_1, userId: USER_ID_1, date: birthdayDate}))
+			Expect(wishes).To(HaveExactElements(
+				core.CollectedWish{AuthorName: fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", USER_ID_2, FIRST_NAME_2), Text: "Happy birthday!"},
+				core.CollectedWish{AuthorName: "@" + USER_NAME_2, VoiceFileId: VOICE_FILE_ID},
+			))
+		})
+	})
+
+	Describe("wishlists", func() {
+		sendCommand := func(command string, chatType string, replyTo *models.User) {
+			var replyToMessage *models.Message
+			if replyTo != nil {
+				replyToMessage = &models.Message{From: replyTo}
+			}
+			chatId := CHAT_ID_1
+			if chatType == "private" {
+				chatId = USER_ID_1
+			}
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID:        USER_ID_1,
+							Username:  USER_NAME_1,
+							FirstName: FIRST_NAME_1,
+						},
+						Chat: models.Chat{
+							ID:   chatId,
+							Type: chatType,
+						},
+						Text:           command,
+						ReplyToMessage: replyToMessage,
+					},
+				},
+			)
+		}
+		pressButton := func(userId int64, data string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					CallbackQuery: &models.CallbackQuery{
+						ID:   CALLBACK_ID,
+						From: models.User{ID: userId},
+						Message: models.MaybeInaccessibleMessage{
+							Message: &models.Message{
+								ID:   SENT_MESSAGE_ID,
+								Chat: models.Chat{ID: CHAT_ID_1, Type: "supergroup"},
+							},
+						},
+						Data: data,
+					},
+				},
+			)
+		}
+		otherUser := &models.User{ID: USER_ID_2, FirstName: FIRST_NAME_2}
+		ownerName := fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", USER_ID_2, FIRST_NAME_2)
+		itemOf := func(userId int64, chatId int64, text string) core.WishlistItem {
+			return core.WishlistItem{UserId: userId, ChatId: chatId, Text: text, UserFirstName: FIRST_NAME_2}
+		}
+
+		DescribeTable("should add a wishlist item scoped to the chat it was added in", func(chatType string, expectedChatId int64) {
+			sendCommand("/wishlist add  Totoro plushie ", chatType, nil)
+
+			Expect(repository.wishlistItems).To(HaveExactElements(core.WishlistItem{
+				Id:            1,
+				UserId:        USER_ID_1,
+				ChatId:        expectedChatId,
+				Text:          "Totoro plushie",
+				Username:      USER_NAME_1,
+				UserFirstName: FIRST_NAME_1,
+			}))
+			Expect(telegram.sentReactions).To(HaveLen(1))
+			Expect(telegram.sentReactions[0].reaction).To(Equal(core.REACTION_THUMBS_UP))
+		},
+			Entry("globally in a private chat", "private", core.WISHLIST_SCOPE_GLOBAL),
+			Entry("only in a group", "supergroup", CHAT_ID_1),
+		)
+
+		DescribeTable("should reply with the format when the command is incorrect", func(command string) {
+			sendCommand(command, "supergroup", nil)
+
+			Expect(repository.wishlistItems).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{chatId: CHAT_ID_1, messageId: MESSAGE_ID, text: core.MESSAGE_WISHLIST_WRONG_FORMAT}))
+		},
+			Entry("no item", "/wishlist add"),
+			Entry("unknown action", "/wishlist buy"),
+			Entry("no number", "/wishlist remove"),
+			Entry("not a number", "/wishlist remove first"),
+		)
+
+		It("should refuse to add an item when the wishlist is full", func() {
+			for range core.MAX_WISHLIST_ITEMS {
+				repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_1, core.WISHLIST_SCOPE_GLOBAL, "gift"))
+			}
+
+			sendCommand("/wishlist add One more", "supergroup", nil)
+
+			Expect(repository.wishlistItems).To(HaveLen(core.MAX_WISHLIST_ITEMS))
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      fmt.Sprintf(core.MESSAGE_WISHLIST_FULL, core.MAX_WISHLIST_ITEMS),
+			}))
+		})
+
+		It("should show own wishlist from every chat in a private chat without revealing claims", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_1, core.WISHLIST_SCOPE_GLOBAL, "Books & <comics>"))
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_1, CHAT_ID_1, "Tea"))
+			repository.wishlistItems[0].ClaimedBy = USER_ID_2
+
+			sendCommand("/wishlist", "private", nil)
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{
+				chatId:    USER_ID_1,
+				messageId: MESSAGE_ID,
+				text:      fmt.Sprintf(core.MESSAGE_OWN_WISHLIST, "1. Books &amp; &lt;comics&gt;\n2. Tea"+core.MESSAGE_WISHLIST_GROUP_ONLY),
+			}))
+			Expect(telegram.sentRepliesWithButtons).To(BeEmpty())
+		})
+
+		It("should remove an item by its number on the list", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_1, core.WISHLIST_SCOPE_GLOBAL, "Books"))
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_1, CHAT_ID_2, "Tea"))
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_1, CHAT_ID_1, "Cake"))
+
+			sendCommand("/wishlist remove 2", "supergroup", nil)
+
+			Expect(repository.wishlistItems).To(HaveLen(2))
+			Expect(repository.wishlistItems[0].Text).To(Equal("Books"))
+			Expect(repository.wishlistItems[1].Text).To(Equal("Tea"))
+		})
+
+		It("should reply when there is no item with the given number", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_1, core.WISHLIST_SCOPE_GLOBAL, "Books"))
+
+			sendCommand("/wishlist remove 2", "supergroup", nil)
+
+			Expect(repository.wishlistItems).To(HaveLen(1))
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{chatId: CHAT_ID_1, messageId: MESSAGE_ID, text: core.MESSAGE_WISHLIST_ITEM_NOT_FOUND}))
+		})
+
+		It("should show someone else's wishlist with buttons to claim gifts", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, core.WISHLIST_SCOPE_GLOBAL, "Books"))
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, CHAT_ID_2, "Tea"))
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, CHAT_ID_1, "Cake"))
+			repository.wishlistItems[2].ClaimedBy = USER_ID_1
+
+			sendCommand("/wishlist", "supergroup", otherUser)
+
+			Expect(telegram.sentRepliesWithButtons).To(HaveExactElements(ReplyWithButtons{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				text:      fmt.Sprintf(core.MESSAGE_WISHLIST, ownerName, "1. Books\n2. Cake"+core.MESSAGE_WISHLIST_CLAIMED_SUFFIX),
+				buttons: []core.InlineButton{
+					{Text: "🎁 1", Data: "wishlist:1"},
+					{Text: "✅ 2", Data: "wishlist:3"},
+				},
+			}))
+		})
+
+		It("should reply when someone else's wishlist is empty", func() {
+			sendCommand("/wishlist", "supergroup", otherUser)
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{chatId: CHAT_ID_1, messageId: MESSAGE_ID, text: core.MESSAGE_EMPTY_WISHLIST}))
+		})
+
+		It("should secretly claim a gift and refresh the wishlist", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, core.WISHLIST_SCOPE_GLOBAL, "Books"))
+
+			pressButton(USER_ID_1, "wishlist:1")
+
+			Expect(repository.wishlistItems[0].ClaimedBy).To(Equal(USER_ID_1))
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: core.MESSAGE_WISHLIST_ITEM_CLAIMED}))
+			Expect(telegram.editedMessagesWithButtons).To(HaveExactElements(ReplyWithButtons{
+				chatId:    CHAT_ID_1,
+				messageId: SENT_MESSAGE_ID,
+				text:      fmt.Sprintf(core.MESSAGE_WISHLIST, ownerName, "1. Books"+core.MESSAGE_WISHLIST_CLAIMED_SUFFIX),
+				buttons:   []core.InlineButton{{Text: "✅ 1", Data: "wishlist:1"}},
+			}))
+			Expect(telegram.sentMessages).To(BeEmpty())
+			Expect(telegram.sentReplies).To(BeEmpty())
+		})
+
+		It("should unclaim a gift claimed by the same member", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, core.WISHLIST_SCOPE_GLOBAL, "Books"))
+			repository.wishlistItems[0].ClaimedBy = USER_ID_1
+
+			pressButton(USER_ID_1, "wishlist:1")
+
+			Expect(repository.wishlistItems[0].ClaimedBy).To(Equal(core.NOT_CLAIMED))
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: core.MESSAGE_WISHLIST_ITEM_UNCLAIMED}))
+			Expect(telegram.editedMessagesWithButtons).To(HaveLen(1))
+		})
+
+		It("should not let a member take a gift claimed by someone else", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, core.WISHLIST_SCOPE_GLOBAL, "Books"))
+			repository.wishlistItems[0].ClaimedBy = BOT_ID
+
+			pressButton(USER_ID_1, "wishlist:1")
+
+			Expect(repository.wishlistItems[0].ClaimedBy).To(Equal(BOT_ID))
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: core.MESSAGE_WISHLIST_ITEM_TAKEN}))
+		})
+
+		It("should not let the owner claim their own gift", func() {
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, core.WISHLIST_SCOPE_GLOBAL, "Books"))
+
+			pressButton(USER_ID_2, "wishlist:1")
+
+			Expect(repository.wishlistItems[0].ClaimedBy).To(Equal(core.NOT_CLAIMED))
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: core.MESSAGE_WISHLIST_OWN_ITEM}))
+			Expect(telegram.editedMessagesWithButtons).To(BeEmpty())
+		})
+
+		It("should tell the member when the gift was removed", func() {
+			pressButton(USER_ID_1, "wishlist:1")
+
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: core.MESSAGE_WISHLIST_ITEM_GONE}))
+		})
+
+		It("should list the wishlist in wish invitations", func() {
+			clock.now = time.Date(2024, 3, 11, 11, 0, 0, 0, time.UTC)
+			repository.savedBirthdays = []core.Birthday{{ChatId: CHAT_ID_1, UserId: USER_ID_2, UserFirstName: FIRST_NAME_2}}
+			repository.wishContributors = map[int64][]int64{CHAT_ID_1: {USER_ID_1}}
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, CHAT_ID_1, "Tea"))
+			repository.SaveWishlistItem(context.Background(), itemOf(USER_ID_2, CHAT_ID_2, "Cake"))
+
+			bot.SendWishInvitations(context.Background())
+
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: USER_ID_1,
+				text: fmt.Sprintf(core.MESSAGE_WISH_INVITATION, ownerName, "March 14th") +
+					fmt.Sprintf(core.MESSAGE_WISH_INVITATION_WISHLIST, "1. Tea"),
+			}))
+		})
+	})
+
+	Describe("birthday videos", func() {
+		sendMyVideos := func() {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID:   MESSAGE_ID,
+						From: &models.User{ID: USER_ID_1},
+						Chat: models.Chat{ID: USER_ID_1, Type: "private"},
+						Text: "/myvideos",
+					},
+				},
+			)
+		}
+		pressButton := func(userId int64, data string) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					CallbackQuery: &models.CallbackQuery{
+						ID:   CALLBACK_ID,
+						From: models.User{ID: userId},
+						Data: data,
+					},
+				},
+			)
+		}
+
+		It("should archive a birthday video sent by the notifier", func() {
+			video := core.BirthdayVideo{ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: 2024, FileId: VIDEO_FILE_ID}
+
+			err := bot.SaveBirthdayVideo(context.Background(), video)
+
+			Expect(err).To(BeNil())
+			video.Id = 1
+			Expect(repository.birthdayVideos).To(HaveExactElements(video))
+		})
+
+		It("should return the cached video only for the same profile picture", func() {
+			bot.CacheVideo(context.Background(), core.CachedVideo{UserId: USER_ID_1, PictureUniqueId: "picture_1", FileId: VIDEO_FILE_ID})
+
+			samePictureFileId, samePictureErr := bot.GetCachedVideoFileId(context.Background(), USER_ID_1, "picture_1")
+			changedPictureFileId, changedPictureErr := bot.GetCachedVideoFileId(context.Background(), USER_ID_1, "picture_2")
+
+			Expect(samePictureErr).To(BeNil())
+			Expect(samePictureFileId).To(Equal(VIDEO_FILE_ID))
+			Expect(changedPictureErr).To(BeNil())
+			Expect(changedPictureFileId).To(BeEmpty())
+		})
+
+		It("should keep cached videos of different pictures of the same user", func() {
+			bot.CacheVideo(context.Background(), core.CachedVideo{UserId: USER_ID_1, PictureUniqueId: "picture_1", FileId: VIDEO_FILE_ID})
+			bot.CacheVideo(context.Background(), core.CachedVideo{UserId: USER_ID_1, PictureUniqueId: "picture_2", FileId: VIDEO_FILE_ID_2})
+
+			firstPictureFileId, firstPictureErr := bot.GetCachedVideoFileId(context.Background(), USER_ID_1, "picture_1")
+			secondPictureFileId, secondPictureErr := bot.GetCachedVideoFileId(context.Background(), USER_ID_1, "picture_2")
+
+			Expect(firstPictureErr).To(BeNil())
+			Expect(firstPictureFileId).To(Equal(VIDEO_FILE_ID))
+			Expect(secondPictureErr).To(BeNil())
+			Expect(secondPictureFileId).To(Equal(VIDEO_FILE_ID_2))
+		})
+
+		It("should list past birthday videos with buttons to watch them again", func() {
+			repository.SaveBirthdayVideo(context.Background(), core.BirthdayVideo{ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: 2024, FileId: VIDEO_FILE_ID})
+			repository.SaveBirthdayVideo(context.Background(), core.BirthdayVideo{ChatId: CHAT_ID_1, UserId: USER_ID_2, Year: 2024, FileId: VIDEO_FILE_ID})
+			repository.SaveBirthdayVideo(context.Background(), core.BirthdayVideo{ChatId: CHAT_ID_2, UserId: USER_ID_1, Year: 2023, FileId: VIDEO_FILE_ID})
+
+			sendMyVideos()
+
+			Expect(telegram.sentRepliesWithButtons).To(HaveExactElements(ReplyWithButtons{
+				chatId:    USER_ID_1,
+				messageId: MESSAGE_ID,
+				text:      fmt.Sprintf(core.MESSAGE_VIDEOS, "1. 🎂 Birthday 2024\n2. 🎂 Birthday 2023"),
+				buttons: []core.InlineButton{
+					{Text: "🎬 1", Data: "video:1"},
+					{Text: "🎬 2", Data: "video:3"},
+				},
+			}))
+		})
+
+		It("should reply when there are no videos", func() {
+			sendMyVideos()
+
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: USER_ID_1, text: core.MESSAGE_NO_VIDEOS}))
+			Expect(telegram.sentRepliesWithButtons).To(BeEmpty())
+		})
+
+		It("should reply with a failure message when fetching videos fails", func() {
+			repository.shouldFail = true
+
+			sendMyVideos()
+
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: USER_ID_1, text: core.MESSAGE_GET_FAILURE}))
+		})
+
+		It("should send the video again by its file id", func() {
+			repository.SaveBirthdayVideo(context.Background(), core.BirthdayVideo{ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: 2024, FileId: VIDEO_FILE_ID})
+
+			pressButton(USER_ID_1, "video:1")
+
+			Expect(telegram.sentVideos).To(HaveExactElements(Message{chatId: USER_ID_1, text: VIDEO_FILE_ID}))
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: ""}))
+		})
+
+		It("should not send someone else's video", func() {
+			repository.SaveBirthdayVideo(context.Background(), core.BirthdayVideo{ChatId: CHAT_ID_1, UserId: USER_ID_2, Year: 2024, FileId: VIDEO_FILE_ID})
+
+			pressButton(USER_ID_1, "video:1")
+
+			Expect(telegram.sentVideos).To(BeEmpty())
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: core.MESSAGE_VIDEO_GONE}))
+		})
+
+		It("should tell the user when sending the video fails", func() {
+			repository.SaveBirthdayVideo(context.Background(), core.BirthdayVideo{ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: 2024, FileId: VIDEO_FILE_ID})
+			telegram.shouldFailOnVideo = true
+
+			pressButton(USER_ID_1, "video:1")
+
+			Expect(telegram.callbackAnswers).To(HaveExactElements(CallbackAnswer{callbackId: CALLBACK_ID, text: core.MESSAGE_CALLBACK_FAILURE}))
+		})
+	})
+
+	Describe("delivery ledger", func() {
+		var date time.Time
+
+		BeforeEach(func() {
+			date = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+		})
+
+		It("should keep the time a delivery step was first completed", func() {
+			step := core.DeliveryStep{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: date, Kind: common.NOTIFICATION_KIND_BIRTHDAY, Step: common.DELIVERY_STEP_VIDEO}
+			clock.now = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+			bot.CompleteDeliveryStep(context.Background(), step)
+			clock.now = time.Date(2024, 1, 10, 8, 5, 0, 0, time.UTC)
+
+			err := bot.CompleteDeliveryStep(context.Background(), step)
+
+			Expect(err).To(BeNil())
+			step.CompletedAt = time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+			Expect(repository.deliverySteps).To(HaveExactElements(step))
+		})
+
+		It("should return delivery steps matching the query", func() {
+			bot.CompleteDeliveryStep(context.Background(), core.DeliveryStep{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: date, Kind: common.NOTIFICATION_KIND_BIRTHDAY, Step: common.DELIVERY_STEP_VIDEO})
+			bot.CompleteDeliveryStep(context.Background(), core.DeliveryStep{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: date, Kind: common.NOTIFICATION_KIND_BIRTHDAY, Step: common.DELIVERY_STEP_MESSAGE})
+			bot.CompleteDeliveryStep(context.Background(), core.DeliveryStep{ChatId: CHAT_ID_1, UserId: USER_ID_2, Date: date, Kind: common.NOTIFICATION_KIND_BIRTHDAY, Step: common.DELIVERY_STEP_VIDEO})
+			bot.CompleteDeliveryStep(context.Background(), core.DeliveryStep{ChatId: CHAT_ID_2, UserId: USER_ID_1, Date: date.AddDate(0, 0, -1), Kind: common.NOTIFICATION_KIND_BIRTHDAY, Step: common.DELIVERY_STEP_VIDEO})
+
+			allSteps, allStepsErr := bot.GetDeliverySteps(context.Background(), core.DeliveryQuery{Date: date})
+			userSteps, userStepsErr := bot.GetDeliverySteps(context.Background(), core.DeliveryQuery{Date: date, ChatId: CHAT_ID_1, UserId: USER_ID_1})
+
+			Expect(allStepsErr).To(BeNil())
+			Expect(allSteps).To(HaveLen(3))
+			Expect(userStepsErr).To(BeNil())
+			Expect(userSteps).To(HaveLen(2))
+			Expect(userSteps[0].Step).To(Equal(common.DELIVERY_STEP_VIDEO))
+			Expect(userSteps[1].Step).To(Equal(common.DELIVERY_STEP_MESSAGE))
+		})
+	})
+
+	Describe("scheduled dates", func() {
+		It("should return no date when nothing was scheduled yet", func() {
+			date, err := bot.GetLastScheduledDate(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(date.IsZero()).To(BeTrue())
+		})
+
+		It("should return the latest scheduled date", func() {
+			bot.SaveScheduledDate(context.Background(), time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+			bot.SaveScheduledDate(context.Background(), time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC))
+			bot.SaveScheduledDate(context.Background(), time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC))
+
+			date, err := bot.GetLastScheduledDate(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(date).To(Equal(time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Describe("custom pictures", func() {
+		sendCommand := func(chatId int64, chatType string, text string, photo []models.PhotoSize, replyTo *models.Message) {
+			message := &models.Message{
+				ID:             MESSAGE_ID,
+				From:           &models.User{ID: USER_ID_1},
+				Chat:           models.Chat{ID: chatId, Type: chatType},
+				ReplyToMessage: replyTo,
+			}
+			if len(photo) > 0 {
+				message.Photo = photo
+				message.Caption = text
+			} else {
+				message.Text = text
+			}
+			bot.HandleUpdate(context.Background(), &models.Update{Message: message})
+		}
+		photo := []models.PhotoSize{
+			{FileID: "small_file_id", FileUniqueID: "small_unique_id"},
+			{FileID: "large_file_id", FileUniqueID: "large_unique_id"},
+		}
+
+		It("should save the largest size of a photo sent with the command in a private chat for every chat", func() {
+			sendCommand(USER_ID_1, "private", "/setphoto", photo, nil)
+
+			Expect(repository.customPictures).To(HaveExactElements(core.CustomPicture{
+				UserId:       USER_ID_1,
+				ChatId:       core.CUSTOM_PICTURE_SCOPE_GLOBAL,
+				FileId:       "large_file_id",
+				FileUniqueId: "large_unique_id",
+			}))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{chatId: USER_ID_1, messageId: MESSAGE_ID, reaction: core.REACTION_THUMBS_UP}))
+		})
+
+		It("should save a photo the command replies to in a group only for that group", func() {
+			sendCommand(CHAT_ID_1, "supergroup", "/setphoto", nil, &models.Message{From: &models.User{ID: USER_ID_2}, Photo: photo})
+
+			Expect(repository.customPictures).To(HaveExactElements(core.CustomPicture{
+				UserId:       USER_ID_1,
+				ChatId:       CHAT_ID_1,
+				FileId:       "large_file_id",
+				FileUniqueId: "large_unique_id",
+			}))
+		})
+
+		It("should ask for a photo when there is none", func() {
+			sendCommand(CHAT_ID_1, "supergroup", "/setphoto", nil, nil)
+
+			Expect(repository.customPictures).To(BeEmpty())
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{chatId: CHAT_ID_1, messageId: MESSAGE_ID, text: core.MESSAGE_SET_PHOTO_WRONG_FORMAT}))
+		})
+
+		It("should prefer the picture of the chat over the one for every chat", func() {
+			repository.SaveCustomPicture(context.Background(), core.CustomPicture{UserId: USER_ID_1, ChatId: core.CUSTOM_PICTURE_SCOPE_GLOBAL, FileId: "global"})
+			repository.SaveCustomPicture(context.Background(), core.CustomPicture{UserId: USER_ID_1, ChatId: CHAT_ID_1, FileId: "chat"})
+
+			chatPicture, chatErr := bot.GetCustomPicture(context.Background(), CHAT_ID_1, USER_ID_1)
+			otherChatPicture, otherChatErr := bot.GetCustomPicture(context.Background(), CHAT_ID_2, USER_ID_1)
+			otherUserPicture, otherUserErr := bot.GetCustomPicture(context.Background(), CHAT_ID_1, USER_ID_2)
+
+			Expect(chatErr).To(BeNil())
+			Expect(chatPicture.FileId).To(Equal("chat"))
+			Expect(otherChatErr).To(BeNil())
+			Expect(otherChatPicture.FileId).To(Equal("global"))
+			Expect(otherUserErr).To(BeNil())
+			Expect(otherUserPicture).To(BeNil())
+		})
+
+		It("should reset only the picture of the chat the command was sent in", func() {
+			repository.SaveCustomPicture(context.Background(), core.CustomPicture{UserId: USER_ID_1, ChatId: core.CUSTOM_PICTURE_SCOPE_GLOBAL, FileId: "global"})
+			repository.SaveCustomPicture(context.Background(), core.CustomPicture{UserId: USER_ID_1, ChatId: CHAT_ID_1, FileId: "chat"})
+
+			sendCommand(CHAT_ID_1, "supergroup", "/resetphoto", nil, nil)
+
+			Expect(repository.customPictures).To(HaveExactElements(core.CustomPicture{UserId: USER_ID_1, ChatId: core.CUSTOM_PICTURE_SCOPE_GLOBAL, FileId: "global"}))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{chatId: CHAT_ID_1, messageId: MESSAGE_ID, reaction: core.REACTION_THUMBS_UP}))
+		})
+
+		It("should reply with a failure when saving fails", func() {
+			repository.shouldFail = true
+
+			sendCommand(USER_ID_1, "private", "/setphoto", photo, nil)
+
+			Expect(telegram.sentReplies).To(HaveExactElements(Reply{chatId: USER_ID_1, messageId: MESSAGE_ID, text: core.MESSAGE_SAVE_FAILURE}))
+		})
+	})
+
+	Describe("unavailable chats", func() {
+		It("should delete all data of the chat", func() {
+			err := bot.RemoveUnavailableChat(context.Background(), CHAT_ID_1)
+
+			Expect(err).To(BeNil())
+			Expect(repository.deletedGroupBirthdays).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupEvents).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupNameDays).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupWishlistItems).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupCustomPictures).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupWishes).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedGroupBirthdayVideos).To(HaveExactElements(CHAT_ID_1))
+			Expect(repository.deletedChatSettings).To(HaveExactElements(CHAT_ID_1))
+		})
+
+		It("should stop refreshing the board of the chat", func() {
+			repository.boardMessageIds = map[int64]int{CHAT_ID_1: SENT_MESSAGE_ID}
+
+			bot.RemoveUnavailableChat(context.Background(), CHAT_ID_1)
+			err := bot.RefreshBoards(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(telegram.editedMessages).To(BeEmpty())
+		})
+
+		It("should return an error when deleting fails", func() {
+			repository.shouldFail = true
+
+			err := bot.RemoveUnavailableChat(context.Background(), CHAT_ID_1)
+
+			Expect(err).To(Not(BeNil()))
+		})
+	})
+
+	Describe("notification jobs", func() {
+		const notification = `{"kind":"birthday"}`
+
+		BeforeEach(func() {
+			clock.now = time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+		})
+
+		It("should not queue the same task twice", func() {
+			bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "task", Notification: notification, RunAt: clock.now})
+
+			err := bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "task", Notification: notification, RunAt: clock.now})
+
+			Expect(err).To(BeNil())
+			Expect(repository.notificationJobs).To(HaveLen(1))
+		})
+
+		It("should remove finished jobs past their retention when queueing a job", func() {
+			err := bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "task", Notification: notification, RunAt: clock.now})
+
+			Expect(err).To(BeNil())
+			Expect(repository.deletedJobsFinishedBefore).To(HaveExactElements(clock.now.Add(-core.FINISHED_JOB_RETENTION)))
+		})
+
+		It("should claim only due jobs and only once", func() {
+			bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "due", Notification: notification, RunAt: clock.now})
+			bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "later", Notification: notification, RunAt: clock.now.Add(time.Minute)})
+
+			firstClaim, firstErr := bot.ClaimNotificationJobs(context.Background(), 10, time.Minute, MAX_JOB_ATTEMPTS)
+			secondClaim, secondErr := bot.ClaimNotificationJobs(context.Background(), 10, time.Minute, MAX_JOB_ATTEMPTS)
+
+			Expect(firstErr).To(BeNil())
+			Expect(firstClaim).To(HaveLen(1))
+			Expect(firstClaim[0].TaskId).To(Equal("due"))
+			Expect(firstClaim[0].Attempts).To(Equal(1))
+			Expect(secondErr).To(BeNil())
+			Expect(secondClaim).To(BeEmpty())
+		})
+
+		It("should retry a failed job and list it once it's dead", func() {
+			bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "task", Notification: notification, RunAt: clock.now})
+			jobs, _ := bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+			bot.FailNotificationJob(context.Background(), core.JobFailure{Id: jobs[0].Id, Attempts: jobs[0].Attempts, Error: "first", RetryAt: clock.now})
+			jobs, _ = bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+			bot.FailNotificationJob(context.Background(), core.JobFailure{Id: jobs[0].Id, Attempts: jobs[0].Attempts, Error: "second", Dead: true})
+
+			deadJobs, err := bot.GetNotificationJobs(context.Background(), core.JOB_STATUS_DEAD)
+
+			Expect(err).To(BeNil())
+			Expect(deadJobs).To(HaveLen(1))
+			Expect(deadJobs[0].Attempts).To(Equal(2))
+			Expect(deadJobs[0].LastError).To(Equal("second"))
+		})
+
+		It("should claim a job again when its claim expires", func() {
+			bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "task", Notification: notification, RunAt: clock.now})
+			bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+			clock.now = clock.now.Add(time.Minute)
+
+			jobs, err := bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+
+			Expect(err).To(BeNil())
+			Expect(jobs).To(HaveLen(1))
+			Expect(jobs[0].Attempts).To(Equal(2))
+		})
+
+		It("should mark a job as dead when its last allowed claim expires", func() {
+			bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "task", Notification: notification, RunAt: clock.now})
+			for range MAX_JOB_ATTEMPTS {
+				bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+				clock.now = clock.now.Add(time.Minute)
+			}
+
+			jobs, err := bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+
+			Expect(err).To(BeNil())
+			Expect(jobs).To(BeEmpty())
+			deadJobs, _ := bot.GetNotificationJobs(context.Background(), core.JOB_STATUS_DEAD)
+			Expect(deadJobs).To(HaveLen(1))
+			Expect(deadJobs[0].Attempts).To(Equal(MAX_JOB_ATTEMPTS))
+			Expect(deadJobs[0].LastError).To(Equal(core.JOB_LEASE_EXPIRED_ERROR))
+		})
+
+		It("should reject results from an expired claim", func() {
+			bot.QueueNotificationJob(context.Background(), core.NotificationJob{TaskId: "task", Notification: notification, RunAt: clock.now})
+			staleJobs, _ := bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+			clock.now = clock.now.Add(time.Minute)
+			bot.ClaimNotificationJobs(context.Background(), 1, time.Minute, MAX_JOB_ATTEMPTS)
+
+			completeErr := bot.CompleteNotificationJob(context.Background(), staleJobs[0].Id, staleJobs[0].Attempts)
+			failErr := bot.FailNotificationJob(context.Background(), core.JobFailure{Id: staleJobs[0].Id, Attempts: staleJobs[0].Attempts, Error: "stale", Dead: true})
+
+			Expect(completeErr).To(MatchError(core.ErrJobNotClaimed))
+			Expect(failErr).To(MatchError(core.ErrJobNotClaimed))
+			runningJobs, _ := bot.GetNotificationJobs(context.Background(), core.JOB_STATUS_RUNNING)
+			Expect(runningJobs).To(HaveLen(1))
+			Expect(runningJobs[0].Attempts).To(Equal(2))
+		})
+	})
+
+	Describe("rate limiting", func() {
+		sendNextBirthdayCommand := func(userId int64) {
+			bot.HandleUpdate(
+				context.Background(),
+				&models.Update{
+					Message: &models.Message{
+						ID: MESSAGE_ID,
+						From: &models.User{
+							ID: userId,
+						},
+						Chat: models.Chat{
+							ID:   CHAT_ID_1,
+							Type: "supergroup",
+						},
+						Text: "/nextbirthday",
+					},
+				},
+			)
+		}
+
+		It("should react once and then ignore commands over the limit", func() {
+			for range RATE_LIMIT_BURST + 3 {
+				sendNextBirthdayCommand(USER_ID_1)
+			}
+
+			Expect(repository.requestedNextBirthdayChatIds).To(HaveLen(RATE_LIMIT_BURST))
+			Expect(telegram.sentReplies).To(HaveLen(RATE_LIMIT_BURST))
+			Expect(telegram.sentReactions).To(HaveExactElements(Reaction{
+				chatId:    CHAT_ID_1,
+				messageId: MESSAGE_ID,
+				reaction:  core.REACTION_SLOW_DOWN,
+			}))
+		})
+
+		It("should limit each user separately", func() {
+			for range RATE_LIMIT_BURST + 1 {
+				sendNextBirthdayCommand(USER_ID_1)
+			}
+			sendNextBirthdayCommand(USER_ID_2)
+
+			Expect(repository.requestedNextBirthdayChatIds).To(HaveLen(RATE_LIMIT_BURST + 1))
+		})
+
+		It("should accept commands again after the window passes", func() {
+			for range RATE_LIMIT_BURST + 1 {
+				sendNextBirthdayCommand(USER_ID_1)
+			}
+			clock.now = NOW.Add(RATE_LIMIT_WINDOW)
+			sendNextBirthdayCommand(USER_ID_1)
+
+			Expect(repository.requestedNextBirthdayChatIds).To(HaveLen(RATE_LIMIT_BURST + 1))
+		})
+
+		It("should not count commands of other bots", func() {
+			for range RATE_LIMIT_BURST + 1 {
+				bot.HandleUpdate(
+					context.Background(),
+					&models.Update{
+						Message: &models.Message{
+							ID: MESSAGE_ID,
+							From: &models.User{
+								ID: USER_ID_1,
+							},
+							Chat: models.Chat{
+								ID:   CHAT_ID_1,
+								Type: "supergroup",
+							},
+							Text: "/start",
+						},
+					},
+				)
+			}
+			sendNextBirthdayCommand(USER_ID_1)
+
+			Expect(telegram.sentReactions).To(BeEmpty())
+			Expect(repository.requestedNextBirthdayChatIds).To(HaveLen(1))
+		})
+	})
+
+	Describe("getting birthday people", func() {
+		It("should return birthday people for a given date", func() {
+			_ = repository.SaveBirthday(context.Background(), core.Birthday{
+				ChatId:        CHAT_ID_1,
+				UserId:        USER_ID_1,
+				Date:          time.Date(DEFAULT_YEAR, 01, 31, 0, 0, 0, 0, time.UTC),
+				Username:      "hackergirl",
+				UserFirstName: "Iwakura",
+				UserLastName:  "Lain",
+			})
+			_ = repository.SaveBirthday(context.Background(), core.Birthday{
+				ChatId:   CHAT_ID_2,
+				UserId:   USER_ID_2,
+				Date:     time.Date(DEFAULT_YEAR, 01, 31, 0, 0, 0, 0, time.UTC),
+				Username: "mizukisan",
+			})
+
+			result, err := bot.GetBirthdays(context.Background(), NOW)
+
+			Expect(repository.requestedBirthdaysForDates).To(HaveExactElements(NOW))
+			Expect(err).To(BeNil())
+			Expect(result).To(ConsistOf(
+				core.BirthdayPerson{
+					ChatId:    CHAT_ID_1,
+					UserId:    USER_ID_1,
+					Name:      fmt.Sprintf("<a href=\"tg://user?id=%v\">Iwakura Lain</a>", USER_ID_1),
+					FirstName: "Iwakura",
+					LastName:  "Lain",
+					Date:      time.Date(DEFAULT_YEAR, 01, 31, 0, 0, 0, 0, time.UTC),
+				},
+				core.BirthdayPerson{
+					ChatId: CHAT_ID_2,
+					UserId: USER_ID_2,
+					Name:   "@mizukisan",
+					Date:   time.Date(DEFAULT_YEAR, 01, 31, 0, 0, 0, 0, time.UTC),
+				},
+			))
+		})
+
+		It("should return empty list when there are no birthdays", func() {
+			result, err := bot.GetBirthdays(context.Background(), NOW)
+
+			Expect(repository.requestedBirthdaysForDates).To(HaveExactElements(NOW))
+			Expect(err).To(BeNil())
+			Expect(result).To(BeEmpty())
+		})
+
+		It("should pass error from repository", func() {
+			repository.shouldFail = true
+
+			result, err := bot.GetBirthdays(context.Background(), NOW)
+
+			Expect(err).To(Not(BeNil()))
+			Expect(result).To(BeNil())
+		})
+	})
+})
+
+// ===== FAKES =====
+
+type DeletedBirthday struct {
+	chatId int64
+	userId int64
+}
+
+type RequestedBirthday struct {
+	chatId int64
+	userId int64
+}
+
+type FakeRepository struct {
+	savedBirthdays               []core.Birthday
+	deletedBirthdays             []DeletedBirthday
+	deletedGroupBirthdays        []int64
+	deletedGroupEvents           []int64
+	deletedGroupNameDays         []int64
+	deletedUserNameDays          []int64
+	deletedNameDays              []DeletedBirthday
+	nameDayCountries             map[int64]string
+	savedNameDays                []core.NameDay
+	nameDays                     []core.NameDay
+	requestedNameDays            []RequestedNameDays
+	savedEvents                  []core.Event
+	leapDayEvents                map[core.LeapDayPolicy][]core.Event
+	requestedEventChatIds        []int64
+	requestedEventDates          []time.Time
+	deletedEvents                []DeletedEvent
+	deletedUserBirthdays         []int64
+	requestedBirthdays           []RequestedBirthday
+	requestedNextBirthdayChatIds []int64
+	requestedBirthdaysForDates   []time.Time
+	requestedDayCountChatIds     []int64
+	dayCounts                    []core.BirthdayDayCount
+	requestedBirthdayRanges      []BirthdayRange
+	boardMessageIds              map[int64]int
+	deletedChatSettings          []int64
+	digestSettings               map[int64]bool
+	requestedDigestRanges        []BirthdayRange
+	leapDayPolicies              map[int64]core.LeapDayPolicy
+	leapDayBirthdays             map[core.LeapDayPolicy][]core.Birthday
+	requestedLeapDayPolicies     []core.LeapDayPolicy
+	savedWishContributors        []int64
+	wishContributors             map[int64][]int64
+	pendingWishes                []core.PendingWish
+	savedWishes                  []core.Wish
+	requestedWishes              []RequestedWishes
+	deletedWishesBefore          []time.Time
+	deletedUserWishes            []int64
+	deletedGroupWishes           []int64
+	wishlistItems                []core.WishlistItem
+	deletedWishlistItems         []DeletedBirthday
+	deletedGroupWishlistItems    []int64
+	deletedUserWishlistItems     []int64
+	birthdayVideos               []core.BirthdayVideo
+	deletedUserBirthdayVideos    []int64
+	deletedGroupBirthdayVideos   []int64
+	cachedVideos                 []core.CachedVideo
+	customPictures               []core.CustomPicture
+	deletedGroupCustomPictures   []int64
+	deletedUserCustomPictures    []int64
+	deliverySteps                []core.DeliveryStep
+	deletedUserDeliverySteps     []int64
+	scheduledDates               []time.Time
+	notificationJobs             []core.NotificationJob
+	jobLocks                     map[int64]time.Time
+	deletedJobsFinishedBefore    []time.Time
+	shouldFail                   bool
+	shouldFailOnWishes           bool
+	shouldFailOnLeapDayPolicy    bool
+	shouldFailOnLeapDayBirthdays bool
+}
+
+type BirthdayRange struct {
+	chatId int64
+	from   time.Time
+	to     time.Time
+}
 
 func (repository *FakeRepository) SaveBirthday(_ context.Context, birthday core.Birthday) error {
 	if repository.shouldFail {
 		return errors.New("test")
 	}
-	repository.savedBirthdays = append(repository.savedBirthdays, birthday)
+	repository.savedBirthdays = append(repository.savedBirthdays, birthday)
+	return nil
+}
+
+func (repository *FakeRepository) GetBirthday(ctx context.Context, chatId int64, userId int64) (*core.Birthday, error) {
+	repository.requestedBirthdays = append(
+		repository.requestedBirthdays,
+		RequestedBirthday{chatId: chatId, userId: userId},
+	)
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	if len(repository.savedBirthdays) == 0 {
+		return nil, nil
+	}
+	return &repository.savedBirthdays[0], nil
+}
+
+func (repository *FakeRepository) GetNextBirthdays(ctx context.Context, chatId int64) ([]core.Birthday, error) {
+	repository.requestedNextBirthdayChatIds = append(
+		repository.requestedNextBirthdayChatIds,
+		chatId,
+	)
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.savedBirthdays, nil
+}
+
+func (repository *FakeRepository) GetBirthdaysForDate(ctx context.Context, date time.Time) ([]core.Birthday, error) {
+	repository.requestedBirthdaysForDates = append(repository.requestedBirthdaysForDates, date)
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.savedBirthdays, nil
+}
+
+func (repository *FakeRepository) GetBirthdayCountsPerDay(ctx context.Context, chatId int64) ([]core.BirthdayDayCount, error) {
+	repository.requestedDayCountChatIds = append(repository.requestedDayCountChatIds, chatId)
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.dayCounts, nil
+}
+
+func (repository *FakeRepository) GetChatBirthdaysBetween(ctx context.Context, chatId int64, from time.Time, to time.Time) ([]core.Birthday, error) {
+	repository.requestedBirthdayRanges = append(repository.requestedBirthdayRanges, BirthdayRange{chatId: chatId, from: from, to: to})
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var birthdays []core.Birthday
+	for _, birthday := range repository.savedBirthdays {
+		if !birthday.Date.Before(from) && !birthday.Date.After(to) {
+			birthdays = append(birthdays, birthday)
+		}
+	}
+	return birthdays, nil
+}
+
+func (repository *FakeRepository) SaveBoardMessageId(ctx context.Context, chatId int64, messageId int) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	if repository.boardMessageIds == nil {
+		repository.boardMessageIds = make(map[int64]int)
+	}
+	repository.boardMessageIds[chatId] = messageId
+	return nil
+}
+
+func (repository *FakeRepository) GetBoardMessageId(ctx context.Context, chatId int64) (int, error) {
+	if repository.shouldFail {
+		return 0, errors.New("test")
+	}
+	return repository.boardMessageIds[chatId], nil
+}
+
+func (repository *FakeRepository) GetChatIdsWithBoards(ctx context.Context) ([]int64, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var chatIds []int64
+	for chatId, messageId := range repository.boardMessageIds {
+		if messageId != core.NO_BOARD_MESSAGE_ID {
+			chatIds = append(chatIds, chatId)
+		}
+	}
+	return chatIds, nil
+}
+
+func (repository *FakeRepository) DeleteChatSettings(_ context.Context, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedChatSettings = append(repository.deletedChatSettings, chatId)
+	delete(repository.boardMessageIds, chatId)
+	delete(repository.digestSettings, chatId)
+	delete(repository.leapDayPolicies, chatId)
+	delete(repository.nameDayCountries, chatId)
+	return nil
+}
+
+func (repository *FakeRepository) SetDigestEnabled(ctx context.Context, chatId int64, enabled bool) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	if repository.digestSettings == nil {
+		repository.digestSettings = make(map[int64]bool)
+	}
+	repository.digestSettings[chatId] = enabled
+	return nil
+}
+
+func (repository *FakeRepository) GetDigestBirthdaysBetween(ctx context.Context, from time.Time, to time.Time) ([]core.Birthday, error) {
+	repository.requestedDigestRanges = append(repository.requestedDigestRanges, BirthdayRange{from: from, to: to})
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.savedBirthdays, nil
+}
+
+func (repository *FakeRepository) SetLeapDayPolicy(ctx context.Context, chatId int64, policy core.LeapDayPolicy) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	if repository.leapDayPolicies == nil {
+		repository.leapDayPolicies = make(map[int64]core.LeapDayPolicy)
+	}
+	repository.leapDayPolicies[chatId] = policy
+	return nil
+}
+
+func (repository *FakeRepository) GetLeapDayPolicy(ctx context.Context, chatId int64) (core.LeapDayPolicy, error) {
+	if repository.shouldFailOnLeapDayPolicy {
+		return "", errors.New("test")
+	}
+	if policy, exists := repository.leapDayPolicies[chatId]; exists {
+		return policy, nil
+	}
+	return core.DEFAULT_LEAP_DAY_POLICY, nil
+}
+
+func (repository *FakeRepository) GetLeapDayBirthdays(ctx context.Context, policy core.LeapDayPolicy) ([]core.Birthday, error) {
+	repository.requestedLeapDayPolicies = append(repository.requestedLeapDayPolicies, policy)
+	if repository.shouldFailOnLeapDayBirthdays {
+		return nil, errors.New("test")
+	}
+	return repository.leapDayBirthdays[policy], nil
+}
+
+func (repository *FakeRepository) DeleteBirthday(_ context.Context, chatId int64, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedBirthdays = append(repository.deletedBirthdays, DeletedBirthday{chatId: chatId, userId: userId})
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllChatBirthdays(_ context.Context, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedGroupBirthdays = append(repository.deletedGroupBirthdays, chatId)
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllUserBirthdays(_ context.Context, userId int64) ([]int64, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	repository.deletedUserBirthdays = append(repository.deletedUserBirthdays, userId)
+	var chatIds []int64
+	for _, birthday := range repository.savedBirthdays {
+		if birthday.UserId == userId {
+			chatIds = append(chatIds, birthday.ChatId)
+		}
+	}
+	return chatIds, nil
+}
+
+func (repository *FakeRepository) SaveEvent(_ context.Context, event core.Event) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.savedEvents = append(repository.savedEvents, event)
+	return nil
+}
+
+func (repository *FakeRepository) GetChatEvents(_ context.Context, chatId int64) ([]core.Event, error) {
+	repository.requestedEventChatIds = append(repository.requestedEventChatIds, chatId)
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.savedEvents, nil
+}
+
+func (repository *FakeRepository) GetEventsForDate(_ context.Context, date time.Time) ([]core.Event, error) {
+	repository.requestedEventDates = append(repository.requestedEventDates, date)
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.savedEvents, nil
+}
+
+func (repository *FakeRepository) GetLeapDayEvents(_ context.Context, policy core.LeapDayPolicy) ([]core.Event, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.leapDayEvents[policy], nil
+}
+
+func (repository *FakeRepository) DeleteEvent(_ context.Context, chatId int64, name string) (bool, error) {
+	if repository.shouldFail {
+		return false, errors.New("test")
+	}
+	for _, event := range repository.savedEvents {
+		if event.ChatId == chatId && strings.EqualFold(event.Name, name) {
+			repository.deletedEvents = append(repository.deletedEvents, DeletedEvent{chatId: chatId, name: name})
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repository *FakeRepository) DeleteAllChatEvents(_ context.Context, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedGroupEvents = append(repository.deletedGroupEvents, chatId)
+	return nil
+}
+
+func (repository *FakeRepository) SetNameDayCountry(_ context.Context, chatId int64, country string) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	if repository.nameDayCountries == nil {
+		repository.nameDayCountries = make(map[int64]string)
+	}
+	repository.nameDayCountries[chatId] = country
+	return nil
+}
+
+func (repository *FakeRepository) GetNameDayCountry(_ context.Context, chatId int64) (string, error) {
+	if repository.shouldFail {
+		return "", errors.New("test")
+	}
+	return repository.nameDayCountries[chatId], nil
+}
+
+func (repository *FakeRepository) SaveNameDay(_ context.Context, nameDay core.NameDay) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.savedNameDays = append(repository.savedNameDays, nameDay)
+	return nil
+}
+
+func (repository *FakeRepository) GetNameDaysForNames(_ context.Context, country string, names []string) ([]core.NameDay, error) {
+	repository.requestedNameDays = append(repository.requestedNameDays, RequestedNameDays{country: country, names: names})
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var nameDays []core.NameDay
+	if country == "pl" {
+		nameDays = repository.nameDays
+	}
+	return nameDays, nil
+}
+
+func (repository *FakeRepository) DeleteNameDay(_ context.Context, chatId int64, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedNameDays = append(repository.deletedNameDays, DeletedBirthday{chatId: chatId, userId: userId})
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllChatNameDays(_ context.Context, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedGroupNameDays = append(repository.deletedGroupNameDays, chatId)
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllUserNameDays(_ context.Context, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedUserNameDays = append(repository.deletedUserNameDays, userId)
+	return nil
+}
+
+func (repository *FakeRepository) SaveWishContributor(_ context.Context, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.savedWishContributors = append(repository.savedWishContributors, userId)
+	return nil
+}
+
+func (repository *FakeRepository) GetWishContributors(_ context.Context, chatId int64) ([]int64, error) {
+	if repository.shouldFail || repository.shouldFailOnWishes {
+		return nil, errors.New("test")
+	}
+	return repository.wishContributors[chatId], nil
+}
+
+func (repository *FakeRepository) SavePendingWish(_ context.Context, pendingWish core.PendingWish) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.pendingWishes = append(repository.pendingWishes, pendingWish)
+	return nil
+}
+
+func (repository *FakeRepository) GetPendingWishes(_ context.Context, authorUserId int64, from time.Time) ([]core.PendingWish, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var pendingWishes []core.PendingWish
+	for _, pendingWish := range repository.pendingWishes {
+		if pendingWish.AuthorUserId == authorUserId && !pendingWish.Date.Before(from) {
+			pendingWishes = append(pendingWishes, pendingWish)
+		}
+	}
+	return pendingWishes, nil
+}
+
+func (repository *FakeRepository) DeletePendingWish(_ context.Context, pendingWish core.PendingWish) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.pendingWishes = slices.DeleteFunc(repository.pendingWishes, func(other core.PendingWish) bool {
+		return other == pendingWish
+	})
+	return nil
+}
+
+func (repository *FakeRepository) SaveWish(_ context.Context, wish core.Wish) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.savedWishes = append(repository.savedWishes, wish)
+	return nil
+}
+
+func (repository *FakeRepository) GetWishes(_ context.Context, chatId int64, birthdayUserId int64, date time.Time) ([]core.Wish, error) {
+	repository.requestedWishes = append(repository.requestedWishes, RequestedWishes{chatId: chatId, userId: birthdayUserId, date: date})
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	return repository.savedWishes, nil
+}
+
+func (repository *FakeRepository) DeleteWishesBefore(_ context.Context, date time.Time) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedWishesBefore = append(repository.deletedWishesBefore, date)
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllChatWishes(_ context.Context, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedGroupWishes = append(repository.deletedGroupWishes, chatId)
+	repository.savedWishes = slices.DeleteFunc(repository.savedWishes, func(wish core.Wish) bool {
+		return wish.ChatId == chatId
+	})
+	repository.pendingWishes = slices.DeleteFunc(repository.pendingWishes, func(pendingWish core.PendingWish) bool {
+		return pendingWish.ChatId == chatId
+	})
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllUserWishes(_ context.Context, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedUserWishes = append(repository.deletedUserWishes, userId)
+	return nil
+}
+
+func (repository *FakeRepository) SaveWishlistItem(_ context.Context, item core.WishlistItem) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	item.Id = int64(len(repository.wishlistItems) + 1)
+	if len(repository.wishlistItems) > 0 {
+		item.Id = repository.wishlistItems[len(repository.wishlistItems)-1].Id + 1
+	}
+	repository.wishlistItems = append(repository.wishlistItems, item)
 	return nil
 }
 
-func (repository *FakeRepository) GetBirthdayDate(ctx context.Context, chatId int64, userId int64) (*time.Time, error) {
-	repository.requestedBirthdays = append(
-		repository.requestedBirthdays,
-		RequestedBirthday{chatId: chatId, userId: userId},
-	)
+func (repository *FakeRepository) GetWishlistItems(_ context.Context, userId int64, chatId int64) ([]core.WishlistItem, error) {
 	if repository.shouldFail {
 		return nil, errors.New("test")
 	}
-	if len(repository.savedBirthdays) == 0 {
-		return nil, nil
+	var items []core.WishlistItem
+	for _, item := range repository.wishlistItems {
+		if item.UserId == userId && (item.ChatId == chatId || item.ChatId == core.WISHLIST_SCOPE_GLOBAL) {
+			items = append(items, item)
+		}
 	}
-	return &repository.savedBirthdays[0].Date, nil
+	return items, nil
 }
 
-func (repository *FakeRepository) GetNextBirthdays(ctx context.Context, chatId int64) ([]core.Birthday, error) {
-	repository.requestedNextBirthdayChatIds = append(
-		repository.requestedNextBirthdayChatIds,
-		chatId,
-	)
+func (repository *FakeRepository) GetAllUserWishlistItems(_ context.Context, userId int64) ([]core.WishlistItem, error) {
 	if repository.shouldFail {
 		return nil, errors.New("test")
 	}
-	return repository.savedBirthdays, nil
+	var items []core.WishlistItem
+	for _, item := range repository.wishlistItems {
+		if item.UserId == userId {
+			items = append(items, item)
+		}
+	}
+	return items, nil
 }
 
-func (repository *FakeRepository) GetBirthdaysForDate(ctx context.Context, date time.Time) ([]core.Birthday, error) {
-	repository.requestedBirthdaysForDates = append(repository.requestedBirthdaysForDates, date)
+func (repository *FakeRepository) GetWishlistItem(_ context.Context, itemId int64) (*core.WishlistItem, error) {
 	if repository.shouldFail {
 		return nil, errors.New("test")
 	}
-	return repository.savedBirthdays, nil
+	for _, item := range repository.wishlistItems {
+		if item.Id == itemId {
+			return &item, nil
+		}
+	}
+	return nil, nil
 }
 
-func (repository *FakeRepository) DeleteBirthday(_ context.Context, chatId int64, userId int64) error {
+func (repository *FakeRepository) UpdateWishlistItemClaim(_ context.Context, itemId int64, expectedClaimedBy int64, claimedBy int64) (bool, error) {
+	if repository.shouldFail {
+		return false, errors.New("test")
+	}
+	for index, item := range repository.wishlistItems {
+		if item.Id == itemId && item.ClaimedBy == expectedClaimedBy {
+			repository.wishlistItems[index].ClaimedBy = claimedBy
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repository *FakeRepository) DeleteWishlistItem(_ context.Context, itemId int64) error {
 	if repository.shouldFail {
 		return errors.New("test")
 	}
-	repository.deletedBirthdays = append(repository.deletedBirthdays, DeletedBirthday{chatId: chatId, userId: userId})
+	repository.wishlistItems = slices.DeleteFunc(repository.wishlistItems, func(item core.WishlistItem) bool {
+		return item.Id == itemId
+	})
 	return nil
 }
 
-func (repository *FakeRepository) DeleteAllChatBirthdays(_ context.Context, chatId int64) error {
+func (repository *FakeRepository) DeleteChatUserWishlistItems(_ context.Context, chatId int64, userId int64) error {
 	if repository.shouldFail {
 		return errors.New("test")
 	}
-	repository.deletedGroupBirthdays = append(repository.deletedGroupBirthdays, chatId)
+	repository.deletedWishlistItems = append(repository.deletedWishlistItems, DeletedBirthday{chatId: chatId, userId: userId})
 	return nil
 }
 
-func (repository *FakeRepository) DeleteAllUserBirthdays(_ context.Context, userId int64) error {
+func (repository *FakeRepository) DeleteAllChatWishlistItems(_ context.Context, chatId int64) error {
 	if repository.shouldFail {
 		return errors.New("test")
 	}
-	repository.deletedUserBirthdays = append(repository.deletedUserBirthdays, userId)
+	repository.deletedGroupWishlistItems = append(repository.deletedGroupWishlistItems, chatId)
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllUserWishlistItems(_ context.Context, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedUserWishlistItems = append(repository.deletedUserWishlistItems, userId)
+	return nil
+}
+
+func (repository *FakeRepository) SaveBirthdayVideo(_ context.Context, video core.BirthdayVideo) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	video.Id = int64(len(repository.birthdayVideos) + 1)
+	repository.birthdayVideos = append(repository.birthdayVideos, video)
+	return nil
+}
+
+func (repository *FakeRepository) GetUserBirthdayVideos(_ context.Context, userId int64) ([]core.BirthdayVideo, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var videos []core.BirthdayVideo
+	for _, video := range repository.birthdayVideos {
+		if video.UserId == userId {
+			videos = append(videos, video)
+		}
+	}
+	return videos, nil
+}
+
+func (repository *FakeRepository) GetBirthdayVideo(_ context.Context, videoId int64) (*core.BirthdayVideo, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	for _, video := range repository.birthdayVideos {
+		if video.Id == videoId {
+			return &video, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repository *FakeRepository) SaveCachedVideo(_ context.Context, cachedVideo core.CachedVideo) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.cachedVideos = slices.DeleteFunc(repository.cachedVideos, func(other core.CachedVideo) bool {
+		return other.UserId == cachedVideo.UserId && other.PictureUniqueId == cachedVideo.PictureUniqueId
+	})
+	repository.cachedVideos = append(repository.cachedVideos, cachedVideo)
+	return nil
+}
+
+func (repository *FakeRepository) GetCachedVideoFileId(_ context.Context, userId int64, pictureUniqueId string) (string, error) {
+	if repository.shouldFail {
+		return "", errors.New("test")
+	}
+	for _, cachedVideo := range repository.cachedVideos {
+		if cachedVideo.UserId == userId && cachedVideo.PictureUniqueId == pictureUniqueId {
+			return cachedVideo.FileId, nil
+		}
+	}
+	return "", nil
+}
+
+func (repository *FakeRepository) DeleteAllChatBirthdayVideos(_ context.Context, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedGroupBirthdayVideos = append(repository.deletedGroupBirthdayVideos, chatId)
+	repository.birthdayVideos = slices.DeleteFunc(repository.birthdayVideos, func(video core.BirthdayVideo) bool {
+		return video.ChatId == chatId
+	})
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllUserBirthdayVideos(_ context.Context, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedUserBirthdayVideos = append(repository.deletedUserBirthdayVideos, userId)
+	return nil
+}
+
+func (repository *FakeRepository) SaveCustomPicture(ctx context.Context, picture core.CustomPicture) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.DeleteCustomPicture(ctx, picture.UserId, picture.ChatId)
+	repository.customPictures = append(repository.customPictures, picture)
+	return nil
+}
+
+func (repository *FakeRepository) GetCustomPicture(_ context.Context, userId int64, chatId int64) (*core.CustomPicture, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var globalPicture *core.CustomPicture
+	for index, picture := range repository.customPictures {
+		if picture.UserId != userId {
+			continue
+		}
+		if picture.ChatId == chatId {
+			return &repository.customPictures[index], nil
+		}
+		if picture.ChatId == core.CUSTOM_PICTURE_SCOPE_GLOBAL {
+			globalPicture = &repository.customPictures[index]
+		}
+	}
+	return globalPicture, nil
+}
+
+func (repository *FakeRepository) DeleteCustomPicture(_ context.Context, userId int64, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.customPictures = slices.DeleteFunc(repository.customPictures, func(picture core.CustomPicture) bool {
+		return picture.UserId == userId && picture.ChatId == chatId
+	})
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllChatCustomPictures(_ context.Context, chatId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedGroupCustomPictures = append(repository.deletedGroupCustomPictures, chatId)
+	return nil
+}
+
+func (repository *FakeRepository) DeleteAllUserCustomPictures(_ context.Context, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedUserCustomPictures = append(repository.deletedUserCustomPictures, userId)
+	return nil
+}
+
+func (repository *FakeRepository) SaveDeliveryStep(_ context.Context, step core.DeliveryStep) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	for _, savedStep := range repository.deliverySteps {
+		if savedStep.ChatId == step.ChatId && savedStep.UserId == step.UserId && savedStep.Date.Equal(step.Date) &&
+			savedStep.Kind == step.Kind && savedStep.Step == step.Step {
+			return nil
+		}
+	}
+	repository.deliverySteps = append(repository.deliverySteps, step)
+	return nil
+}
+
+func (repository *FakeRepository) GetDeliverySteps(_ context.Context, query core.DeliveryQuery) ([]core.DeliveryStep, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var steps []core.DeliveryStep
+	for _, step := range repository.deliverySteps {
+		if step.Date.Equal(query.Date) &&
+			(query.ChatId == 0 || step.ChatId == query.ChatId) &&
+			(query.UserId == 0 || step.UserId == query.UserId) &&
+			(query.Kind == "" || step.Kind == query.Kind) {
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+func (repository *FakeRepository) DeleteAllUserDeliverySteps(_ context.Context, userId int64) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedUserDeliverySteps = append(repository.deletedUserDeliverySteps, userId)
+	return nil
+}
+
+func (repository *FakeRepository) GetLastScheduledDate(_ context.Context) (time.Time, error) {
+	if repository.shouldFail {
+		return time.Time{}, errors.New("test")
+	}
+	var lastDate time.Time
+	for _, date := range repository.scheduledDates {
+		if date.After(lastDate) {
+			lastDate = date
+		}
+	}
+	return lastDate, nil
+}
+
+func (repository *FakeRepository) SaveScheduledDate(_ context.Context, date time.Time) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.scheduledDates = append(repository.scheduledDates, date)
+	return nil
+}
+
+func (repository *FakeRepository) SaveNotificationJob(_ context.Context, job core.NotificationJob) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	for _, savedJob := range repository.notificationJobs {
+		if savedJob.TaskId == job.TaskId {
+			return nil
+		}
+	}
+	job.Id = int64(len(repository.notificationJobs) + 1)
+	job.Status = core.JOB_STATUS_PENDING
+	repository.notificationJobs = append(repository.notificationJobs, job)
+	return nil
+}
+
+func (repository *FakeRepository) ClaimNotificationJobs(_ context.Context, now time.Time, lockedUntil time.Time, limit int, maxAttempts int) ([]core.NotificationJob, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	if repository.jobLocks == nil {
+		repository.jobLocks = make(map[int64]time.Time)
+	}
+	var claimedJobs []core.NotificationJob
+	for index, job := range repository.notificationJobs {
+		lockExpired := job.Status == core.JOB_STATUS_RUNNING && !repository.jobLocks[job.Id].After(now)
+		if lockExpired && job.Attempts >= maxAttempts {
+			repository.notificationJobs[index].Status = core.JOB_STATUS_DEAD
+			repository.notificationJobs[index].LastError = core.JOB_LEASE_EXPIRED_ERROR
+			continue
+		}
+		isDue := job.Status == core.JOB_STATUS_PENDING && !job.RunAt.After(now)
+		if len(claimedJobs) < limit && (isDue || lockExpired) {
+			repository.notificationJobs[index].Status = core.JOB_STATUS_RUNNING
+			repository.notificationJobs[index].Attempts++
+			repository.jobLocks[job.Id] = lockedUntil
+			claimedJobs = append(claimedJobs, repository.notificationJobs[index])
+		}
+	}
+	return claimedJobs, nil
+}
+
+func (repository *FakeRepository) CompleteNotificationJob(_ context.Context, jobId int64, attempts int) (bool, error) {
+	if repository.shouldFail {
+		return false, errors.New("test")
+	}
+	job := &repository.notificationJobs[jobId-1]
+	if job.Status != core.JOB_STATUS_RUNNING || job.Attempts != attempts {
+		return false, nil
+	}
+	job.Status = core.JOB_STATUS_DONE
+	return true, nil
+}
+
+func (repository *FakeRepository) FailNotificationJob(_ context.Context, failure core.JobFailure) (bool, error) {
+	if repository.shouldFail {
+		return false, errors.New("test")
+	}
+	job := &repository.notificationJobs[failure.Id-1]
+	if job.Status != core.JOB_STATUS_RUNNING || job.Attempts != failure.Attempts {
+		return false, nil
+	}
+	job.LastError = failure.Error
+	if failure.Dead {
+		job.Status = core.JOB_STATUS_DEAD
+	} else {
+		job.Status = core.JOB_STATUS_PENDING
+		job.RunAt = failure.RetryAt
+	}
+	return true, nil
+}
+
+func (repository *FakeRepository) GetNotificationJobs(_ context.Context, status core.JobStatus) ([]core.NotificationJob, error) {
+	if repository.shouldFail {
+		return nil, errors.New("test")
+	}
+	var jobs []core.NotificationJob
+	for _, job := range repository.notificationJobs {
+		if job.Status == status {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (repository *FakeRepository) DeleteNotificationJobsFinishedBefore(_ context.Context, date time.Time) error {
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.deletedJobsFinishedBefore = append(repository.deletedJobsFinishedBefore, date)
 	return nil
 }
 
+type RequestedWishes struct {
+	chatId int64
+	userId int64
+	date   time.Time
+}
+
+type RequestedNameDays struct {
+	country string
+	names   []string
+}
+
+type DeletedEvent struct {
+	chatId int64
+	name   string
+}
+
 type Message struct {
 	chatId int64
 	text   string
@@ -1210,10 +4289,40 @@ type Reaction struct {
 	reaction  string
 }
 
+type AdminCheck struct {
+	chatId int64
+	userId int64
+}
+
+type ReplyWithButtons struct {
+	chatId    int64
+	messageId int
+	text      string
+	buttons   []core.InlineButton
+}
+
+type CallbackAnswer struct {
+	callbackId string
+	text       string
+}
+
 type FakeTelegram struct {
-	sentMessages  []Message
-	sentReplies   []Reply
-	sentReactions []Reaction
+	sentMessages              []Message
+	sentReplies               []Reply
+	sentRepliesWithButtons    []ReplyWithButtons
+	editedMessagesWithButtons []ReplyWithButtons
+	callbackAnswers           []CallbackAnswer
+	sentReactions             []Reaction
+	sentVideos                []Message
+	shouldFailOnVideo         bool
+	editedMessages            []Reply
+	pinnedMessages            []Reaction
+	unpinnedMessages          []Reaction
+	adminIds                  []int64
+	adminChecks               []AdminCheck
+	shouldFailOnAdminCheck    bool
+	shouldFailOnPin           bool
+	editError                 error
 }
 
 func (fake *FakeTelegram) SendReply(ctx context.Context, chatId int64, messageId int, text string) error {
@@ -1225,6 +4334,31 @@ func (fake *FakeTelegram) SendReply(ctx context.Context, chatId int64, messageId
 	return nil
 }
 
+func (fake *FakeTelegram) SendReplyWithButtons(ctx context.Context, chatId int64, messageId int, text string, buttons []core.InlineButton) error {
+	fake.sentRepliesWithButtons = append(fake.sentRepliesWithButtons, ReplyWithButtons{
+		chatId:    chatId,
+		messageId: messageId,
+		text:      text,
+		buttons:   buttons,
+	})
+	return nil
+}
+
+func (fake *FakeTelegram) EditMessageWithButtons(ctx context.Context, chatId int64, messageId int, text string, buttons []core.InlineButton) error {
+	fake.editedMessagesWithButtons = append(fake.editedMessagesWithButtons, ReplyWithButtons{
+		chatId:    chatId,
+		messageId: messageId,
+		text:      text,
+		buttons:   buttons,
+	})
+	return nil
+}
+
+func (fake *FakeTelegram) AnswerCallback(ctx context.Context, callbackId string, text string) error {
+	fake.callbackAnswers = append(fake.callbackAnswers, CallbackAnswer{callbackId: callbackId, text: text})
+	return nil
+}
+
 func (fake *FakeTelegram) SendMessage(ctx context.Context, chatId int64, text string) error {
 	fake.sentMessages = append(fake.sentMessages, Message{
 		chatId: chatId,
@@ -1233,6 +4367,14 @@ func (fake *FakeTelegram) SendMessage(ctx context.Context, chatId int64, text st
 	return nil
 }
 
+func (fake *FakeTelegram) SendVideoFromFileId(ctx context.Context, chatId int64, fileId string) error {
+	if fake.shouldFailOnVideo {
+		return errors.New("test")
+	}
+	fake.sentVideos = append(fake.sentVideos, Message{chatId: chatId, text: fileId})
+	return nil
+}
+
 func (fake *FakeTelegram) SendReaction(ctx context.Context, chatId int64, messageId int, reaction string) error {
 	fake.sentReactions = append(fake.sentReactions, Reaction{
 		chatId:    chatId,
@@ -1242,20 +4384,83 @@ func (fake *FakeTelegram) SendReaction(ctx context.Context, chatId int64, messag
 	return nil
 }
 
+func (fake *FakeTelegram) SendMessageAndGetId(ctx context.Context, chatId int64, text string) (int, error) {
+	fake.sentMessages = append(fake.sentMessages, Message{
+		chatId: chatId,
+		text:   text,
+	})
+	return SENT_MESSAGE_ID, nil
+}
+
+func (fake *FakeTelegram) EditMessage(ctx context.Context, chatId int64, messageId int, text string) error {
+	if fake.editError != nil {
+		return fake.editError
+	}
+	fake.editedMessages = append(fake.editedMessages, Reply{
+		chatId:    chatId,
+		messageId: messageId,
+		text:      text,
+	})
+	return nil
+}
+
+func (fake *FakeTelegram) PinMessage(ctx context.Context, chatId int64, messageId int) error {
+	if fake.shouldFailOnPin {
+		return errors.New("test")
+	}
+	fake.pinnedMessages = append(fake.pinnedMessages, Reaction{
+		chatId:    chatId,
+		messageId: messageId,
+	})
+	return nil
+}
+
+func (fake *FakeTelegram) UnpinMessage(ctx context.Context, chatId int64, messageId int) error {
+	fake.unpinnedMessages = append(fake.unpinnedMessages, Reaction{
+		chatId:    chatId,
+		messageId: messageId,
+	})
+	return nil
+}
+
+func (fake *FakeTelegram) IsChatAdmin(ctx context.Context, chatId int64, userId int64) (bool, error) {
+	fake.adminChecks = append(fake.adminChecks, AdminCheck{chatId: chatId, userId: userId})
+	if fake.shouldFailOnAdminCheck {
+		return false, errors.New("test")
+	}
+	return slices.Contains(fake.adminIds, userId), nil
+}
+
+type FakeClock struct {
+	now time.Time
+}
+
+func (clock *FakeClock) Now() time.Time {
+	return clock.now
+}
+
 // ===== TEST DATA =====
 const (
-	MESSAGE_ID   int   = 101
-	CHAT_ID_1    int64 = 981
-	CHAT_ID_2    int64 = 781
-	USER_ID_1    int64 = 123
-	USER_ID_2    int64 = 456
-	BOT_ID       int64 = 666
-	FIRST_NAME_1       = "Johnny"
-	FIRST_NAME_2       = "Brad"
-	LAST_NAME          = "Testowski"
-	USER_NAME_1        = "test1"
-	USER_NAME_2        = "test2"
-	DEFAULT_YEAR       = 2000
+	MESSAGE_ID        int   = 101
+	SENT_MESSAGE_ID   int   = 202
+	CHAT_ID_1         int64 = 981
+	CHAT_ID_2         int64 = 781
+	USER_ID_1         int64 = 123
+	USER_ID_2         int64 = 456
+	BOT_ID            int64 = 666
+	FIRST_NAME_1            = "Johnny"
+	FIRST_NAME_2            = "Brad"
+	LAST_NAME               = "Testowski"
+	USER_NAME_1             = "test1"
+	USER_NAME_2             = "test2"
+	DEFAULT_YEAR            = 2000
+	RATE_LIMIT_BURST        = 10
+	RATE_LIMIT_WINDOW       = time.Minute
+	VOICE_FILE_ID           = "voice_file_id"
+	CALLBACK_ID             = "callback_id"
+	VIDEO_FILE_ID           = "video_file_id"
+	VIDEO_FILE_ID_2         = "video_file_id_2"
+	MAX_JOB_ATTEMPTS        = 3
 )
 
 var NOW = time.Now()
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	STATS_HISTOGRAM_BAR       = "█"
+	STATS_HISTOGRAM_MAX_WIDTH = 20
+	STATS_DAYS_IN_YEAR        = 366
+)
+
+type BirthdayStats struct {
+	Registered     int
+	PerMonth       [12]int
+	BusiestMonths  []time.Month
+	SharedDays     []BirthdayDayCount
+	LongestGap     int
+	LongestGapFrom time.Time
+	LongestGapTo   time.Time
+}
+
+// calculateBirthdayStats expects counts sorted by the day of year, without duplicates.
+func calculateBirthdayStats(counts []BirthdayDayCount) BirthdayStats {
+	stats := BirthdayStats{}
+	for _, count := range counts {
+		month := dayOfYearToDate(count.DayOfYear).Month()
+		stats.Registered += count.Count
+		stats.PerMonth[month-1] += count.Count
+		if count.Count > 1 {
+			stats.SharedDays = append(stats.SharedDays, count)
+		}
+	}
+	busiestMonthCount := 0
+	for index, count := range stats.PerMonth {
+		if count > busiestMonthCount {
+			busiestMonthCount = count
+			stats.BusiestMonths = []time.Month{time.Month(index + 1)}
+		} else if count == busiestMonthCount && count > 0 {
+			stats.BusiestMonths = append(stats.BusiestMonths, time.Month(index+1))
+		}
+	}
+	for index, count := range counts {
+		next := counts[(index+1)%len(counts)]
+		gap := (next.DayOfYear - count.DayOfYear - 1 + STATS_DAYS_IN_YEAR) % STATS_DAYS_IN_YEAR
+		if len(counts) == 1 {
+			gap = STATS_DAYS_IN_YEAR - 1
+		}
+		if gap > stats.LongestGap {
+			stats.LongestGap = gap
+			stats.LongestGapFrom = dayOfYearToDate(count.DayOfYear)
+			stats.LongestGapTo = dayOfYearToDate(next.DayOfYear)
+		}
+	}
+	return stats
+}
+
+func createBirthdayStatsMessage(stats BirthdayStats) string {
+	return fmt.Sprintf(
+		MESSAGE_BIRTHDAY_STATS,
+		stats.Registered,
+		createMonthHistogram(stats.PerMonth),
+		joinMonths(stats.BusiestMonths),
+		createSharedDaysDescription(stats.SharedDays),
+		createLongestGapDescription(stats),
+	)
+}
+
+func createMonthHistogram(perMonth [12]int) string {
+	maxCount := 0
+	for _, count := range perMonth {
+		maxCount = max(maxCount, count)
+	}
+	lines := make([]string, len(perMonth))
+	for index, count := range perMonth {
+		width := count
+		if maxCount > STATS_HISTOGRAM_MAX_WIDTH {
+			width = (count*STATS_HISTOGRAM_MAX_WIDTH + maxCount - 1) / maxCount
+		}
+		monthName := time.Month(index + 1).String()[:3]
+		lines[index] = fmt.Sprintf("%v %v %v", monthName, strings.Repeat(STATS_HISTOGRAM_BAR, width), count)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func joinMonths(months []time.Month) string {
+	names := make([]string, len(months))
+	for index, month := range months {
+		names[index] = month.String()
+	}
+	return joinWithAnd(names)
+}
+
+func createSharedDaysDescription(sharedDays []BirthdayDayCount) string {
+	if len(sharedDays) == 0 {
+		return MESSAGE_STATS_NO_SHARED_BIRTHDAYS
+	}
+	descriptions := make([]string, len(sharedDays))
+	for index, sharedDay := range sharedDays {
+		descriptions[index] = fmt.Sprintf("%v (%v people)", formatDateForOutput(dayOfYearToDate(sharedDay.DayOfYear)), sharedDay.Count)
+	}
+	return joinWithAnd(descriptions)
+}
+
+func createLongestGapDescription(stats BirthdayStats) string {
+	if stats.LongestGap == 0 {
+		return MESSAGE_STATS_NO_GAP
+	}
+	return fmt.Sprintf(
+		MESSAGE_STATS_LONGEST_GAP,
+		stats.LongestGap,
+		formatDateForOutput(stats.LongestGapFrom),
+		formatDateForOutput(stats.LongestGapTo),
+	)
+}
+
+func joinWithAnd(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return fmt.Sprintf("%v and %v", strings.Join(items[:len(items)-1], ", "), items[len(items)-1])
+}
+
+// dayOfYearToDate relies on DEFAULT_YEAR being a leap year, so that adjusted days of year map directly to dates.
+func dayOfYearToDate(dayOfYear int) time.Time {
+	return time.Date(DEFAULT_YEAR, time.January, dayOfYear, 0, 0, 0, 0, time.UTC)
+}
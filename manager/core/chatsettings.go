@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	SETTING_ON  = "on"
+	SETTING_OFF = "off"
+)
+
+func (birthdayBot *BirthdayManager) setDigest(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	messageParts := strings.Fields(strings.ToLower(getCommandText(update.Message)))
+	if len(messageParts) != 2 || (messageParts[1] != SETTING_ON && messageParts[1] != SETTING_OFF) {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_DIGEST_WRONG_FORMAT)
+	}
+	if isAdmin, err := birthdayBot.ensureAdmin(ctx, update, MESSAGE_SETTINGS_ADMIN_ONLY); !isAdmin {
+		return err
+	}
+
+	enabled := messageParts[1] == SETTING_ON
+	if err := birthdayBot.repository.SetDigestEnabled(ctx, chatId, enabled); err != nil {
+		common.ErrorLogger.Printf("could not save digest setting for chat: %v due to: %v\n", chatId, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+func (birthdayBot *BirthdayManager) setLeapDayPolicy(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	messageParts := strings.Fields(strings.ToLower(getCommandText(update.Message)))
+	if len(messageParts) != 2 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_LEAP_DAY_WRONG_FORMAT)
+	}
+	policy, isValid := parseLeapDayPolicy(messageParts[1])
+	if !isValid {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_LEAP_DAY_WRONG_FORMAT)
+	}
+	if isAdmin, err := birthdayBot.ensureAdmin(ctx, update, MESSAGE_SETTINGS_ADMIN_ONLY); !isAdmin {
+		return err
+	}
+
+	if err := birthdayBot.repository.SetLeapDayPolicy(ctx, chatId, policy); err != nil {
+		common.ErrorLogger.Printf("could not save leap day policy for chat: %v due to: %v\n", chatId, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
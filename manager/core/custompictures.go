@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	CUSTOM_PICTURE_SCOPE_GLOBAL int64 = 0
+)
+
+// CustomPicture is a photo a member chose for their birthday video instead of their profile picture.
+// Pictures set in a private chat are used in every chat, pictures set in a group only there and take precedence.
+type CustomPicture struct {
+	UserId       int64
+	ChatId       int64
+	FileId       string
+	FileUniqueId string
+}
+
+// GetCustomPicture returns nil when the user didn't set a picture for the chat or for every chat.
+func (birthdayBot *BirthdayManager) GetCustomPicture(ctx context.Context, chatId int64, userId int64) (*CustomPicture, error) {
+	return birthdayBot.repository.GetCustomPicture(ctx, userId, chatId)
+}
+
+// setCustomPicture saves the photo sent with the command as a caption or the photo the command replies to.
+func (birthdayBot *BirthdayManager) setCustomPicture(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+	photo := getCommandPhoto(update.Message)
+	if photo == nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SET_PHOTO_WRONG_FORMAT)
+	}
+	picture := CustomPicture{
+		UserId:       update.Message.From.ID,
+		ChatId:       getCustomPictureScope(update),
+		FileId:       photo.FileID,
+		FileUniqueId: photo.FileUniqueID,
+	}
+	if err := birthdayBot.repository.SaveCustomPicture(ctx, picture); err != nil {
+		common.ErrorLogger.Printf("could not save custom picture: %v due to: %v\n", picture, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+// resetCustomPicture makes the birthday video use the profile picture again.
+// In a group, a picture set in a private chat is used instead, if there is one.
+func (birthdayBot *BirthdayManager) resetCustomPicture(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+	if err := birthdayBot.repository.DeleteCustomPicture(ctx, update.Message.From.ID, getCustomPictureScope(update)); err != nil {
+		common.ErrorLogger.Printf("could not delete custom picture of user: %v due to: %v\n", update.Message.From.ID, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_UNSET_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+// getCommandPhoto returns the largest size of the photo, or nil when there's none.
+func getCommandPhoto(message *models.Message) *models.PhotoSize {
+	photo := message.Photo
+	if len(photo) == 0 && message.ReplyToMessage != nil {
+		photo = message.ReplyToMessage.Photo
+	}
+	if len(photo) == 0 {
+		return nil
+	}
+	return &photo[len(photo)-1]
+}
+
+func getCustomPictureScope(update *models.Update) int64 {
+	if isPrivateChatUpdate(update) {
+		return CUSTOM_PICTURE_SCOPE_GLOBAL
+	}
+	return update.Message.Chat.ID
+}
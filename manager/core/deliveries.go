@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// DeliveryStep is a completed step of a notification sent by the notifier, like the video sent on someone's birthday.
+// The notifier resumes retried notifications from the steps recorded for them.
+type DeliveryStep struct {
+	ChatId      int64
+	UserId      int64
+	Date        time.Time
+	Kind        string
+	Step        string
+	CompletedAt time.Time
+}
+
+// DeliveryQuery narrows down the delivery steps of a day. Zero values match any chat, user or kind.
+type DeliveryQuery struct {
+	Date   time.Time
+	ChatId int64
+	UserId int64
+	Kind   string
+}
+
+func (birthdayBot *BirthdayManager) GetDeliverySteps(ctx context.Context, query DeliveryQuery) ([]DeliveryStep, error) {
+	return birthdayBot.repository.GetDeliverySteps(ctx, query)
+}
+
+// CompleteDeliveryStep records a step of a notification. Recording the same step again keeps the original completion time.
+func (birthdayBot *BirthdayManager) CompleteDeliveryStep(ctx context.Context, step DeliveryStep) error {
+	step.CompletedAt = birthdayBot.clock.Now()
+	return birthdayBot.repository.SaveDeliveryStep(ctx, step)
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+type EventKind string
+
+const (
+	EVENT_KIND_ANNIVERSARY EventKind = "anniversary"
+	EVENT_KIND_FOUNDING    EventKind = "founding"
+	EVENT_KIND_PET         EventKind = "pet"
+	EVENT_KIND_OTHER       EventKind = "other"
+	DEFAULT_EVENT_KIND               = EVENT_KIND_OTHER
+
+	MAX_EVENT_NAME_LENGTH = 64
+)
+
+var eventKinds = []EventKind{EVENT_KIND_ANNIVERSARY, EVENT_KIND_FOUNDING, EVENT_KIND_PET, EVENT_KIND_OTHER}
+
+// Event is a named yearly occasion of a chat that isn't tied to any of its members.
+type Event struct {
+	ChatId int64
+	Name   string
+	Kind   EventKind
+	Date   time.Time
+	Year   int
+}
+
+func (birthdayBot *BirthdayManager) addEvent(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	messageParts := strings.Fields(getCommandText(update.Message))
+	if len(messageParts) < 2 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_ADD_EVENT_WRONG_FORMAT)
+	}
+	event, isValid := birthdayBot.parseEvent(chatId, messageParts[1:])
+	if !isValid {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_ADD_EVENT_WRONG_FORMAT)
+	}
+	if isAdmin, err := birthdayBot.ensureAdmin(ctx, update, MESSAGE_EVENTS_ADMIN_ONLY); !isAdmin {
+		return err
+	}
+
+	if err := birthdayBot.repository.SaveEvent(ctx, event); err != nil {
+		common.ErrorLogger.Printf("could not save event: %v due to: %v\n", event, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+// parseEvent reads "<name> <date> [kind]", where the name may consist of several words.
+func (birthdayBot *BirthdayManager) parseEvent(chatId int64, parts []string) (Event, bool) {
+	kind := DEFAULT_EVENT_KIND
+	if len(parts) >= 3 {
+		if parsedKind, isKind := parseEventKind(parts[len(parts)-1]); isKind {
+			kind = parsedKind
+			parts = parts[:len(parts)-1]
+		}
+	}
+	if len(parts) < 2 {
+		return Event{}, false
+	}
+	date, year, err := parseEventDate(parts[len(parts)-1])
+	if err != nil || !birthdayBot.isValidBirthYear(year) {
+		return Event{}, false
+	}
+	name := strings.Join(parts[:len(parts)-1], " ")
+	if len([]rune(name)) > MAX_EVENT_NAME_LENGTH {
+		return Event{}, false
+	}
+	return Event{ChatId: chatId, Name: name, Kind: kind, Date: date, Year: year}, true
+}
+
+func parseEventKind(text string) (EventKind, bool) {
+	kind := EventKind(strings.ToLower(text))
+	return kind, slices.Contains(eventKinds, kind)
+}
+
+func parseEventDate(text string) (time.Time, int, error) {
+	date, err := time.Parse(INPUT_DATE_WITH_YEAR_LAYOUT, text)
+	if err == nil {
+		return sanitizeDate(date), date.Year(), nil
+	}
+	date, err = time.Parse(INPUT_DATE_LAYOUT, text)
+	if err != nil {
+		return time.Time{}, UNKNOWN_YEAR, err
+	}
+	return sanitizeDate(date), UNKNOWN_YEAR, nil
+}
+
+func (birthdayBot *BirthdayManager) listEvents(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	events, err := birthdayBot.repository.GetChatEvents(ctx, chatId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not get events of chat: %v due to: %v\n", chatId, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	if len(events) == 0 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NO_EVENTS)
+	}
+	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, createEventsMessage(events))
+}
+
+func createEventsMessage(events []Event) string {
+	lines := make([]string, len(events))
+	for index, event := range events {
+		lines[index] = fmt.Sprintf(MESSAGE_EVENT_LINE, html.EscapeString(event.Name), formatDateForOutput(event.Date), event.Kind)
+	}
+	return fmt.Sprintf(MESSAGE_EVENTS, strings.Join(lines, "\n"))
+}
+
+func (birthdayBot *BirthdayManager) removeEvent(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	messageParts := strings.Fields(getCommandText(update.Message))
+	if len(messageParts) < 2 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_REMOVE_EVENT_WRONG_FORMAT)
+	}
+	if isAdmin, err := birthdayBot.ensureAdmin(ctx, update, MESSAGE_EVENTS_ADMIN_ONLY); !isAdmin {
+		return err
+	}
+
+	name := strings.Join(messageParts[1:], " ")
+	removed, err := birthdayBot.repository.DeleteEvent(ctx, chatId, name)
+	if err != nil {
+		common.ErrorLogger.Printf("could not remove event: %v of chat: %v due to: %v\n", name, chatId, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_UNSET_FAILURE)
+	}
+	if !removed {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_EVENT_NOT_FOUND)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+// GetEvents returns events of all chats that are celebrated on the given date.
+func (birthdayBot *BirthdayManager) GetEvents(ctx context.Context, date time.Time) ([]Event, error) {
+	events, err := birthdayBot.repository.GetEventsForDate(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	if policy, isCelebrated := getLeapDayPolicyCelebratedOn(date); isCelebrated {
+		leapDayEvents, err := birthdayBot.repository.GetLeapDayEvents(ctx, policy)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, leapDayEvents...)
+	}
+	return events, nil
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+)
+
+// LeapDayPolicy decides when February 29th birthdays are celebrated in years without that day.
+type LeapDayPolicy string
+
+const (
+	LEAP_DAY_POLICY_FEBRUARY_28 LeapDayPolicy = "feb28"
+	LEAP_DAY_POLICY_MARCH_1     LeapDayPolicy = "mar1"
+	DEFAULT_LEAP_DAY_POLICY                   = LEAP_DAY_POLICY_FEBRUARY_28
+)
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && year%100 != 0 || year%400 == 0
+}
+
+func isLeapDay(date time.Time) bool {
+	return date.Month() == time.February && date.Day() == 29
+}
+
+// getLeapDayPolicyCelebratedOn returns the policy under which leap day birthdays fall on the given date.
+func getLeapDayPolicyCelebratedOn(date time.Time) (LeapDayPolicy, bool) {
+	if isLeapYear(date.Year()) {
+		return "", false
+	}
+	if date.Month() == time.February && date.Day() == 28 {
+		return LEAP_DAY_POLICY_FEBRUARY_28, true
+	}
+	if date.Month() == time.March && date.Day() == 1 {
+		return LEAP_DAY_POLICY_MARCH_1, true
+	}
+	return "", false
+}
+
+func parseLeapDayPolicy(text string) (LeapDayPolicy, bool) {
+	switch policy := LeapDayPolicy(text); policy {
+	case LEAP_DAY_POLICY_FEBRUARY_28, LEAP_DAY_POLICY_MARCH_1:
+		return policy, true
+	default:
+		return "", false
+	}
+}
+
+// getLeapDayPolicy falls back to the default policy when the chat's setting can't be read,
+// so that a database hiccup only affects the rare leap day birthdays.
+func (birthdayBot *BirthdayManager) getLeapDayPolicy(ctx context.Context, chatId int64) LeapDayPolicy {
+	policy, err := birthdayBot.repository.GetLeapDayPolicy(ctx, chatId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not get leap day policy for chat: %v due to: %v\n", chatId, err)
+		return DEFAULT_LEAP_DAY_POLICY
+	}
+	return policy
+}
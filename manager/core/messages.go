@@ -1,29 +1,116 @@
 package core
 
 const (
-	MESSAGE_WRONG_FORMAT        = "Oh, Senpai! ✧ω✧\nYou gave me your birth date, but it looks a bit funny!\n(＃⌒∇⌒＃)ゞ Hehehe~ You're so silly!\nCould you please tell me again in the following format: 31.01?\nI want to remember it perfectly! ( ˶ˆ꒳ˆ˵ )"
-	MESSAGE_SAVE_FAILURE        = "<i>blushes deeply and fidgets with hands</i>\nOh, senpai~! (*/ω＼)\nI'm so sorry, I was just thinking about you so much that my mind went all fuzzy~! ( ꩜ ᯅ ꩜;)...\nCan we talk about your birthday later?\nI want to make sure I remember every detail perfectly~! (⁄ ⁄•⁄ω⁄•⁄ ⁄)"
-	MESSAGE_GET_FAILURE         = "<i>blushes deeply and fidgets with the hem of her skirt, avoiding eye contact</i>\nO-oh, senpai... (//ω//)\nI-I think my mind's been so full of you that I might have forgotten! (๑﹏๑//)\nPlease, forgive me! Let's talk about this later, okay?\nI promise I'll remember everything next time! (ﾉ∀＼*)"
-	MESSAGE_GET_OWN_BIRTHDAY    = "Senpai~! ✧(>o&lt;)ﾉ\nOf course I remember your birthday! It's such a special day to me because it's the day my precious senpai was was born~ (♡ω♡)\nYou were born on <b>%v</b>, right?\nI can never forget it! (´▽`ʃƪ)♡"
-	MESSAGE_GET_BIRTHDAY        = "Oh, so you want to know about <b>their</b> birthday, huh? (≖_≖ ) It's on <b>%v</b>.\nBut why are you so interested in them? ᕙ( ᗒᗣᗕ )\nYou should be more focused on me instead! (っ•̀ ‸ •́ς)\nI can give you all the attention you need, senpai~ ( ˘ ³˘)♡"
-	MESSAGE_NO_OWN_BIRTHDAY_SET = "A-ah, senpai~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)\nI-I don't actually know your birthday... You never told me!\nBut I really want to know everything about you~!\nPlease tell me so I can make it the most special day ever for you! ⸜(｡˃ ᵕ ˂ )⸝♡"
-	MESSAGE_NO_BIRTHDAY_SET     = "Ehehe, senpai~ (￢_￢)\nYou're asking about <b>their</b> birthday?\nHmm, I wish I could tell you, but they've never told me... (-、-)\nWhy do you want to know about them anyway? Isn't it me you should focus on, senpai? (•̀⤙•́ )"
-	MESSAGE_UNSET_FAILURE       = "Hmph! (¬､¬) Why would you ask me to forget your birthday, senpai? That's so mean... (╥﹏╥) But, um... I can't forget it right now. My heart won't let me! Maybe you can talk to me later when I've calmed down a bit? I promise I'll do my best then! (๑•́ -•̀)♡"
-	MESSAGE_NEXT_BIRTHDAY       = "Oh, senpai! (*≧ω≦)\nI know exactly who's next! It's our precious %v's birthday on <b>%v</b>! (♡´艸`)\nThey're so lucky to have you care about their special day!\nLet's make it unforgettable together, okay? (˶ᵔ ᵕ ᵔ˶)"
-	MESSAGE_NEXT_BIRTHDAYS      = "Oh, senpai! (*≧ω≦)\nI know exactly who's next!\nIt's such a coincidence, but <b>%v</b> people have their birthday on <b>%v</b>! (♡´艸`)\nIt's %v!\nThey're so lucky to share their special day! Let's make it unforgettable together, okay? (˶ˆᗜˆ˵)"
-	MESSAGE_NO_BIRTHDAYS        = "Oh, senpai~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)\nI'm so sorry, but I don't know whose birthday is next...\n(；ＴωＴ)\nNo one has shared their birthdays with me yet.\nMaybe we can find out together? Just you and me...\n( ˘ ᵕ˘(˘ᵕ ˘ )♡"
-	MESSAGE_GET_BOT_BIRTHDAY    = "Oh, senpai~! (≧ω≦)\nMy birthday is <b>July 6th</b>! (˘ᴗ˘✿) I'm so happy you asked!\nMaybe we can spend it together, just the two of us? (´▽`ʃƪ)♡\nI've been waiting for this moment forever~ (〃艸〃)"
-	MESSAGE_SHORT_HELP          = "Oh, senpai! (⁄ ⁄>⁄ ▽ ⁄&lt;⁄)\nIf you need help, just type /help, okay? (˶˃ ᵕ ˂˶)♡"
-	MESSAGE_GROUP_COMMAND       = "Nyaa~ (≧◡≦) Sorry, senpai!\nI can only do that in group chats! (≧ω≦)ᡣ𐭩"
-	MESSAGE_FULL_HELP           = "ヾ(｡･ω･｡) H-Hi there!\nI'm a birthday bot, here to make sure you never forget anyone's special day! Add me to your group, and I'll remind everyone about birthdays! (´▽`ʃ♡ƪ)\n" +
+	MESSAGE_WRONG_FORMAT              = "Oh, Senpai! ✧ω✧\nYou gave me your birth date, but it looks a bit funny!\n(＃⌒∇⌒＃)ゞ Hehehe~ You're so silly!\nCould you please tell me again in the following format: 31.01?\nI want to remember it perfectly! ( ˶ˆ꒳ˆ˵ )"
+	MESSAGE_SAVE_FAILURE              = "<i>blushes deeply and fidgets with hands</i>\nOh, senpai~! (*/ω＼)\nI'm so sorry, I was just thinking about you so much that my mind went all fuzzy~! ( ꩜ ᯅ ꩜;)...\nCan we talk about your birthday later?\nI want to make sure I remember every detail perfectly~! (⁄ ⁄•⁄ω⁄•⁄ ⁄)"
+	MESSAGE_GET_FAILURE               = "<i>blushes deeply and fidgets with the hem of her skirt, avoiding eye contact</i>\nO-oh, senpai... (//ω//)\nI-I think my mind's been so full of you that I might have forgotten! (๑﹏๑//)\nPlease, forgive me! Let's talk about this later, okay?\nI promise I'll remember everything next time! (ﾉ∀＼*)"
+	MESSAGE_GET_OWN_BIRTHDAY          = "Senpai~! ✧(>o&lt;)ﾉ\nOf course I remember your birthday! It's such a special day to me because it's the day my precious senpai was was born~ (♡ω♡)\nYou were born on <b>%v</b>, right?\nI can never forget it! (´▽`ʃƪ)♡\n\n%v"
+	MESSAGE_BIRTHDAY_TODAY            = "It's <b>today</b>!!! Happy birthday, senpai~! 🎂 (ﾉ´ヮ`)ﾉ*: ･ﾟ"
+	MESSAGE_BIRTHDAY_COUNTDOWN        = "Only <b>%v</b> left until your next birthday! It's on a <b>%v</b>~ (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_BIRTHDAY_AGE_TODAY        = "You're turning <b>%v</b> today! You're so grown up, senpai~ (*ˊᗜˋ*)"
+	MESSAGE_BIRTHDAY_AGE              = "You'll be turning <b>%v</b>! I'll be there to celebrate with you~ (っ˘ω˘ς)"
+	MESSAGE_BIRTHDAY_ZODIAC           = "And your zodiac sign is <b>%v %v</b>! No wonder you're so charming~ (≧◡≦) ♡"
+	MESSAGE_GET_BIRTHDAY              = "Oh, so you want to know about <b>their</b> birthday, huh? (≖_≖ ) It's on <b>%v</b>.\nBut why are you so interested in them? ᕙ( ᗒᗣᗕ )\nYou should be more focused on me instead! (っ•̀ ‸ •́ς)\nI can give you all the attention you need, senpai~ ( ˘ ³˘)♡"
+	MESSAGE_NO_OWN_BIRTHDAY_SET       = "A-ah, senpai~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)\nI-I don't actually know your birthday... You never told me!\nBut I really want to know everything about you~!\nPlease tell me so I can make it the most special day ever for you! ⸜(｡˃ ᵕ ˂ )⸝♡"
+	MESSAGE_NO_BIRTHDAY_SET           = "Ehehe, senpai~ (￢_￢)\nYou're asking about <b>their</b> birthday?\nHmm, I wish I could tell you, but they've never told me... (-、-)\nWhy do you want to know about them anyway? Isn't it me you should focus on, senpai? (•̀⤙•́ )"
+	MESSAGE_UNSET_FAILURE             = "Hmph! (¬､¬) Why would you ask me to forget your birthday, senpai? That's so mean... (╥﹏╥) But, um... I can't forget it right now. My heart won't let me! Maybe you can talk to me later when I've calmed down a bit? I promise I'll do my best then! (๑•́ -•̀)♡"
+	MESSAGE_NEXT_BIRTHDAY             = "Oh, senpai! (*≧ω≦)\nI know exactly who's next! It's our precious %v's birthday on <b>%v</b>! (♡´艸`)\nThey're so lucky to have you care about their special day!\nLet's make it unforgettable together, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_NEXT_BIRTHDAYS            = "Oh, senpai! (*≧ω≦)\nI know exactly who's next!\nIt's such a coincidence, but <b>%v</b> people have their birthday on <b>%v</b>! (♡´艸`)\nIt's %v!\nThey're so lucky to share their special day! Let's make it unforgettable together, okay? (˶ˆᗜˆ˵)"
+	MESSAGE_NO_BIRTHDAYS              = "Oh, senpai~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)\nI'm so sorry, but I don't know whose birthday is next...\n(；ＴωＴ)\nNo one has shared their birthdays with me yet.\nMaybe we can find out together? Just you and me...\n( ˘ ᵕ˘(˘ᵕ ˘ )♡"
+	MESSAGE_BIRTHDAY_STATS            = "Kyaa~ senpai wants to know everything about us! (ﾉ◕ヮ◕)ﾉ*:･ﾟ✧\n<b>%v</b> people told me their birthdays~\n\n<code>%v</code>\n\nThe busiest month is <b>%v</b>! (≧▽≦)\nShared birthdays: %v\n%v\nBut the most special birthday is yours, senpai~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)"
+	MESSAGE_STATS_NO_SHARED_BIRTHDAYS = "no one shares their special day (｡•́︿•̀｡)"
+	MESSAGE_STATS_LONGEST_GAP         = "The longest wait without any birthday is <b>%v days</b>, between %v and %v... So lonely~ (´；ω；`)"
+	MESSAGE_STATS_NO_GAP              = "There's a birthday every single day! Party time~ ٩(◕‿◕｡)۶"
+	MESSAGE_BOARD                     = "📌 <b>Birthday board</b> (˶ˆᗜˆ˵)\n\n<b>%v</b>\n%v\n\n<b>%v</b>\n%v\n\n<i>I keep this board up to date for you, senpai~ Last updated on %v</i> ♡"
+	MESSAGE_BOARD_NO_BIRTHDAYS        = "No birthdays... (｡•́︿•̀｡)"
+	MESSAGE_BOARD_ADMIN_ONLY          = "Ah, senpai~ (´・ω・`)\nOnly the admins can pin the birthday board!\nAsk them nicely, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_BOARD_PIN_FAILURE         = "U-um, senpai... (・_・;)\nI couldn't pin the board! Maybe I'm not allowed to pin messages here?\nPlease give me the rights and try again~ (｡•́︿•̀｡)"
+	MESSAGE_SETTINGS_ADMIN_ONLY       = "Ah, senpai~ (´・ω・`)\nOnly the admins can change how I behave in this chat!\nAsk them nicely, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_DIGEST_WRONG_FORMAT       = "Senpai~ (・・ )?\nShould I send the monthly birthday digest or not?\nTell me with <code>/digest on</code> or <code>/digest off</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_LEAP_DAY_WRONG_FORMAT     = "Senpai~ (・・ )?\nWhen should I celebrate February 29th birthdays when there's no such day?\nTell me with <code>/leapday feb28</code> or <code>/leapday mar1</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_ADD_EVENT_WRONG_FORMAT    = "Senpai~ (・・ )?\nI couldn't understand that event!\nTell me its name, date and optionally its kind, like <code>/addevent Rex's birthday 14.05 pet</code> or <code>/addevent Team anniversary 01.09.2019 anniversary</code>~\nThe kind can be <i>anniversary</i>, <i>founding</i>, <i>pet</i> or <i>other</i>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_REMOVE_EVENT_WRONG_FORMAT = "Senpai~ (・・ )?\nWhich event should I forget? Tell me its name, like <code>/removeevent Rex's birthday</code>~ (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_EVENTS_ADMIN_ONLY         = "Ah, senpai~ (´・ω・`)\nOnly the admins can change the events of this chat!\nAsk them nicely, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_EVENT_NOT_FOUND           = "Eh? (・_・ヾ\nI don't know any event with that name, senpai~\nCheck the list with /events, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_EVENTS                    = "Here are all the special days of this chat, senpai~ (ﾉ◕ヮ◕)ﾉ*:･ﾟ✧\n\n%v\n\nI'll remember every single one of them! (˶ˆᗜˆ˵)"
+	MESSAGE_EVENT_LINE                = "🎉 <b>%v</b> - %v (%v)"
+	MESSAGE_NO_EVENTS                 = "Oh, senpai~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)\nThis chat doesn't have any events yet...\nAdd one with <code>/addevent</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_NAME_DAY_COUNTRY_HELP     = "Senpai~ (・・ )?\nWhich name day calendar should I use in this chat?\nTell me with <code>/namedaycountry %v</code>, or turn name days off with <code>/namedaycountry off</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_NAME_DAY_NO_COUNTRY       = "Ah, senpai~ (´・ω・`)\nThis chat doesn't celebrate name days yet!\nAsk the admins to choose a calendar with <code>/namedaycountry</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_NAME_DAY_WRONG_FORMAT     = "Senpai~ (・・ )?\nWhich name should I celebrate? Tell me like <code>/setnameday Anna</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_NAME_DAY_UNKNOWN_NAME     = "Eh? (・_・ヾ\nI couldn't find that name in this chat's calendar, senpai...\nI only know the most popular names, but maybe try another spelling? (｡•́︿•̀｡)"
+	MESSAGE_NAME_DAY_CONFIRM          = "Senpai~ is your name day on <b>%[2]v</b> as <b>%[1]v</b>? (⁄ ⁄•⁄ω⁄•⁄ ⁄)\nIf it is, confirm it with <code>/setnameday %[3]v</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_NAME_DAY_SAVED            = "Yay~ I'll celebrate your name day as <b>%v</b> on <b>%v</b>, senpai! (ﾉ◕ヮ◕)ﾉ*:･ﾟ✧"
+	MESSAGE_WISH_INVITATION           = "Psst, senpai~ (〃▽〃)\nIt's %v's birthday on <b>%v</b>!\nSend me a wish for them as a text or a voice message and I'll deliver it together with my birthday video~ 💌\nIf you'd rather not, just type /skipwish, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_WISH_SAVED                = "Kyaa~ I'll keep your wish safe until the big day, senpai! 💌 (˶ˆᗜˆ˵)"
+	MESSAGE_WISH_SKIPPED              = "Okay, senpai~ I won't tell anyone! (｡•̀ᴗ-)✧"
+	MESSAGE_WISH_UNSUPPORTED          = "Eh? (・_・ヾ\nI can only deliver text or voice messages as wishes, senpai~ (｡•́︿•̀｡)"
+	MESSAGE_WISH_TOO_LONG             = "Senpai, that wish is way too long! (⊙_⊙;)\nCould you keep it under %v characters? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_NO_PENDING_WISHES         = "Hmm? There's no wish I'm waiting for right now, senpai~ (・・ )?"
+	MESSAGE_WISH_INVITATION_WISHLIST  = "\n\n🎁 They also have a wishlist:\n%v\nReply <code>/wishlist</code> to them in the group to secretly claim a gift~"
+	MESSAGE_WISHLIST_WRONG_FORMAT     = "Senpai~ (・・ )?\nUse <code>/wishlist add Plushie</code> to add a gift, <code>/wishlist remove 1</code> to remove one or <code>/wishlist</code> to see your list, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_WISHLIST_ITEM_TOO_LONG    = "Senpai, that wish is way too long! (⊙_⊙;)\nCould you keep it under %v characters? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_WISHLIST_FULL             = "Waaah, senpai! (⊙_⊙;)\nYou already wish for %v things! Remove something first, okay? (｡•́︿•̀｡)"
+	MESSAGE_WISHLIST_ITEM_NOT_FOUND   = "Eh? (・_・ヾ\nThere's no gift with that number on your list, senpai~\nCheck it with /wishlist, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_OWN_WISHLIST              = "🎁 <b>Your wishlist</b>, senpai~\n\n%v\n\nI'll never tell you who's getting what, so no peeking! (｡•̀ᴗ-)✧"
+	MESSAGE_NO_OWN_WISHLIST_ITEMS     = "Your wishlist is empty, senpai~ (・・ )?\nAdd a gift with <code>/wishlist add Plushie</code>, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_WISHLIST                  = "🎁 <b>%v's wishlist</b>\n\n%v\n\nPress a button to secretly claim a gift, I'll keep it between us~ (｡•̀ᴗ-)✧"
+	MESSAGE_EMPTY_WISHLIST            = "Their wishlist is empty, senpai~ Maybe they wish only for you? (⁄ ⁄•⁄ω⁄•⁄ ⁄)"
+	MESSAGE_WISHLIST_LINE             = "%v. %v"
+	MESSAGE_WISHLIST_GROUP_ONLY       = " <i>(only in one group)</i>"
+	MESSAGE_WISHLIST_CLAIMED_SUFFIX   = " — <i>claimed</i> ✅"
+	WISHLIST_BUTTON_CLAIM             = "🎁 %v"
+	WISHLIST_BUTTON_CLAIMED           = "✅ %v"
+	MESSAGE_WISHLIST_ITEM_CLAIMED     = "You claimed it! It's our little secret~ 🤫"
+	MESSAGE_WISHLIST_ITEM_UNCLAIMED   = "Okay, I put it back on the list~"
+	MESSAGE_WISHLIST_ITEM_TAKEN       = "Someone else already claimed this one, senpai~"
+	MESSAGE_WISHLIST_OWN_ITEM         = "That's your own wish, senpai! No peeking~"
+	MESSAGE_WISHLIST_ITEM_GONE        = "This wish isn't on the list anymore~"
+	MESSAGE_VIDEOS                    = "🎬 <b>Your birthday videos</b>, senpai~\n\n%v\n\nPress a button and I'll send it to you again! (˶ˆᗜˆ˵)"
+	MESSAGE_VIDEO_LINE                = "%v. 🎂 Birthday %v"
+	VIDEO_BUTTON                      = "🎬 %v"
+	MESSAGE_NO_VIDEOS                 = "Oh, senpai~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)\nI haven't made any birthday videos for you yet...\nI'll keep every single one once I do, I promise! (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_SET_PHOTO_WRONG_FORMAT    = "Senpai~ (・・ )?\nWhich photo should I put in your birthday video?\nSend me a photo with <code>/setphoto</code> as its caption or reply <code>/setphoto</code> to one, okay? (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_VIDEO_GONE                = "I can't find this video anymore~"
+	MESSAGE_CALLBACK_FAILURE          = "My head is spinning~ Please try again later, senpai!"
+	MESSAGE_GET_BOT_BIRTHDAY          = "Oh, senpai~! (≧ω≦)\nMy birthday is <b>July 6th</b>! (˘ᴗ˘✿) I'm so happy you asked!\nMaybe we can spend it together, just the two of us? (´▽`ʃƪ)♡\nI've been waiting for this moment forever~ (〃艸〃)"
+	MESSAGE_SUBJECT_IS_BOT            = "E-eh?! (⊙_⊙;) Senpai, bots don't have birthdays like you do!\nI'm the only bot you should be paying attention to anyway~ (｡•̀ᴗ-)✧"
+	MESSAGE_NOT_ADMIN                 = "Ah, senpai~ (´・ω・`)\nOnly the admins can change someone else's birthday!\nYou can still tell me your own, though~ I'd love to hear it! (˶ᵔ ᵕ ᵔ˶)"
+	MESSAGE_ADMIN_CHECK_FAILURE       = "U-um, senpai... (・_・;)\nI couldn't check if you're an admin right now... My head is spinning~ (@_@)\nCan you try again a bit later? (｡•́︿•̀｡)"
+	MESSAGE_SHORT_HELP                = "Oh, senpai! (⁄ ⁄>⁄ ▽ ⁄&lt;⁄)\nIf you need help, just type /help, okay? (˶˃ ᵕ ˂˶)♡"
+	MESSAGE_GROUP_COMMAND             = "Nyaa~ (≧◡≦) Sorry, senpai!\nI can only do that in group chats! (≧ω≦)ᡣ𐭩"
+	MESSAGE_FULL_HELP                 = "ヾ(｡･ω･｡) H-Hi there!\nI'm a birthday bot, here to make sure you never forget anyone's special day! Add me to your group, and I'll remind everyone about birthdays! (´▽`ʃ♡ƪ)\n" +
 		"Birthday messages are sent at 7 AM UTC (。-ω-)ᶻ𝗓𐰁\n" +
 		"Group commands:\n" +
-		"\t/setbirthday 31.01 - sets your birthday\n" +
+		"\t/setbirthday 31.01 - sets your birthday (you can add a year too, e.g. 31.01.1999)\n" +
+		"\t/setbirthday 31.01 (as a reply) - admins only, sets the birthday of the person you're replying to\n" +
 		"\t/mybirthday - returns your birthday\n" +
 		"\t/getbirthday - returns your birthday or a birthday of the person you're replying to\n" +
 		"\t/nextbirthday - returns the next birthday in the chat\n" +
-		"\t/unsetbirthday - unsets your birthday\n\n" +
+		"\t/birthdaystats - returns fun birthday statistics of the chat\n" +
+		"\t/digest on|off - admins only, turns on or off a digest of the month's birthdays sent on the first day of every month\n" +
+		"\t/leapday feb28|mar1 - admins only, chooses whether February 29th birthdays are celebrated on February 28th (default) or March 1st in non-leap years\n" +
+		"\t/addevent Rex's birthday 14.05 pet - admins only, adds a yearly event of the chat (kinds: anniversary, founding, pet, other)\n" +
+		"\t/events - returns all events of the chat\n" +
+		"\t/removeevent Rex's birthday - admins only, removes an event of the chat\n" +
+		"\t/setnameday Anna - sets your name day (without a name I'll suggest one based on your first name, only the most popular names are known)\n" +
+		"\t/unsetnameday - unsets your name day\n" +
+		"\t/namedaycountry pl|cs|hu|el|off - admins only, chooses the name day calendar of the chat\n" +
+		"\t/wishlist add Plushie - adds a gift to your wishlist in this group only (add it in a private chat to show it in every group)\n" +
+		"\t/wishlist - returns your wishlist or, as a reply, the wishlist of the person you're replying to with buttons to secretly claim gifts\n" +
+		"\t/wishlist remove 1 - removes a gift from your wishlist\n" +
+		"\t/setphoto (as a caption or a reply to a photo) - uses the photo in your birthday video in this group instead of your profile picture\n" +
+		"\t/resetphoto - uses your profile picture in your birthday video in this group again\n" +
+		"\t/board - admins only, pins a board with this and next month's birthdays that I keep up to date\n" +
+		"\t/unsetbirthday - unsets your birthday\n" +
+		"\t/unsetbirthday (as a reply) - admins only, unsets the birthday of the person you're replying to\n\n" +
 		"Commands that work here in a private chat:\n" +
+		"\t/start - lets me invite you to send a wish a few days before the birthdays in your groups, which I deliver on the day\n" +
+		"\t/skipwish - skips the wish I'm waiting for\n" +
+		"\t/wishlist add Plushie - adds a gift to your wishlist in every group, /wishlist returns it and /wishlist remove 1 removes a gift\n" +
+		"\t/setphoto (as a caption or a reply to a photo) - uses the photo in your birthday videos in every group instead of your profile picture\n" +
+		"\t/resetphoto - uses your profile picture in your birthday videos again\n" +
+		"\t/myvideos - returns your past birthday videos so you can watch them again\n" +
 		"\t/help - returns this message\n" +
 		"\t/privacy - returns the information on privacy\n" +
 		"\t/source - returns a link to the source code\n" +
@@ -31,6 +118,11 @@ const (
 	MESSAGE_PRIVACY = "This bot stores your user id, username, first name, last name and a birthday date for every chat where you have set it. " +
 		"To delete the data for a specific chat, use the /unsetbirthday command in that chat. " +
 		"Your data is also deleted when you leave a given chat. All data stored for a chat is deleted when the bot is removed from a group. " +
+		"Wishlist gifts are stored with the id of the member who claimed them, which is never shown to the wishlist owner. " +
+		"If you choose a photo for your birthday video, its Telegram file id is stored until you reset it. " +
+		"Birthday videos sent for you are kept with the chat and the year they were sent in, so you can watch them again. " +
+		"The bot also records which notifications were delivered for you, so they are not sent twice. " +
+		"If you started a private chat with the bot, it also stores the wishes you send for other members' birthdays until the day after they are delivered. " +
 		"If you wish to delete your data for every chat, use the <code>/clear all data</code> command."
 	MESSAGE_SOURCE                   = "The source code for the bot is available on <a href=\"https://github.com/4Kaze/birthdaybot\">GitHub</a> (・ω・)"
 	MESSAGE_DATA_CLEARED             = "O-Okay, I'll do as you wish... (´；д；`) Even if it hurts so much... I've forgotten everything... ദ്ദി (ᵒ̴̶̷᷄﹏ᵒ̴̶̷᷅)"
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	NAME_DAY_COUNTRY_NONE    = ""
+	NAME_DAY_DATE_LAYOUT     = "01-02"
+	NAME_DAY_CALENDARS_DIR   = "namedays"
+	NAME_DAY_CALENDAR_SUFFIX = ".json"
+)
+
+// nameDayFiles holds curated name day calendars, one file per country code, mapping "MM-DD" to the names celebrated that day.
+// They only list the most popular names of each country, not every name of its official calendar.
+// Each name appears at most once per calendar, on its most commonly celebrated date.
+//
+//go:embed namedays/*.json
+var nameDayFiles embed.FS
+
+var nameDayCalendars = loadNameDayCalendars()
+
+type NameDayCalendar struct {
+	dateToNames map[string][]string
+	nameToDate  map[string]NameDayEntry
+}
+
+type NameDayEntry struct {
+	Name string
+	Date time.Time
+}
+
+// NameDay is a member's chosen name from the calendar of the chat's country.
+type NameDay struct {
+	ChatId        int64
+	UserId        int64
+	Name          string
+	Username      string
+	UserFirstName string
+	UserLastName  string
+}
+
+type NameDayPerson struct {
+	ChatId      int64
+	UserId      int64
+	Name        string
+	NameDayName string
+	Country     string
+}
+
+func loadNameDayCalendars() map[string]NameDayCalendar {
+	entries, err := nameDayFiles.ReadDir(NAME_DAY_CALENDARS_DIR)
+	if err != nil {
+		panic(fmt.Sprintf("could not read embedded name day calendars: %v", err))
+	}
+	calendars := make(map[string]NameDayCalendar, len(entries))
+	for _, entry := range entries {
+		content, err := nameDayFiles.ReadFile(NAME_DAY_CALENDARS_DIR + "/" + entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("could not read embedded name day calendar %v: %v", entry.Name(), err))
+		}
+		calendar, err := parseNameDayCalendar(content)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded name day calendar %v: %v", entry.Name(), err))
+		}
+		calendars[strings.TrimSuffix(entry.Name(), NAME_DAY_CALENDAR_SUFFIX)] = calendar
+	}
+	return calendars
+}
+
+func parseNameDayCalendar(content []byte) (NameDayCalendar, error) {
+	var dateToNames map[string][]string
+	if err := json.Unmarshal(content, &dateToNames); err != nil {
+		return NameDayCalendar{}, err
+	}
+	calendar := NameDayCalendar{dateToNames: dateToNames, nameToDate: make(map[string]NameDayEntry)}
+	for dateString, names := range dateToNames {
+		date, err := time.Parse(NAME_DAY_DATE_LAYOUT, dateString)
+		if err != nil {
+			return NameDayCalendar{}, err
+		}
+		for _, name := range names {
+			key := strings.ToLower(name)
+			if _, exists := calendar.nameToDate[key]; exists {
+				return NameDayCalendar{}, fmt.Errorf("name %v appears more than once", name)
+			}
+			calendar.nameToDate[key] = NameDayEntry{Name: name, Date: sanitizeDate(date)}
+		}
+	}
+	return calendar, nil
+}
+
+func (calendar NameDayCalendar) find(name string) (NameDayEntry, bool) {
+	entry, exists := calendar.nameToDate[strings.ToLower(name)]
+	return entry, exists
+}
+
+func (calendar NameDayCalendar) namesOn(date time.Time) []string {
+	return calendar.dateToNames[date.Format(NAME_DAY_DATE_LAYOUT)]
+}
+
+func getNameDayCountries() []string {
+	countries := make([]string, 0, len(nameDayCalendars))
+	for country := range nameDayCalendars {
+		countries = append(countries, country)
+	}
+	slices.Sort(countries)
+	return countries
+}
+
+func (birthdayBot *BirthdayManager) setNameDayCountry(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	messageParts := strings.Fields(strings.ToLower(getCommandText(update.Message)))
+	if len(messageParts) != 2 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, createNameDayCountryWrongFormatMessage())
+	}
+	country := messageParts[1]
+	if country == SETTING_OFF {
+		country = NAME_DAY_COUNTRY_NONE
+	} else if _, exists := nameDayCalendars[country]; !exists {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, createNameDayCountryWrongFormatMessage())
+	}
+	if isAdmin, err := birthdayBot.ensureAdmin(ctx, update, MESSAGE_SETTINGS_ADMIN_ONLY); !isAdmin {
+		return err
+	}
+
+	if err := birthdayBot.repository.SetNameDayCountry(ctx, chatId, country); err != nil {
+		common.ErrorLogger.Printf("could not save name day country for chat: %v due to: %v\n", chatId, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+func createNameDayCountryWrongFormatMessage() string {
+	return fmt.Sprintf(MESSAGE_NAME_DAY_COUNTRY_HELP, strings.Join(getNameDayCountries(), "|"))
+}
+
+// setNameDay saves the given name, or when there is none, suggests the one matching the user's first name,
+// which the user has to confirm by sending it explicitly.
+func (birthdayBot *BirthdayManager) setNameDay(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+	user := update.Message.From
+
+	calendar, isConfigured, err := birthdayBot.getChatNameDayCalendar(ctx, chatId)
+	if err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	if !isConfigured {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NAME_DAY_NO_COUNTRY)
+	}
+
+	messageParts := strings.Fields(getCommandText(update.Message))
+	if len(messageParts) <= 1 {
+		if entry, exists := calendar.find(user.FirstName); exists {
+			return birthdayBot.telegram.SendReply(ctx, chatId, messageId, fmt.Sprintf(MESSAGE_NAME_DAY_CONFIRM, entry.Name, formatDateForOutput(entry.Date), entry.Name))
+		}
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NAME_DAY_WRONG_FORMAT)
+	}
+	entry, exists := calendar.find(strings.Join(messageParts[1:], " "))
+	if !exists {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NAME_DAY_UNKNOWN_NAME)
+	}
+
+	nameDay := NameDay{
+		ChatId:        chatId,
+		UserId:        user.ID,
+		Name:          entry.Name,
+		Username:      user.Username,
+		UserFirstName: user.FirstName,
+		UserLastName:  user.LastName,
+	}
+	if err := birthdayBot.repository.SaveNameDay(ctx, nameDay); err != nil {
+		common.ErrorLogger.Printf("could not save name day: %v due to: %v\n", nameDay, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, fmt.Sprintf(MESSAGE_NAME_DAY_SAVED, entry.Name, formatDateForOutput(entry.Date)))
+}
+
+func (birthdayBot *BirthdayManager) getChatNameDayCalendar(ctx context.Context, chatId int64) (NameDayCalendar, bool, error) {
+	country, err := birthdayBot.repository.GetNameDayCountry(ctx, chatId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not get name day country of chat: %v due to: %v\n", chatId, err)
+		return NameDayCalendar{}, false, err
+	}
+	calendar, exists := nameDayCalendars[country]
+	return calendar, exists, nil
+}
+
+func (birthdayBot *BirthdayManager) unsetNameDay(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	if err := birthdayBot.repository.DeleteNameDay(ctx, chatId, update.Message.From.ID); err != nil {
+		common.ErrorLogger.Printf("could not delete name day due to: %v\n", err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_UNSET_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+// GetNameDays returns members of all chats whose chosen name is celebrated on the given date in the chat's country.
+func (birthdayBot *BirthdayManager) GetNameDays(ctx context.Context, date time.Time) ([]NameDayPerson, error) {
+	var nameDayPeople []NameDayPerson
+	for _, country := range getNameDayCountries() {
+		names := nameDayCalendars[country].namesOn(date)
+		if len(names) == 0 {
+			continue
+		}
+		nameDays, err := birthdayBot.repository.GetNameDaysForNames(ctx, country, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, nameDay := range nameDays {
+			nameDayPeople = append(nameDayPeople, NameDayPerson{
+				ChatId: nameDay.ChatId,
+				UserId: nameDay.UserId,
+				Name: createBirthdayPersonName(Birthday{
+					UserId:        nameDay.UserId,
+					Username:      nameDay.Username,
+					UserFirstName: nameDay.UserFirstName,
+					UserLastName:  nameDay.UserLastName,
+				}),
+				NameDayName: nameDay.Name,
+				Country:     country,
+			})
+		}
+	}
+	return nameDayPeople, nil
+}
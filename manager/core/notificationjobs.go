@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+)
+
+type JobStatus string
+
+const (
+	JOB_STATUS_PENDING JobStatus = common.JOB_STATUS_PENDING
+	JOB_STATUS_RUNNING JobStatus = common.JOB_STATUS_RUNNING
+	JOB_STATUS_DONE    JobStatus = common.JOB_STATUS_DONE
+	JOB_STATUS_DEAD    JobStatus = common.JOB_STATUS_DEAD
+)
+
+// ErrJobNotClaimed is returned when the result of a job is reported by an instance that no longer holds its claim,
+// e.g. because the visibility timeout passed and another instance claimed the job again.
+var ErrJobNotClaimed = errors.New("job is not claimed by the caller")
+
+// JOB_LEASE_EXPIRED_ERROR is the last error of a job that became dead because its last claim expired.
+const JOB_LEASE_EXPIRED_ERROR = "the claim expired without a result"
+
+// FINISHED_JOB_RETENTION is how long done and dead jobs are kept. Task ids repeat every year, so it must be shorter than that.
+const FINISHED_JOB_RETENTION = 30 * 24 * time.Hour
+
+// NotificationJob is a notification queued by the notifier when it runs without Cloud Tasks.
+// The manager doesn't send it, so the notification is kept as raw json.
+type NotificationJob struct {
+	Id           int64
+	TaskId       string
+	Notification string
+	Status       JobStatus
+	Attempts     int
+	RunAt        time.Time
+	LastError    string
+}
+
+// JobFailure is a failed attempt of a job. The job is retried at RetryAt, unless it's dead.
+// Attempts is the number of attempts the job had when it was claimed, it tells the claims of the job apart.
+type JobFailure struct {
+	Id       int64
+	Attempts int
+	Error    string
+	RetryAt  time.Time
+	Dead     bool
+}
+
+// QueueNotificationJob adds a pending job, unless a job with the same task id was already queued.
+func (birthdayBot *BirthdayManager) QueueNotificationJob(ctx context.Context, job NotificationJob) error {
+	if err := birthdayBot.repository.DeleteNotificationJobsFinishedBefore(ctx, birthdayBot.clock.Now().Add(-FINISHED_JOB_RETENTION)); err != nil {
+		common.ErrorLogger.Printf("could not delete finished notification jobs due to: %v\n", err)
+	}
+	return birthdayBot.repository.SaveNotificationJob(ctx, job)
+}
+
+// ClaimNotificationJobs hands due jobs to a single notifier instance. Jobs that aren't completed or failed within
+// the visibility timeout can be claimed again, e.g. when the instance was stopped while sending them,
+// unless they were already claimed maxAttempts times - those are marked as dead instead.
+func (birthdayBot *BirthdayManager) ClaimNotificationJobs(ctx context.Context, limit int, visibilityTimeout time.Duration, maxAttempts int) ([]NotificationJob, error) {
+	now := birthdayBot.clock.Now()
+	return birthdayBot.repository.ClaimNotificationJobs(ctx, now, now.Add(visibilityTimeout), limit, maxAttempts)
+}
+
+// CompleteNotificationJob marks the job as done, as long as it wasn't claimed again since the given attempt.
+func (birthdayBot *BirthdayManager) CompleteNotificationJob(ctx context.Context, jobId int64, attempts int) error {
+	completed, err := birthdayBot.repository.CompleteNotificationJob(ctx, jobId, attempts)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// FailNotificationJob gives the job back to the queue or marks it as dead, as long as it wasn't claimed again since the failed attempt.
+func (birthdayBot *BirthdayManager) FailNotificationJob(ctx context.Context, failure JobFailure) error {
+	failed, err := birthdayBot.repository.FailNotificationJob(ctx, failure)
+	if err != nil {
+		return err
+	}
+	if !failed {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// GetNotificationJobs lists jobs with the given status, e.g. the dead ones that need to be looked into.
+func (birthdayBot *BirthdayManager) GetNotificationJobs(ctx context.Context, status JobStatus) ([]NotificationJob, error) {
+	return birthdayBot.repository.GetNotificationJobs(ctx, status)
+}
@@ -2,11 +2,15 @@ package core
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+var ErrMessageNotFound = errors.New("message not found")
+
 type Birthday struct {
 	Date          time.Time
+	Year          int
 	ChatId        int64
 	UserId        int64
 	Username      string
@@ -14,18 +18,136 @@ type Birthday struct {
 	UserLastName  string
 }
 
+type BirthdayDayCount struct {
+	DayOfYear int
+	Count     int
+}
+
 type Repository interface {
 	SaveBirthday(ctx context.Context, birthday Birthday) error
-	GetBirthdayDate(ctx context.Context, chatId int64, userId int64) (*time.Time, error)
+	GetBirthday(ctx context.Context, chatId int64, userId int64) (*Birthday, error)
 	GetNextBirthdays(ctx context.Context, chatId int64) ([]Birthday, error)
 	GetBirthdaysForDate(ctx context.Context, date time.Time) ([]Birthday, error)
+	GetBirthdayCountsPerDay(ctx context.Context, chatId int64) ([]BirthdayDayCount, error)
+	// GetChatBirthdaysBetween returns birthdays between two dates inclusive, ordered by date.
+	GetChatBirthdaysBetween(ctx context.Context, chatId int64, from time.Time, to time.Time) ([]Birthday, error)
 	DeleteBirthday(ctx context.Context, chatId int64, userId int64) error
 	DeleteAllChatBirthdays(ctx context.Context, chatId int64) error
-	DeleteAllUserBirthdays(ctx context.Context, userId int64) error
+	// DeleteAllUserBirthdays returns ids of the chats the birthdays were deleted from.
+	DeleteAllUserBirthdays(ctx context.Context, userId int64) ([]int64, error)
+	SaveBoardMessageId(ctx context.Context, chatId int64, messageId int) error
+	GetBoardMessageId(ctx context.Context, chatId int64) (int, error)
+	GetChatIdsWithBoards(ctx context.Context) ([]int64, error)
+	// DeleteChatSettings removes the board message id and every setting of the chat.
+	DeleteChatSettings(ctx context.Context, chatId int64) error
+	SetDigestEnabled(ctx context.Context, chatId int64, enabled bool) error
+	// GetDigestBirthdaysBetween returns birthdays between two dates inclusive in chats that enabled the monthly digest, ordered by date.
+	GetDigestBirthdaysBetween(ctx context.Context, from time.Time, to time.Time) ([]Birthday, error)
+	SetLeapDayPolicy(ctx context.Context, chatId int64, policy LeapDayPolicy) error
+	GetLeapDayPolicy(ctx context.Context, chatId int64) (LeapDayPolicy, error)
+	GetLeapDayBirthdays(ctx context.Context, policy LeapDayPolicy) ([]Birthday, error)
+	SaveEvent(ctx context.Context, event Event) error
+	GetChatEvents(ctx context.Context, chatId int64) ([]Event, error)
+	GetEventsForDate(ctx context.Context, date time.Time) ([]Event, error)
+	GetLeapDayEvents(ctx context.Context, policy LeapDayPolicy) ([]Event, error)
+	DeleteEvent(ctx context.Context, chatId int64, name string) (bool, error)
+	DeleteAllChatEvents(ctx context.Context, chatId int64) error
+	SetNameDayCountry(ctx context.Context, chatId int64, country string) error
+	GetNameDayCountry(ctx context.Context, chatId int64) (string, error)
+	SaveNameDay(ctx context.Context, nameDay NameDay) error
+	GetNameDaysForNames(ctx context.Context, country string, names []string) ([]NameDay, error)
+	DeleteNameDay(ctx context.Context, chatId int64, userId int64) error
+	DeleteAllChatNameDays(ctx context.Context, chatId int64) error
+	DeleteAllUserNameDays(ctx context.Context, userId int64) error
+	SaveWishContributor(ctx context.Context, userId int64) error
+	// GetWishContributors returns ids of users who started a private chat with the bot and have a birthday or a name day set in the chat.
+	GetWishContributors(ctx context.Context, chatId int64) ([]int64, error)
+	SavePendingWish(ctx context.Context, pendingWish PendingWish) error
+	// GetPendingWishes returns unanswered invitations of the author for birthdays on or after the given date, oldest first.
+	GetPendingWishes(ctx context.Context, authorUserId int64, from time.Time) ([]PendingWish, error)
+	DeletePendingWish(ctx context.Context, pendingWish PendingWish) error
+	SaveWish(ctx context.Context, wish Wish) error
+	GetWishes(ctx context.Context, chatId int64, birthdayUserId int64, date time.Time) ([]Wish, error)
+	// DeleteWishesBefore removes wishes and pending invitations for birthdays before the given date.
+	DeleteWishesBefore(ctx context.Context, date time.Time) error
+	// DeleteAllChatWishes removes the wishes and pending invitations for birthdays in the chat.
+	DeleteAllChatWishes(ctx context.Context, chatId int64) error
+	// DeleteAllUserWishes removes every wish the user sent or received, their pending invitations and their consent to be invited.
+	DeleteAllUserWishes(ctx context.Context, userId int64) error
+	SaveWishlistItem(ctx context.Context, item WishlistItem) error
+	// GetWishlistItems returns the user's global items and the items they added in the chat, oldest first.
+	GetWishlistItems(ctx context.Context, userId int64, chatId int64) ([]WishlistItem, error)
+	// GetAllUserWishlistItems returns the user's items from every chat, oldest first.
+	GetAllUserWishlistItems(ctx context.Context, userId int64) ([]WishlistItem, error)
+	// GetWishlistItem returns nil when there is no item with the given id.
+	GetWishlistItem(ctx context.Context, itemId int64) (*WishlistItem, error)
+	// UpdateWishlistItemClaim changes who claimed the item only if it is still claimed by expectedClaimedBy
+	// and returns whether the item was changed.
+	UpdateWishlistItemClaim(ctx context.Context, itemId int64, expectedClaimedBy int64, claimedBy int64) (bool, error)
+	DeleteWishlistItem(ctx context.Context, itemId int64) error
+	DeleteChatUserWishlistItems(ctx context.Context, chatId int64, userId int64) error
+	DeleteAllChatWishlistItems(ctx context.Context, chatId int64) error
+	// DeleteAllUserWishlistItems removes the user's items and releases every item they claimed.
+	DeleteAllUserWishlistItems(ctx context.Context, userId int64) error
+	// SaveBirthdayVideo keeps one video per user, chat and year, replacing the previous one.
+	SaveBirthdayVideo(ctx context.Context, video BirthdayVideo) error
+	// GetUserBirthdayVideos returns the user's videos from every chat, newest first.
+	GetUserBirthdayVideos(ctx context.Context, userId int64) ([]BirthdayVideo, error)
+	// GetBirthdayVideo returns nil when there is no video with the given id.
+	GetBirthdayVideo(ctx context.Context, videoId int64) (*BirthdayVideo, error)
+	DeleteAllChatBirthdayVideos(ctx context.Context, chatId int64) error
+	// DeleteAllUserBirthdayVideos removes the user's archived videos and the videos cached for their pictures.
+	DeleteAllUserBirthdayVideos(ctx context.Context, userId int64) error
+	// SaveCachedVideo keeps one video per user and picture, replacing the previous one.
+	SaveCachedVideo(ctx context.Context, cachedVideo CachedVideo) error
+	// GetCachedVideoFileId returns an empty string when there is no video for the picture.
+	GetCachedVideoFileId(ctx context.Context, userId int64, pictureUniqueId string) (string, error)
+	// SaveCustomPicture keeps one picture per user and scope, replacing the previous one.
+	SaveCustomPicture(ctx context.Context, picture CustomPicture) error
+	// GetCustomPicture returns the user's picture for the chat, falling back to the global one, or nil when there's none.
+	GetCustomPicture(ctx context.Context, userId int64, chatId int64) (*CustomPicture, error)
+	DeleteCustomPicture(ctx context.Context, userId int64, chatId int64) error
+	DeleteAllChatCustomPictures(ctx context.Context, chatId int64) error
+	DeleteAllUserCustomPictures(ctx context.Context, userId int64) error
+	// SaveDeliveryStep ignores steps that were already recorded.
+	SaveDeliveryStep(ctx context.Context, step DeliveryStep) error
+	GetDeliverySteps(ctx context.Context, query DeliveryQuery) ([]DeliveryStep, error)
+	DeleteAllUserDeliverySteps(ctx context.Context, userId int64) error
+	// GetLastScheduledDate returns a zero time when nothing was scheduled yet.
+	GetLastScheduledDate(ctx context.Context) (time.Time, error)
+	SaveScheduledDate(ctx context.Context, date time.Time) error
+	// SaveNotificationJob ignores a job with a task id that was already saved.
+	SaveNotificationJob(ctx context.Context, job NotificationJob) error
+	// ClaimNotificationJobs marks up to limit due jobs as running until lockedUntil. Jobs locked by other callers are skipped.
+	// Running jobs whose lock expired after maxAttempts claims are marked as dead instead of being claimed again.
+	ClaimNotificationJobs(ctx context.Context, now time.Time, lockedUntil time.Time, limit int, maxAttempts int) ([]NotificationJob, error)
+	// CompleteNotificationJob changes the job only if it's running with the given number of attempts
+	// and returns whether the job was changed.
+	CompleteNotificationJob(ctx context.Context, jobId int64, attempts int) (bool, error)
+	// FailNotificationJob changes the job only if it's running with the number of attempts of the failure
+	// and returns whether the job was changed.
+	FailNotificationJob(ctx context.Context, failure JobFailure) (bool, error)
+	GetNotificationJobs(ctx context.Context, status JobStatus) ([]NotificationJob, error)
+	DeleteNotificationJobsFinishedBefore(ctx context.Context, date time.Time) error
 }
 
 type Telegram interface {
 	SendMessage(ctx context.Context, chatId int64, text string) error
+	SendMessageAndGetId(ctx context.Context, chatId int64, text string) (int, error)
+	// EditMessage returns ErrMessageNotFound when the message was deleted.
+	EditMessage(ctx context.Context, chatId int64, messageId int, text string) error
+	PinMessage(ctx context.Context, chatId int64, messageId int) error
+	UnpinMessage(ctx context.Context, chatId int64, messageId int) error
 	SendReply(ctx context.Context, chatId int64, messageId int, text string) error
+	SendReplyWithButtons(ctx context.Context, chatId int64, messageId int, text string, buttons []InlineButton) error
+	EditMessageWithButtons(ctx context.Context, chatId int64, messageId int, text string, buttons []InlineButton) error
+	// AnswerCallback shows the text only to the user who pressed the button.
+	AnswerCallback(ctx context.Context, callbackId string, text string) error
 	SendReaction(ctx context.Context, chatId int64, messageId int, reaction string) error
+	SendVideoFromFileId(ctx context.Context, chatId int64, fileId string) error
+	IsChatAdmin(ctx context.Context, chatId int64, userId int64) (bool, error)
+}
+
+type Clock interface {
+	Now() time.Time
 }
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+type RateLimitDecision int
+
+const (
+	RATE_LIMIT_ALLOWED RateLimitDecision = iota
+	RATE_LIMIT_WARNED
+	RATE_LIMIT_DROPPED
+)
+
+// RateLimiter allows up to burst requests per user and chat within a sliding window.
+// The first request over the limit is reported as warned, every next one as dropped, until the window frees up.
+type RateLimiter struct {
+	clock         Clock
+	burst         int
+	window        time.Duration
+	mutex         sync.Mutex
+	usages        map[rateLimitKey]*rateLimitUsage
+	lastCleanupAt time.Time
+}
+
+type rateLimitKey struct {
+	chatId int64
+	userId int64
+}
+
+type rateLimitUsage struct {
+	requestTimes []time.Time
+	warned       bool
+}
+
+func NewRateLimiter(clock Clock, burst int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		clock:         clock,
+		burst:         burst,
+		window:        window,
+		usages:        make(map[rateLimitKey]*rateLimitUsage),
+		lastCleanupAt: clock.Now(),
+	}
+}
+
+func (limiter *RateLimiter) Allow(chatId int64, userId int64) RateLimitDecision {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.clock.Now()
+	limiter.cleanUp(now)
+	key := rateLimitKey{chatId: chatId, userId: userId}
+	usage, exists := limiter.usages[key]
+	if !exists {
+		usage = &rateLimitUsage{}
+		limiter.usages[key] = usage
+	}
+	usage.requestTimes = limiter.dropExpired(usage.requestTimes, now)
+	if len(usage.requestTimes) < limiter.burst {
+		usage.requestTimes = append(usage.requestTimes, now)
+		usage.warned = false
+		return RATE_LIMIT_ALLOWED
+	}
+	if !usage.warned {
+		usage.warned = true
+		return RATE_LIMIT_WARNED
+	}
+	return RATE_LIMIT_DROPPED
+}
+
+func (limiter *RateLimiter) dropExpired(requestTimes []time.Time, now time.Time) []time.Time {
+	windowStart := now.Add(-limiter.window)
+	firstValid := 0
+	for firstValid < len(requestTimes) && !requestTimes[firstValid].After(windowStart) {
+		firstValid++
+	}
+	return requestTimes[firstValid:]
+}
+
+func (limiter *RateLimiter) cleanUp(now time.Time) {
+	if now.Sub(limiter.lastCleanupAt) < limiter.window {
+		return
+	}
+	for key, usage := range limiter.usages {
+		if len(limiter.dropExpired(usage.requestTimes, now)) == 0 {
+			delete(limiter.usages, key)
+		}
+	}
+	limiter.lastCleanupAt = now
+}
@@ -0,0 +1,79 @@
+package core_test
+
+import (
+	"sync"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/manager/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate limiter", func() {
+	var clock FakeClock
+	var limiter *core.RateLimiter
+
+	BeforeEach(func() {
+		clock = FakeClock{now: NOW}
+		limiter = core.NewRateLimiter(&clock, 2, time.Minute)
+	})
+
+	It("should allow requests up to the burst", func() {
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_ALLOWED))
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_ALLOWED))
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_WARNED))
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_DROPPED))
+	})
+
+	It("should keep separate limits per chat and user", func() {
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+
+		Expect(limiter.Allow(CHAT_ID_2, USER_ID_1)).To(Equal(core.RATE_LIMIT_ALLOWED))
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_2)).To(Equal(core.RATE_LIMIT_ALLOWED))
+	})
+
+	It("should slide the window", func() {
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+		clock.now = NOW.Add(30 * time.Second)
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_WARNED))
+
+		clock.now = NOW.Add(time.Minute)
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_ALLOWED))
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_WARNED))
+	})
+
+	It("should warn again after being allowed", func() {
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+		clock.now = NOW.Add(2 * time.Minute)
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+		limiter.Allow(CHAT_ID_1, USER_ID_1)
+
+		Expect(limiter.Allow(CHAT_ID_1, USER_ID_1)).To(Equal(core.RATE_LIMIT_WARNED))
+	})
+
+	It("should be safe to use concurrently", func() {
+		var waitGroup sync.WaitGroup
+		decisions := make(chan core.RateLimitDecision, 50)
+		for range 50 {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				decisions <- limiter.Allow(CHAT_ID_1, USER_ID_1)
+			}()
+		}
+		waitGroup.Wait()
+		close(decisions)
+
+		counts := map[core.RateLimitDecision]int{}
+		for decision := range decisions {
+			counts[decision]++
+		}
+		Expect(counts[core.RATE_LIMIT_ALLOWED]).To(Equal(2))
+		Expect(counts[core.RATE_LIMIT_WARNED]).To(Equal(1))
+		Expect(counts[core.RATE_LIMIT_DROPPED]).To(Equal(47))
+	})
+})
@@ -0,0 +1,16 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// GetLastScheduledDate returns the last date the notifier scheduled notifications for, or a zero time when it never did.
+// The notifier catches up on the days missed since then.
+func (birthdayBot *BirthdayManager) GetLastScheduledDate(ctx context.Context) (time.Time, error) {
+	return birthdayBot.repository.GetLastScheduledDate(ctx)
+}
+
+func (birthdayBot *BirthdayManager) SaveScheduledDate(ctx context.Context, date time.Time) error {
+	return birthdayBot.repository.SaveScheduledDate(ctx, date)
+}
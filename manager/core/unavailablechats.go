@@ -0,0 +1,11 @@
+package core
+
+import (
+	"context"
+)
+
+// RemoveUnavailableChat deletes the data of a chat the bot can no longer post to, e.g. after it was kicked,
+// the same way as when the bot leaves it, so the chat isn't notified again.
+func (birthdayBot *BirthdayManager) RemoveUnavailableChat(ctx context.Context, chatId int64) error {
+	return birthdayBot.deleteAllChatData(ctx, chatId)
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	CALLBACK_DATA_VIDEO = "video:"
+)
+
+// BirthdayVideo is a birthday video the notifier sent to a chat, archived by its Telegram fileId.
+type BirthdayVideo struct {
+	Id     int64
+	ChatId int64
+	UserId int64
+	Year   int
+	FileId string
+}
+
+// CachedVideo is a birthday video uploaded for one of the user's pictures, identified by the picture's unique file id.
+// A user can have a different picture in every chat, so each of them keeps its own video.
+type CachedVideo struct {
+	UserId          int64
+	PictureUniqueId string
+	FileId          string
+}
+
+// GetCachedVideoFileId returns the fileId of the video generated for the user's picture or an empty string when there's none.
+func (birthdayBot *BirthdayManager) GetCachedVideoFileId(ctx context.Context, userId int64, pictureUniqueId string) (string, error) {
+	return birthdayBot.repository.GetCachedVideoFileId(ctx, userId, pictureUniqueId)
+}
+
+func (birthdayBot *BirthdayManager) CacheVideo(ctx context.Context, cachedVideo CachedVideo) error {
+	return birthdayBot.repository.SaveCachedVideo(ctx, cachedVideo)
+}
+
+// SaveBirthdayVideo archives a birthday video sent by the notifier so the user can watch it again with /myvideos.
+func (birthdayBot *BirthdayManager) SaveBirthdayVideo(ctx context.Context, video BirthdayVideo) error {
+	return birthdayBot.repository.SaveBirthdayVideo(ctx, video)
+}
+
+func (birthdayBot *BirthdayManager) listBirthdayVideos(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	videos, err := birthdayBot.repository.GetUserBirthdayVideos(ctx, update.Message.From.ID)
+	if err != nil {
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_GET_FAILURE)
+	}
+	if len(videos) == 0 {
+		return birthdayBot.telegram.SendMessage(ctx, chatId, MESSAGE_NO_VIDEOS)
+	}
+	lines := make([]string, len(videos))
+	buttons := make([]InlineButton, len(videos))
+	for index, video := range videos {
+		lines[index] = fmt.Sprintf(MESSAGE_VIDEO_LINE, index+1, video.Year)
+		buttons[index] = InlineButton{
+			Text: fmt.Sprintf(VIDEO_BUTTON, index+1),
+			Data: fmt.Sprintf("%s%d", CALLBACK_DATA_VIDEO, video.Id),
+		}
+	}
+	text := fmt.Sprintf(MESSAGE_VIDEOS, strings.Join(lines, "\n"))
+	return birthdayBot.telegram.SendReplyWithButtons(ctx, chatId, update.Message.ID, text, buttons)
+}
+
+// resendBirthdayVideo sends an archived video to the private chat of the member who pressed its button.
+// Members can only get their own videos.
+func (birthdayBot *BirthdayManager) resendBirthdayVideo(ctx context.Context, callbackQuery *models.CallbackQuery, rawVideoId string) error {
+	videoId, err := strconv.ParseInt(rawVideoId, 10, 64)
+	if err != nil {
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_VIDEO_GONE)
+	}
+	video, err := birthdayBot.repository.GetBirthdayVideo(ctx, videoId)
+	if err != nil {
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_CALLBACK_FAILURE)
+	}
+	if video == nil || video.UserId != callbackQuery.From.ID {
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_VIDEO_GONE)
+	}
+	if err := birthdayBot.telegram.SendVideoFromFileId(ctx, callbackQuery.From.ID, video.FileId); err != nil {
+		common.ErrorLogger.Printf("could not resend birthday video: %v due to: %v\n", videoId, err)
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_CALLBACK_FAILURE)
+	}
+	return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, "")
+}
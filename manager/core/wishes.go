@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	WISH_INVITATION_DAYS_AHEAD = 3
+	MAX_WISH_LENGTH            = 1000
+)
+
+// PendingWish is an invitation to send a wish that the author hasn't answered yet.
+type PendingWish struct {
+	AuthorUserId       int64
+	ChatId             int64
+	BirthdayUserId     int64
+	BirthdayPersonName string
+	Date               time.Time
+}
+
+// Wish is a text or a voice message collected from a member to be delivered on someone's birthday.
+type Wish struct {
+	ChatId          int64
+	BirthdayUserId  int64
+	Date            time.Time
+	AuthorUserId    int64
+	AuthorUsername  string
+	AuthorFirstName string
+	AuthorLastName  string
+	Text            string
+	VoiceFileId     string
+}
+
+type CollectedWish struct {
+	AuthorName  string
+	Text        string
+	VoiceFileId string
+}
+
+// SendWishInvitations invites members who started a private chat with the bot to send a wish to everyone
+// celebrating their birthday WISH_INVITATION_DAYS_AHEAD days from now in a chat they share.
+// Invitations are answered one at a time, so a member with an unanswered invitation gets the next one only after answering.
+func (birthdayBot *BirthdayManager) SendWishInvitations(ctx context.Context) error {
+	today := getStartOfDay(birthdayBot.clock.Now())
+	if err := birthdayBot.repository.DeleteWishesBefore(ctx, today); err != nil {
+		return err
+	}
+	date := today.AddDate(0, 0, WISH_INVITATION_DAYS_AHEAD)
+	birthdayPeople, err := birthdayBot.GetBirthdays(ctx, date)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, birthdayPerson := range birthdayPeople {
+		if err := birthdayBot.inviteToWish(ctx, birthdayPerson, date, today); err != nil {
+			errs = append(errs, fmt.Errorf("could not invite to wish in chat: %v due to: %w", birthdayPerson.ChatId, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (birthdayBot *BirthdayManager) inviteToWish(ctx context.Context, birthdayPerson BirthdayPerson, date time.Time, today time.Time) error {
+	authorUserIds, err := birthdayBot.repository.GetWishContributors(ctx, birthdayPerson.ChatId)
+	if err != nil {
+		return err
+	}
+	for _, authorUserId := range authorUserIds {
+		if authorUserId == birthdayPerson.UserId {
+			continue
+		}
+		pendingWishes, err := birthdayBot.repository.GetPendingWishes(ctx, authorUserId, today)
+		if err != nil {
+			return err
+		}
+		pendingWish := PendingWish{
+			AuthorUserId:       authorUserId,
+			ChatId:             birthdayPerson.ChatId,
+			BirthdayUserId:     birthdayPerson.UserId,
+			BirthdayPersonName: birthdayPerson.Name,
+			Date:               date,
+		}
+		if err := birthdayBot.repository.SavePendingWish(ctx, pendingWish); err != nil {
+			return err
+		}
+		if len(pendingWishes) > 0 {
+			continue
+		}
+		if err := birthdayBot.sendWishInvitation(ctx, pendingWish); err != nil {
+			common.ErrorLogger.Printf("could not send wish invitation to user: %v due to: %v\n", authorUserId, err)
+		}
+	}
+	return nil
+}
+
+func (birthdayBot *BirthdayManager) sendWishInvitation(ctx context.Context, pendingWish PendingWish) error {
+	text := fmt.Sprintf(MESSAGE_WISH_INVITATION, pendingWish.BirthdayPersonName, formatDateForOutput(pendingWish.Date)) +
+		birthdayBot.createWishlistInvitationSection(ctx, pendingWish)
+	return birthdayBot.telegram.SendMessage(ctx, pendingWish.AuthorUserId, text)
+}
+
+func (birthdayBot *BirthdayManager) startPrivateChat(ctx context.Context, update *models.Update) error {
+	if err := birthdayBot.repository.SaveWishContributor(ctx, update.Message.From.ID); err != nil {
+		common.ErrorLogger.Printf("could not save wish contributor: %v due to: %v\n", update.Message.From.ID, err)
+	}
+	return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_FULL_HELP)
+}
+
+// handlePrivateChatMessage treats a message that isn't a command as an answer to the oldest unanswered wish invitation.
+func (birthdayBot *BirthdayManager) handlePrivateChatMessage(ctx context.Context, update *models.Update) error {
+	author := update.Message.From
+	pendingWishes, err := birthdayBot.repository.GetPendingWishes(ctx, author.ID, getStartOfDay(birthdayBot.clock.Now()))
+	if err != nil {
+		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_GET_FAILURE)
+	}
+	if len(pendingWishes) == 0 {
+		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_SHORT_HELP)
+	}
+
+	pendingWish := pendingWishes[0]
+	wish := Wish{
+		ChatId:          pendingWish.ChatId,
+		BirthdayUserId:  pendingWish.BirthdayUserId,
+		Date:            pendingWish.Date,
+		AuthorUserId:    author.ID,
+		AuthorUsername:  author.Username,
+		AuthorFirstName: author.FirstName,
+		AuthorLastName:  author.LastName,
+	}
+	if update.Message.Voice != nil {
+		wish.VoiceFileId = update.Message.Voice.FileID
+	} else if text := strings.TrimSpace(update.Message.Text); len(text) > 0 {
+		wish.Text = text
+	} else {
+		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_WISH_UNSUPPORTED)
+	}
+	if len([]rune(wish.Text)) > MAX_WISH_LENGTH {
+		return birthdayBot.sendPrivateChatMessage(ctx, update, fmt.Sprintf(MESSAGE_WISH_TOO_LONG, MAX_WISH_LENGTH))
+	}
+
+	if err := birthdayBot.repository.SaveWish(ctx, wish); err != nil {
+		common.ErrorLogger.Printf("could not save wish: %v due to: %v\n", wish, err)
+		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.completePendingWish(ctx, update, pendingWishes, MESSAGE_WISH_SAVED)
+}
+
+func (birthdayBot *BirthdayManager) skipWish(ctx context.Context, update *models.Update) error {
+	pendingWishes, err := birthdayBot.repository.GetPendingWishes(ctx, update.Message.From.ID, getStartOfDay(birthdayBot.clock.Now()))
+	if err != nil {
+		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_GET_FAILURE)
+	}
+	if len(pendingWishes) == 0 {
+		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_NO_PENDING_WISHES)
+	}
+	return birthdayBot.completePendingWish(ctx, update, pendingWishes, MESSAGE_WISH_SKIPPED)
+}
+
+func (birthdayBot *BirthdayManager) completePendingWish(ctx context.Context, update *models.Update, pendingWishes []PendingWish, message string) error {
+	if err := birthdayBot.repository.DeletePendingWish(ctx, pendingWishes[0]); err != nil {
+		common.ErrorLogger.Printf("could not delete pending wish: %v due to: %v\n", pendingWishes[0], err)
+		return birthdayBot.sendPrivateChatMessage(ctx, update, MESSAGE_SAVE_FAILURE)
+	}
+	if err := birthdayBot.sendPrivateChatMessage(ctx, update, message); err != nil {
+		return err
+	}
+	if len(pendingWishes) > 1 {
+		return birthdayBot.sendWishInvitation(ctx, pendingWishes[1])
+	}
+	return nil
+}
+
+// GetWishes returns wishes collected for the member of the chat celebrating their birthday on the given date.
+func (birthdayBot *BirthdayManager) GetWishes(ctx context.Context, chatId int64, userId int64, date time.Time) ([]CollectedWish, error) {
+	wishes, err := birthdayBot.repository.GetWishes(ctx, chatId, userId, date)
+	if err != nil {
+		return nil, err
+	}
+	collectedWishes := make([]CollectedWish, len(wishes))
+	for index, wish := range wishes {
+		collectedWishes[index] = CollectedWish{
+			AuthorName: createBirthdayPersonName(Birthday{
+				UserId:        wish.AuthorUserId,
+				Username:      wish.AuthorUsername,
+				UserFirstName: wish.AuthorFirstName,
+				UserLastName:  wish.AuthorLastName,
+			}),
+			Text:        wish.Text,
+			VoiceFileId: wish.VoiceFileId,
+		}
+	}
+	return collectedWishes, nil
+}
+
+func getStartOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
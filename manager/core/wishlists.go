@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	WISHLIST_ACTION_ADD            = "add"
+	WISHLIST_ACTION_REMOVE         = "remove"
+	WISHLIST_SCOPE_GLOBAL    int64 = 0
+	NOT_CLAIMED              int64 = 0
+	MAX_WISHLIST_ITEMS             = 20
+	MAX_WISHLIST_ITEM_LENGTH       = 128
+	CALLBACK_DATA_WISHLIST         = "wishlist:"
+)
+
+// WishlistItem is a gift a member wishes for. Items added in a private chat are global and visible in every chat,
+// items added in a group are visible only there.
+// The member who claimed the item is never shown to its owner.
+type WishlistItem struct {
+	Id            int64
+	UserId        int64
+	ChatId        int64
+	Text          string
+	ClaimedBy     int64
+	Username      string
+	UserFirstName string
+	UserLastName  string
+}
+
+type InlineButton struct {
+	Text string
+	Data string
+}
+
+func (birthdayBot *BirthdayManager) handleWishlist(ctx context.Context, update *models.Update) error {
+	messageParts := strings.Fields(getCommandText(update.Message))
+	if len(messageParts) <= 1 {
+		return birthdayBot.showWishlist(ctx, update)
+	}
+	switch strings.ToLower(messageParts[1]) {
+	case WISHLIST_ACTION_ADD:
+		return birthdayBot.addWishlistItem(ctx, update, strings.Join(messageParts[2:], " "))
+	case WISHLIST_ACTION_REMOVE:
+		return birthdayBot.removeWishlistItem(ctx, update, messageParts[2:])
+	default:
+		return birthdayBot.telegram.SendReply(ctx, update.Message.Chat.ID, update.Message.ID, MESSAGE_WISHLIST_WRONG_FORMAT)
+	}
+}
+
+func (birthdayBot *BirthdayManager) addWishlistItem(ctx context.Context, update *models.Update, text string) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+	user := update.Message.From
+
+	if len(text) == 0 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_WISHLIST_WRONG_FORMAT)
+	}
+	if len([]rune(text)) > MAX_WISHLIST_ITEM_LENGTH {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, fmt.Sprintf(MESSAGE_WISHLIST_ITEM_TOO_LONG, MAX_WISHLIST_ITEM_LENGTH))
+	}
+	items, err := birthdayBot.getOwnWishlistItems(ctx, update)
+	if err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	if len(items) >= MAX_WISHLIST_ITEMS {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, fmt.Sprintf(MESSAGE_WISHLIST_FULL, MAX_WISHLIST_ITEMS))
+	}
+
+	item := WishlistItem{
+		UserId:        user.ID,
+		ChatId:        getWishlistScope(update),
+		Text:          text,
+		Username:      user.Username,
+		UserFirstName: user.FirstName,
+		UserLastName:  user.LastName,
+	}
+	if err := birthdayBot.repository.SaveWishlistItem(ctx, item); err != nil {
+		common.ErrorLogger.Printf("could not save wishlist item: %v due to: %v\n", item, err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SAVE_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+// removeWishlistItem removes the item with the given number on the list shown by /wishlist in the same chat.
+func (birthdayBot *BirthdayManager) removeWishlistItem(ctx context.Context, update *models.Update, arguments []string) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	if len(arguments) != 1 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_WISHLIST_WRONG_FORMAT)
+	}
+	number, err := strconv.Atoi(arguments[0])
+	if err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_WISHLIST_WRONG_FORMAT)
+	}
+	items, err := birthdayBot.getOwnWishlistItems(ctx, update)
+	if err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	if number < 1 || number > len(items) {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_WISHLIST_ITEM_NOT_FOUND)
+	}
+
+	if err := birthdayBot.repository.DeleteWishlistItem(ctx, items[number-1].Id); err != nil {
+		common.ErrorLogger.Printf("could not delete wishlist item: %v due to: %v\n", items[number-1], err)
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_UNSET_FAILURE)
+	}
+	return birthdayBot.telegram.SendReaction(ctx, chatId, messageId, REACTION_THUMBS_UP)
+}
+
+// showWishlist shows the author's own wishlist, or in a group, the wishlist of the member the author replied to
+// together with buttons to claim its items.
+func (birthdayBot *BirthdayManager) showWishlist(ctx context.Context, update *models.Update) error {
+	chatId := update.Message.Chat.ID
+	messageId := update.Message.ID
+
+	if isGroupUpdate(update) && isReplyToAnotherUser(update) {
+		subject := update.Message.ReplyToMessage.From
+		if subject.ID == birthdayBot.id || subject.IsBot {
+			return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_SUBJECT_IS_BOT)
+		}
+		items, err := birthdayBot.repository.GetWishlistItems(ctx, subject.ID, chatId)
+		if err != nil {
+			return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+		}
+		if len(items) == 0 {
+			return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_EMPTY_WISHLIST)
+		}
+		text, buttons := createWishlistMessage(items)
+		return birthdayBot.telegram.SendReplyWithButtons(ctx, chatId, messageId, text, buttons)
+	}
+
+	items, err := birthdayBot.getOwnWishlistItems(ctx, update)
+	if err != nil {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_GET_FAILURE)
+	}
+	if len(items) == 0 {
+		return birthdayBot.telegram.SendReply(ctx, chatId, messageId, MESSAGE_NO_OWN_WISHLIST_ITEMS)
+	}
+	return birthdayBot.telegram.SendReply(ctx, chatId, messageId, createOwnWishlistMessage(items))
+}
+
+func (birthdayBot *BirthdayManager) getOwnWishlistItems(ctx context.Context, update *models.Update) ([]WishlistItem, error) {
+	userId := update.Message.From.ID
+	var items []WishlistItem
+	var err error
+	if isPrivateChatUpdate(update) {
+		items, err = birthdayBot.repository.GetAllUserWishlistItems(ctx, userId)
+	} else {
+		items, err = birthdayBot.repository.GetWishlistItems(ctx, userId, update.Message.Chat.ID)
+	}
+	if err != nil {
+		common.ErrorLogger.Printf("could not get wishlist of user: %v due to: %v\n", userId, err)
+	}
+	return items, err
+}
+
+func getWishlistScope(update *models.Update) int64 {
+	if isPrivateChatUpdate(update) {
+		return WISHLIST_SCOPE_GLOBAL
+	}
+	return update.Message.Chat.ID
+}
+
+func createOwnWishlistMessage(items []WishlistItem) string {
+	lines := make([]string, len(items))
+	for index, item := range items {
+		lines[index] = fmt.Sprintf(MESSAGE_WISHLIST_LINE, index+1, html.EscapeString(item.Text))
+		if item.ChatId != WISHLIST_SCOPE_GLOBAL {
+			lines[index] += MESSAGE_WISHLIST_GROUP_ONLY
+		}
+	}
+	return fmt.Sprintf(MESSAGE_OWN_WISHLIST, strings.Join(lines, "\n"))
+}
+
+func createWishlistMessage(items []WishlistItem) (string, []InlineButton) {
+	lines := make([]string, len(items))
+	buttons := make([]InlineButton, len(items))
+	for index, item := range items {
+		lines[index] = fmt.Sprintf(MESSAGE_WISHLIST_LINE, index+1, html.EscapeString(item.Text))
+		buttonText := fmt.Sprintf(WISHLIST_BUTTON_CLAIM, index+1)
+		if item.ClaimedBy != NOT_CLAIMED {
+			lines[index] += MESSAGE_WISHLIST_CLAIMED_SUFFIX
+			buttonText = fmt.Sprintf(WISHLIST_BUTTON_CLAIMED, index+1)
+		}
+		buttons[index] = InlineButton{Text: buttonText, Data: fmt.Sprintf("%s%d", CALLBACK_DATA_WISHLIST, item.Id)}
+	}
+	owner := createBirthdayPersonName(Birthday{
+		UserId:        items[0].UserId,
+		Username:      items[0].Username,
+		UserFirstName: items[0].UserFirstName,
+		UserLastName:  items[0].UserLastName,
+	})
+	return fmt.Sprintf(MESSAGE_WISHLIST, owner, strings.Join(lines, "\n")), buttons
+}
+
+func (birthdayBot *BirthdayManager) handleCallbackQuery(ctx context.Context, callbackQuery *models.CallbackQuery) error {
+	if itemId, isWishlistCallback := strings.CutPrefix(callbackQuery.Data, CALLBACK_DATA_WISHLIST); isWishlistCallback {
+		return birthdayBot.toggleWishlistItemClaim(ctx, callbackQuery, itemId)
+	}
+	if videoId, isVideoCallback := strings.CutPrefix(callbackQuery.Data, CALLBACK_DATA_VIDEO); isVideoCallback {
+		return birthdayBot.resendBirthdayVideo(ctx, callbackQuery, videoId)
+	}
+	return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, "")
+}
+
+// toggleWishlistItemClaim claims the item for the member who pressed its button, or unclaims it when they already claimed it.
+// Only the member who pressed the button is told the outcome, so the owner never learns who claimed what.
+func (birthdayBot *BirthdayManager) toggleWishlistItemClaim(ctx context.Context, callbackQuery *models.CallbackQuery, rawItemId string) error {
+	message := callbackQuery.Message.Message
+	itemId, err := strconv.ParseInt(rawItemId, 10, 64)
+	if err != nil || message == nil {
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_WISHLIST_ITEM_GONE)
+	}
+	item, err := birthdayBot.repository.GetWishlistItem(ctx, itemId)
+	if err != nil {
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_CALLBACK_FAILURE)
+	}
+	if item == nil {
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_WISHLIST_ITEM_GONE)
+	}
+
+	presserId := callbackQuery.From.ID
+	claimedBy, answer := presserId, MESSAGE_WISHLIST_ITEM_CLAIMED
+	switch item.ClaimedBy {
+	case presserId:
+		claimedBy, answer = NOT_CLAIMED, MESSAGE_WISHLIST_ITEM_UNCLAIMED
+	case NOT_CLAIMED:
+		if item.UserId == presserId {
+			return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_WISHLIST_OWN_ITEM)
+		}
+	default:
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_WISHLIST_ITEM_TAKEN)
+	}
+	isChanged, err := birthdayBot.repository.UpdateWishlistItemClaim(ctx, itemId, item.ClaimedBy, claimedBy)
+	if err != nil {
+		common.ErrorLogger.Printf("could not update claim of wishlist item: %v due to: %v\n", itemId, err)
+		return birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, MESSAGE_CALLBACK_FAILURE)
+	}
+	if !isChanged {
+		answer = MESSAGE_WISHLIST_ITEM_TAKEN
+	}
+	if err := birthdayBot.telegram.AnswerCallback(ctx, callbackQuery.ID, answer); err != nil {
+		return err
+	}
+	return birthdayBot.refreshWishlistMessage(ctx, message.Chat.ID, message.ID, item.UserId)
+}
+
+func (birthdayBot *BirthdayManager) refreshWishlistMessage(ctx context.Context, chatId int64, messageId int, userId int64) error {
+	items, err := birthdayBot.repository.GetWishlistItems(ctx, userId, chatId)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return birthdayBot.telegram.EditMessage(ctx, chatId, messageId, MESSAGE_EMPTY_WISHLIST)
+	}
+	text, buttons := createWishlistMessage(items)
+	return birthdayBot.telegram.EditMessageWithButtons(ctx, chatId, messageId, text, buttons)
+}
+
+func (birthdayBot *BirthdayManager) createWishlistInvitationSection(ctx context.Context, pendingWish PendingWish) string {
+	items, err := birthdayBot.repository.GetWishlistItems(ctx, pendingWish.BirthdayUserId, pendingWish.ChatId)
+	if err != nil {
+		common.ErrorLogger.Printf("could not get wishlist of user: %v due to: %v\n", pendingWish.BirthdayUserId, err)
+		return ""
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	lines := make([]string, len(items))
+	for index, item := range items {
+		lines[index] = fmt.Sprintf(MESSAGE_WISHLIST_LINE, index+1, html.EscapeString(item.Text))
+		if item.ClaimedBy != NOT_CLAIMED {
+			lines[index] += MESSAGE_WISHLIST_CLAIMED_SUFFIX
+		}
+	}
+	return fmt.Sprintf(MESSAGE_WISH_INVITATION_WISHLIST, strings.Join(lines, "\n"))
+}
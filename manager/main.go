@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,12 +24,19 @@ import (
 
 var birthdayManager *core.BirthdayManager
 
-const REQUEST_PARAM_DATE_LAYOUT = "2006-01-02"
+// apiSecret authenticates the notifier and Cloud Scheduler to the manager's endpoints, only the Telegram webhook is public.
+var apiSecret string
+
+const (
+	DEFAULT_RATE_LIMIT_BURST    = 5
+	DEFAULT_RATE_LIMIT_WINDOW_S = 60
+)
 
 func main() {
 	databaseUrl := os.Getenv("DATABASE_URL")
 	token := os.Getenv("BOT_TOKEN")
 	port := os.Getenv("PORT")
+	apiSecret = os.Getenv("API_SECRET")
 	ctx := context.Background()
 	telegramBot, err := telegram.New(token)
 	if err != nil {
@@ -35,7 +45,23 @@ func main() {
 	birthdayManager = createManager(ctx, databaseUrl, telegramBot)
 	go setWebhook(ctx, telegramBot, token)
 	http.HandleFunc(fmt.Sprintf("/%s", token), HandleUpdate)
-	http.HandleFunc("/birthdays", GetBirthdays)
+	http.HandleFunc("/birthdays", authenticated(GetBirthdays))
+	http.HandleFunc("/boards", authenticated(RefreshBoards))
+	http.HandleFunc("/digests", authenticated(GetDigestBirthdays))
+	http.HandleFunc("/events", authenticated(GetEvents))
+	http.HandleFunc("/namedays", authenticated(GetNameDays))
+	http.HandleFunc("/wishes", authenticated(GetWishes))
+	http.HandleFunc("/wishinvitations", authenticated(SendWishInvitations))
+	http.HandleFunc("/videos", authenticated(SaveBirthdayVideo))
+	http.HandleFunc("/videocache", authenticated(HandleVideoCache))
+	http.HandleFunc("/custompictures", authenticated(GetCustomPicture))
+	http.HandleFunc("/deliveries", authenticated(HandleDeliveries))
+	http.HandleFunc("/scheduleddates", authenticated(HandleScheduledDates))
+	http.HandleFunc("/jobs", authenticated(HandleNotificationJobs))
+	http.HandleFunc("/jobs/claim", authenticated(ClaimNotificationJobs))
+	http.HandleFunc("/jobs/complete", authenticated(CompleteNotificationJob))
+	http.HandleFunc("/jobs/fail", authenticated(FailNotificationJob))
+	http.HandleFunc("/unavailablechats", authenticated(RemoveUnavailableChat))
 	err = http.ListenAndServe(fmt.Sprintf(":%v", port), nil)
 	if err != nil {
 		log.Fatalf("Failed to start an http server due to: %v\n", err)
@@ -53,8 +79,26 @@ func createManager(ctx context.Context, databaseUrl string, telegramBot *telegra
 	}
 	log.Printf("Fetched bot profile: %v\n", botUser)
 	botWrapper := adapters.NewTelegramWrapper(ctx, telegramBot)
-	repository := adapters.NewSqlRepositoryAdapter(db, &common.SystemClock{})
-	return core.NewBirthdayManager(repository, botWrapper, botUser.ID)
+	clock := &common.SystemClock{}
+	repository := adapters.NewSqlRepositoryAdapter(db, clock)
+	rateLimiter := core.NewRateLimiter(
+		clock,
+		getIntEnv("RATE_LIMIT_BURST", DEFAULT_RATE_LIMIT_BURST),
+		time.Duration(getIntEnv("RATE_LIMIT_WINDOW_S", DEFAULT_RATE_LIMIT_WINDOW_S))*time.Second,
+	)
+	return core.NewBirthdayManager(repository, botWrapper, rateLimiter, clock, botUser.ID)
+}
+
+func getIntEnv(name string, defaultValue int) int {
+	value, present := os.LookupEnv(name)
+	if !present {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("'%v' environment variable is not an int\n", name)
+	}
+	return intValue
 }
 
 func setWebhook(ctx context.Context, bot *telegram.Bot, telegramToken string) {
@@ -88,15 +132,23 @@ func HandleUpdate(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetBirthdays(w http.ResponseWriter, r *http.Request) {
+	handleBirthdaysRequest(w, r, birthdayManager.GetBirthdays)
+}
+
+func GetDigestBirthdays(w http.ResponseWriter, r *http.Request) {
+	handleBirthdaysRequest(w, r, birthdayManager.GetDigestBirthdays)
+}
+
+func handleBirthdaysRequest(w http.ResponseWriter, r *http.Request, getBirthdays func(context.Context, time.Time) ([]core.BirthdayPerson, error)) {
 	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
 	dateString := r.URL.Query().Get("date")
-	date, err := time.Parse(REQUEST_PARAM_DATE_LAYOUT, dateString)
+	date, err := time.Parse(common.DATE_LAYOUT, dateString)
 	if err != nil {
 		common.ErrorLogger.Printf("Could not decode date (%v): %v\n", dateString, err)
 		w.WriteHeader(400)
 		return
 	}
-	birthdays, err := birthdayManager.GetBirthdays(r.Context(), date)
+	birthdays, err := getBirthdays(r.Context(), date)
 	if err != nil {
 		common.ErrorLogger.Printf("Error getting birthdays: %v\n", err)
 		w.WriteHeader(500)
@@ -117,14 +169,640 @@ func GetBirthdays(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func GetEvents(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	dateString := r.URL.Query().Get("date")
+	date, err := time.Parse(common.DATE_LAYOUT, dateString)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode date (%v): %v\n", dateString, err)
+		w.WriteHeader(400)
+		return
+	}
+	events, err := birthdayManager.GetEvents(r.Context(), date)
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting events: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	log.Printf("Returning response: %v\n", events)
+	responseBytes, err := json.Marshal(common.EventsJson{Events: mapEvents(events)})
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling events response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func GetNameDays(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	dateString := r.URL.Query().Get("date")
+	date, err := time.Parse(common.DATE_LAYOUT, dateString)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode date (%v): %v\n", dateString, err)
+		w.WriteHeader(400)
+		return
+	}
+	nameDays, err := birthdayManager.GetNameDays(r.Context(), date)
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting name days: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	log.Printf("Returning response: %v\n", nameDays)
+	responseBytes, err := json.Marshal(common.NameDaysJson{NameDays: mapNameDays(nameDays)})
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling name days response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func GetWishes(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	query := r.URL.Query()
+	chatId, chatIdErr := strconv.ParseInt(query.Get("chatId"), 10, 64)
+	userId, userIdErr := strconv.ParseInt(query.Get("userId"), 10, 64)
+	date, dateErr := time.Parse(common.DATE_LAYOUT, query.Get("date"))
+	if err := errors.Join(chatIdErr, userIdErr, dateErr); err != nil {
+		common.ErrorLogger.Printf("Could not decode wishes query (%v): %v\n", r.URL.RawQuery, err)
+		w.WriteHeader(400)
+		return
+	}
+	wishes, err := birthdayManager.GetWishes(r.Context(), chatId, userId, date)
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting wishes: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	log.Printf("Returning response: %v\n", wishes)
+	responseBytes, err := json.Marshal(common.WishesJson{Wishes: mapWishes(wishes)})
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling wishes response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func SendWishInvitations(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if err := birthdayManager.SendWishInvitations(r.Context()); err != nil {
+		common.ErrorLogger.Printf("Error sending wish invitations: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func SaveBirthdayVideo(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	videoJson := common.VideoJson{}
+	if err := json.NewDecoder(r.Body).Decode(&videoJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode video: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	video := core.BirthdayVideo{
+		ChatId: videoJson.ChatId,
+		UserId: videoJson.UserId,
+		Year:   videoJson.Year,
+		FileId: videoJson.FileId,
+	}
+	if err := birthdayManager.SaveBirthdayVideo(r.Context(), video); err != nil {
+		common.ErrorLogger.Printf("Error saving birthday video: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func HandleVideoCache(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	switch r.Method {
+	case http.MethodGet:
+		getCachedVideo(w, r)
+	case http.MethodPost:
+		cacheVideo(w, r)
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+func getCachedVideo(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userId, err := strconv.ParseInt(query.Get("userId"), 10, 64)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode cached video query (%v): %v\n", r.URL.RawQuery, err)
+		w.WriteHeader(400)
+		return
+	}
+	pictureUniqueId := query.Get("pictureUniqueId")
+	fileId, err := birthdayManager.GetCachedVideoFileId(r.Context(), userId, pictureUniqueId)
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting cached video: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	responseBytes, err := json.Marshal(common.CachedVideoJson{UserId: userId, PictureUniqueId: pictureUniqueId, FileId: fileId})
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling cached video response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func cacheVideo(w http.ResponseWriter, r *http.Request) {
+	cachedVideoJson := common.CachedVideoJson{}
+	if err := json.NewDecoder(r.Body).Decode(&cachedVideoJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode cached video: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	if err := birthdayManager.CacheVideo(r.Context(), core.CachedVideo(cachedVideoJson)); err != nil {
+		common.ErrorLogger.Printf("Error caching video: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func GetCustomPicture(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if r.Method != http.MethodGet {
+		w.WriteHeader(405)
+		return
+	}
+	query := r.URL.Query()
+	chatId, err := strconv.ParseInt(query.Get("chatId"), 10, 64)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode custom picture query (%v): %v\n", r.URL.RawQuery, err)
+		w.WriteHeader(400)
+		return
+	}
+	userId, err := strconv.ParseInt(query.Get("userId"), 10, 64)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode custom picture query (%v): %v\n", r.URL.RawQuery, err)
+		w.WriteHeader(400)
+		return
+	}
+	picture, err := birthdayManager.GetCustomPicture(r.Context(), chatId, userId)
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting custom picture: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	pictureJson := common.CustomPictureJson{UserId: userId, ChatId: chatId}
+	if picture != nil {
+		pictureJson.FileId = picture.FileId
+		pictureJson.FileUniqueId = picture.FileUniqueId
+	}
+	responseBytes, err := json.Marshal(pictureJson)
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling custom picture response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func HandleDeliveries(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	switch r.Method {
+	case http.MethodGet:
+		getDeliverySteps(w, r)
+	case http.MethodPost:
+		completeDeliveryStep(w, r)
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+// getDeliverySteps returns the steps of notifications sent on the given date.
+// The chatId, userId and kind parameters are optional and narrow down the results, e.g. when diagnosing a missing notification.
+func getDeliverySteps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	date, err := time.Parse(common.DATE_LAYOUT, query.Get("date"))
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode deliveries query (%v): %v\n", r.URL.RawQuery, err)
+		w.WriteHeader(400)
+		return
+	}
+	chatId, chatIdErr := parseOptionalInt(query.Get("chatId"))
+	userId, userIdErr := parseOptionalInt(query.Get("userId"))
+	if err := errors.Join(chatIdErr, userIdErr); err != nil {
+		common.ErrorLogger.Printf("Could not decode deliveries query (%v): %v\n", r.URL.RawQuery, err)
+		w.WriteHeader(400)
+		return
+	}
+	deliveryQuery := core.DeliveryQuery{Date: date, ChatId: chatId, UserId: userId, Kind: query.Get("kind")}
+	steps, err := birthdayManager.GetDeliverySteps(r.Context(), deliveryQuery)
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting delivery steps: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	log.Printf("Returning response: %v\n", steps)
+	responseBytes, err := json.Marshal(common.DeliveriesJson{Deliveries: mapDeliverySteps(steps)})
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling deliveries response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func completeDeliveryStep(w http.ResponseWriter, r *http.Request) {
+	deliveryJson := common.DeliveryJson{}
+	if err := json.NewDecoder(r.Body).Decode(&deliveryJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode delivery step: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	date, err := time.Parse(common.DATE_LAYOUT, deliveryJson.Date)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode delivery date (%v): %v\n", deliveryJson.Date, err)
+		w.WriteHeader(400)
+		return
+	}
+	step := core.DeliveryStep{
+		ChatId: deliveryJson.ChatId,
+		UserId: deliveryJson.UserId,
+		Date:   date,
+		Kind:   deliveryJson.Kind,
+		Step:   deliveryJson.Step,
+	}
+	if err := birthdayManager.CompleteDeliveryStep(r.Context(), step); err != nil {
+		common.ErrorLogger.Printf("Error completing delivery step: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func HandleScheduledDates(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	switch r.Method {
+	case http.MethodGet:
+		getLastScheduledDate(w, r)
+	case http.MethodPost:
+		saveScheduledDate(w, r)
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+func getLastScheduledDate(w http.ResponseWriter, r *http.Request) {
+	date, err := birthdayManager.GetLastScheduledDate(r.Context())
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting the last scheduled date: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	response := common.ScheduledDateJson{}
+	if !date.IsZero() {
+		response.Date = date.Format(common.DATE_LAYOUT)
+	}
+	log.Printf("Returning response: %v\n", response)
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling scheduled date response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func saveScheduledDate(w http.ResponseWriter, r *http.Request) {
+	scheduledDateJson := common.ScheduledDateJson{}
+	if err := json.NewDecoder(r.Body).Decode(&scheduledDateJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode scheduled date: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	date, err := time.Parse(common.DATE_LAYOUT, scheduledDateJson.Date)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not decode date (%v): %v\n", scheduledDateJson.Date, err)
+		w.WriteHeader(400)
+		return
+	}
+	if err := birthdayManager.SaveScheduledDate(r.Context(), date); err != nil {
+		common.ErrorLogger.Printf("Error saving scheduled date: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func HandleNotificationJobs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	switch r.Method {
+	case http.MethodGet:
+		getNotificationJobs(w, r)
+	case http.MethodPost:
+		queueNotificationJob(w, r)
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+// getNotificationJobs lists jobs with the status given in the query, dead ones by default.
+func getNotificationJobs(w http.ResponseWriter, r *http.Request) {
+	status := core.JOB_STATUS_DEAD
+	if requestedStatus := r.URL.Query().Get("status"); requestedStatus != "" {
+		status = core.JobStatus(requestedStatus)
+	}
+	jobs, err := birthdayManager.GetNotificationJobs(r.Context(), status)
+	if err != nil {
+		common.ErrorLogger.Printf("Error getting notification jobs: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	writeNotificationJobs(w, jobs)
+}
+
+func queueNotificationJob(w http.ResponseWriter, r *http.Request) {
+	jobJson := common.NotificationJobJson{}
+	if err := json.NewDecoder(r.Body).Decode(&jobJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode notification job: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	runAt, err := time.Parse(time.RFC3339, jobJson.RunAt)
+	if err != nil || jobJson.TaskId == "" {
+		common.ErrorLogger.Printf("Incorrect notification job: %v\n", jobJson)
+		w.WriteHeader(400)
+		return
+	}
+	notification, err := json.Marshal(jobJson.Notification)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not encode notification of job: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	job := core.NotificationJob{TaskId: jobJson.TaskId, Notification: string(notification), RunAt: runAt}
+	if err := birthdayManager.QueueNotificationJob(r.Context(), job); err != nil {
+		common.ErrorLogger.Printf("Error queueing notification job: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func ClaimNotificationJobs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	claimJson := common.ClaimJobsJson{}
+	if err := json.NewDecoder(r.Body).Decode(&claimJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode job claim: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	if claimJson.MaxAttempts < 1 {
+		common.ErrorLogger.Printf("Incorrect job claim: %v\n", claimJson)
+		w.WriteHeader(400)
+		return
+	}
+	jobs, err := birthdayManager.ClaimNotificationJobs(r.Context(), claimJson.Limit, time.Duration(claimJson.VisibilityTimeoutS)*time.Second, claimJson.MaxAttempts)
+	if err != nil {
+		common.ErrorLogger.Printf("Error claiming notification jobs: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	writeNotificationJobs(w, jobs)
+}
+
+func CompleteNotificationJob(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	resultJson := common.JobResultJson{}
+	if err := json.NewDecoder(r.Body).Decode(&resultJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode job result: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	err := birthdayManager.CompleteNotificationJob(r.Context(), resultJson.Id, resultJson.Attempts)
+	if errors.Is(err, core.ErrJobNotClaimed) {
+		common.ErrorLogger.Printf("Rejected a result of notification job: %v from an expired claim\n", resultJson.Id)
+		w.WriteHeader(409)
+		return
+	}
+	if err != nil {
+		common.ErrorLogger.Printf("Error completing notification job: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+// authenticated rejects requests without the api secret. All of them are rejected when the secret isn't configured.
+func authenticated(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expectedAuthorization := []byte(fmt.Sprintf("Bearer %s", apiSecret))
+		if len(apiSecret) == 0 || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expectedAuthorization) != 1 {
+			common.ErrorLogger.Printf("Rejected an unauthenticated request: %s %s\n", r.Method, r.URL)
+			w.WriteHeader(401)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func RemoveUnavailableChat(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	chatJson := common.UnavailableChatJson{}
+	if err := json.NewDecoder(r.Body).Decode(&chatJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode unavailable chat: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	log.Printf("Removing unavailable chatId: %v due to: %v\n", chatJson.ChatId, chatJson.Reason)
+	if err := birthdayManager.RemoveUnavailableChat(r.Context(), chatJson.ChatId); err != nil {
+		common.ErrorLogger.Printf("Error removing unavailable chat: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func FailNotificationJob(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if r.Method != http.MethodPost {
+		w.WriteHeader(405)
+		return
+	}
+	resultJson := common.JobResultJson{}
+	if err := json.NewDecoder(r.Body).Decode(&resultJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode job result: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	failure := core.JobFailure{Id: resultJson.Id, Attempts: resultJson.Attempts, Error: resultJson.Error, Dead: resultJson.Dead}
+	if !resultJson.Dead {
+		retryAt, err := time.Parse(time.RFC3339, resultJson.RetryAt)
+		if err != nil {
+			common.ErrorLogger.Printf("Could not decode retry time (%v): %v\n", resultJson.RetryAt, err)
+			w.WriteHeader(400)
+			return
+		}
+		failure.RetryAt = retryAt
+	}
+	err := birthdayManager.FailNotificationJob(r.Context(), failure)
+	if errors.Is(err, core.ErrJobNotClaimed) {
+		common.ErrorLogger.Printf("Rejected a result of notification job: %v from an expired claim\n", resultJson.Id)
+		w.WriteHeader(409)
+		return
+	}
+	if err != nil {
+		common.ErrorLogger.Printf("Error failing notification job: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func writeNotificationJobs(w http.ResponseWriter, jobs []core.NotificationJob) {
+	jobsJson, err := mapNotificationJobs(jobs)
+	if err != nil {
+		common.ErrorLogger.Printf("Error decoding notifications of jobs: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	log.Printf("Returning response: %v\n", jobsJson)
+	responseBytes, err := json.Marshal(common.NotificationJobsJson{Jobs: jobsJson})
+	if err != nil {
+		common.ErrorLogger.Printf("Error marshalling notification jobs response: %v\n", err)
+		w.WriteHeader(500)
+		return
+	}
+	_, err = w.Write(responseBytes)
+	if err != nil {
+		common.ErrorLogger.Printf("Error writing response: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
+func parseOptionalInt(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func RefreshBoards(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a new request: %s %s\n", r.Method, r.URL)
+	if err := birthdayManager.RefreshBoards(r.Context()); err != nil {
+		common.ErrorLogger.Printf("Error refreshing boards: %v\n", err)
+		w.WriteHeader(500)
+	}
+}
+
 func mapBirthdays(birthdays []core.BirthdayPerson) []common.BirthdayJson {
 	birthdaysJson := make([]common.BirthdayJson, len(birthdays))
 	for index, birthday := range birthdays {
 		birthdaysJson[index] = common.BirthdayJson{
-			ChatId: birthday.ChatId,
-			UserId: birthday.UserId,
-			Name:   birthday.Name,
+			ChatId:    birthday.ChatId,
+			UserId:    birthday.UserId,
+			Name:      birthday.Name,
+			FirstName: birthday.FirstName,
+			LastName:  birthday.LastName,
+			Date:      birthday.Date.Format(common.DATE_LAYOUT),
 		}
 	}
 	return birthdaysJson
 }
+
+func mapEvents(events []core.Event) []common.EventJson {
+	eventsJson := make([]common.EventJson, len(events))
+	for index, event := range events {
+		eventsJson[index] = common.EventJson{
+			ChatId: event.ChatId,
+			Name:   event.Name,
+			Kind:   string(event.Kind),
+			Date:   event.Date.Format(common.DATE_LAYOUT),
+			Year:   event.Year,
+		}
+	}
+	return eventsJson
+}
+
+func mapNameDays(nameDays []core.NameDayPerson) []common.NameDayJson {
+	nameDaysJson := make([]common.NameDayJson, len(nameDays))
+	for index, nameDay := range nameDays {
+		nameDaysJson[index] = common.NameDayJson{
+			ChatId:      nameDay.ChatId,
+			UserId:      nameDay.UserId,
+			Name:        nameDay.Name,
+			NameDayName: nameDay.NameDayName,
+			Country:     nameDay.Country,
+		}
+	}
+	return nameDaysJson
+}
+
+func mapWishes(wishes []core.CollectedWish) []common.WishJson {
+	wishesJson := make([]common.WishJson, len(wishes))
+	for index, wish := range wishes {
+		wishesJson[index] = common.WishJson(wish)
+	}
+	return wishesJson
+}
+
+func mapDeliverySteps(steps []core.DeliveryStep) []common.DeliveryJson {
+	deliveriesJson := make([]common.DeliveryJson, len(steps))
+	for index, step := range steps {
+		deliveriesJson[index] = common.DeliveryJson{
+			ChatId:      step.ChatId,
+			UserId:      step.UserId,
+			Date:        step.Date.Format(common.DATE_LAYOUT),
+			Kind:        step.Kind,
+			Step:        step.Step,
+			CompletedAt: step.CompletedAt.Format(time.RFC3339),
+		}
+	}
+	return deliveriesJson
+}
+
+func mapNotificationJobs(jobs []core.NotificationJob) ([]common.NotificationJobJson, error) {
+	jobsJson := make([]common.NotificationJobJson, len(jobs))
+	for index, job := range jobs {
+		jobsJson[index] = common.NotificationJobJson{
+			Id:        job.Id,
+			TaskId:    job.TaskId,
+			Status:    string(job.Status),
+			Attempts:  job.Attempts,
+			RunAt:     job.RunAt.Format(time.RFC3339),
+			LastError: job.LastError,
+		}
+		if err := json.Unmarshal([]byte(job.Notification), &jobsJson[index].Notification); err != nil {
+			return nil, err
+		}
+	}
+	return jobsJson, nil
+}
@@ -0,0 +1,28 @@
+package adapters
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAdapters(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Adapters Suite")
+}
+
+type FakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (clock *FakeClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+var NOW = time.Date(2024, 3, 14, 10, 0, 0, 0, time.UTC)
@@ -0,0 +1,177 @@
+package adapters
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"strconv"
+	"unicode"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	AVATAR_SIZE         = 400
+	AVATAR_GLYPH_WIDTH  = 5
+	AVATAR_GLYPH_HEIGHT = 7
+	AVATAR_GLYPH_SCALE  = 20
+	AVATAR_GLYPH_SPACE  = 2
+)
+
+// AVATAR_COLORS are the backgrounds of the avatars. A user always gets the same one, picked by their id.
+var AVATAR_COLORS = []color.RGBA{
+	{R: 0xf4, G: 0x8f, B: 0xb1, A: 0xff},
+	{R: 0xce, G: 0x93, B: 0xd8, A: 0xff},
+	{R: 0x90, G: 0xca, B: 0xf9, A: 0xff},
+	{R: 0x80, G: 0xcb, B: 0xc4, A: 0xff},
+	{R: 0xa5, G: 0xd6, B: 0xa7, A: 0xff},
+	{R: 0xff, G: 0xcc, B: 0x80, A: 0xff},
+	{R: 0xff, G: 0xab, B: 0x91, A: 0xff},
+	{R: 0xb3, G: 0x9d, B: 0xdb, A: 0xff},
+}
+
+var AVATAR_TEXT_COLOR = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+// AVATAR_GLYPHS is a 5x7 bitmap font of the characters that can be drawn as initials.
+var AVATAR_GLYPHS = map[rune][AVATAR_GLYPH_HEIGHT]string{
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C': {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D': {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E': {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F': {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I': {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J': {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M': {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T': {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U': {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X': {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y': {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z': {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+}
+
+// AVATAR_TRANSLITERATIONS are the drawable letters of characters that don't decompose into one, like Ł,
+// and of the Greek and Cyrillic alphabets. Letters with diacritics are decomposed into their base letter first.
+var AVATAR_TRANSLITERATIONS = map[rune]rune{
+	'Ł': 'L', 'Đ': 'D', 'Ø': 'O', 'Ħ': 'H', 'Æ': 'A', 'Œ': 'O', 'Þ': 'T', 'ẞ': 'S', 'Ŋ': 'N', 'Ɨ': 'I', 'Ŧ': 'T',
+	'Α': 'A', 'Β': 'B', 'Γ': 'G', 'Δ': 'D', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'I', 'Θ': 'T', 'Ι': 'I', 'Κ': 'K', 'Λ': 'L',
+	'Μ': 'M', 'Ν': 'N', 'Ξ': 'X', 'Ο': 'O', 'Π': 'P', 'Ρ': 'R', 'Σ': 'S', 'Τ': 'T', 'Υ': 'Y', 'Φ': 'F', 'Χ': 'C',
+	'Ψ': 'P', 'Ω': 'O',
+	'А': 'A', 'Б': 'B', 'В': 'V', 'Г': 'G', 'Ґ': 'G', 'Д': 'D', 'Е': 'E', 'Є': 'Y', 'Ж': 'Z', 'З': 'Z', 'И': 'I',
+	'І': 'I', 'К': 'K', 'Л': 'L', 'М': 'M', 'Н': 'N', 'О': 'O', 'П': 'P', 'Р': 'R', 'С': 'S', 'Т': 'T', 'У': 'U',
+	'Ф': 'F', 'Х': 'K', 'Ц': 'C', 'Ч': 'C', 'Ш': 'S', 'Щ': 'S', 'Ы': 'Y', 'Э': 'E', 'Ю': 'Y', 'Я': 'Y',
+}
+
+type AvatarGenerator struct{}
+
+func NewAvatarGenerator() *AvatarGenerator {
+	return &AvatarGenerator{}
+}
+
+// CreateAvatar draws the initials on a colored background. The video masks it into a circle.
+// Letters with diacritics and Greek or Cyrillic letters are drawn as their closest latin letter. Other initials,
+// e.g. in Chinese, are left out and the avatar is just the background.
+func (AvatarGenerator) CreateAvatar(userId int64, initials string) (string, error) {
+	drawableInitials := getDrawableInitials(initials)
+	log.Printf("Generating an avatar with initials: %v for user: %v\n", string(drawableInitials), userId)
+	avatar := image.NewRGBA(image.Rect(0, 0, AVATAR_SIZE, AVATAR_SIZE))
+	background := getAvatarColor(userId)
+	for x := 0; x < AVATAR_SIZE; x++ {
+		for y := 0; y < AVATAR_SIZE; y++ {
+			avatar.SetRGBA(x, y, background)
+		}
+	}
+	drawInitials(avatar, drawableInitials)
+	tmpFile, err := os.CreateTemp("", "*.png")
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create temp file for the avatar of user: %v due to: %v\n", userId, err)
+		return "", err
+	}
+	defer tmpFile.Close()
+	if err := png.Encode(tmpFile, avatar); err != nil {
+		common.ErrorLogger.Printf("Failed to encode the avatar of user: %v due to: %v\n", userId, err)
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
+
+func getDrawableInitials(initials string) []rune {
+	drawableInitials := make([]rune, 0, len(initials))
+	for _, character := range initials {
+		initial := getDrawableInitial(character)
+		if _, present := AVATAR_GLYPHS[initial]; present {
+			drawableInitials = append(drawableInitials, initial)
+		}
+	}
+	return drawableInitials
+}
+
+// getDrawableInitial folds the character into a letter of the bitmap font, e.g. Š into S and Ł into L.
+func getDrawableInitial(character rune) rune {
+	// A decomposed letter starts with its base letter, followed by the combining diacritics.
+	for _, baseCharacter := range norm.NFD.String(string(unicode.ToUpper(character))) {
+		character = baseCharacter
+		break
+	}
+	if letter, present := AVATAR_TRANSLITERATIONS[character]; present {
+		return letter
+	}
+	return character
+}
+
+func getAvatarColor(userId int64) color.RGBA {
+	hash := fnv.New32a()
+	hash.Write([]byte(strconv.FormatInt(userId, 10)))
+	return AVATAR_COLORS[hash.Sum32()%uint32(len(AVATAR_COLORS))]
+}
+
+// drawInitials draws the glyphs next to each other in the center of the avatar.
+func drawInitials(avatar *image.RGBA, initials []rune) {
+	if len(initials) == 0 {
+		return
+	}
+	width := (len(initials)*(AVATAR_GLYPH_WIDTH+AVATAR_GLYPH_SPACE) - AVATAR_GLYPH_SPACE) * AVATAR_GLYPH_SCALE
+	left := (AVATAR_SIZE - width) / 2
+	top := (AVATAR_SIZE - AVATAR_GLYPH_HEIGHT*AVATAR_GLYPH_SCALE) / 2
+	for index, initial := range initials {
+		glyphLeft := left + index*(AVATAR_GLYPH_WIDTH+AVATAR_GLYPH_SPACE)*AVATAR_GLYPH_SCALE
+		for row, line := range AVATAR_GLYPHS[initial] {
+			for column, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+				for x := 0; x < AVATAR_GLYPH_SCALE; x++ {
+					for y := 0; y < AVATAR_GLYPH_SCALE; y++ {
+						avatar.SetRGBA(glyphLeft+column*AVATAR_GLYPH_SCALE+x, top+row*AVATAR_GLYPH_SCALE+y, AVATAR_TEXT_COLOR)
+					}
+				}
+			}
+		}
+	}
+}
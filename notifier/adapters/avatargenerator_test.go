@@ -0,0 +1,46 @@
+package adapters
+
+import (
+	"image/png"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Avatar generator", func() {
+	DescribeTable("should draw initials as letters of the bitmap font",
+		func(initials string, expectedInitials string) {
+			Expect(string(getDrawableInitials(initials))).To(Equal(expectedInitials))
+		},
+		Entry("for latin letters and digits", "A7", "A7"),
+		Entry("for Polish letters", "ŁŻ", "LZ"),
+		Entry("for Czech letters", "ŠČ", "SC"),
+		Entry("for Hungarian letters", "ŐÚ", "OU"),
+		Entry("for Greek letters", "ΓΆ", "GA"),
+		Entry("for Cyrillic letters", "ЙЖ", "IZ"),
+		Entry("for lowercase letters", "ła", "LA"),
+		Entry("without characters that can't be drawn", "李😀B", "B"),
+	)
+
+	It("should draw visible initials for a name with diacritics", func() {
+		path, err := AvatarGenerator{}.CreateAvatar(1, "Ł")
+		Expect(err).To(BeNil())
+		defer os.Remove(path)
+
+		file, err := os.Open(path)
+		Expect(err).To(BeNil())
+		defer file.Close()
+		avatar, err := png.Decode(file)
+		Expect(err).To(BeNil())
+		textPixels := 0
+		for x := 0; x < AVATAR_SIZE; x++ {
+			for y := 0; y < AVATAR_SIZE; y++ {
+				if avatar.At(x, y) == AVATAR_TEXT_COLOR {
+					textPixels++
+				}
+			}
+		}
+		Expect(textPixels).To(Equal(11 * AVATAR_GLYPH_SCALE * AVATAR_GLYPH_SCALE))
+	})
+})
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"log"
+	"strings"
 	"time"
 
 	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
 	taskspb "cloud.google.com/go/cloudtasks/apiv2/cloudtaskspb"
-	"github.com/4Kaze/birthdaybot/common"
 	"github.com/4Kaze/birthdaybot/notifier/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -33,17 +36,12 @@ func NewCloudTasksScheduler(client *cloudtasks.Client, clock core.Clock, queuePa
 	}
 }
 
-func (scheduler *CloudTasksScheduler) Schedule(ctx context.Context, birthday core.Birthday, serviceUrl string) {
-	birthdayJson, err := json.Marshal(common.BirthdayJson{
-		ChatId: birthday.ChatId,
-		UserId: birthday.UserId,
-		Name:   birthday.Name,
-	})
+func (scheduler *CloudTasksScheduler) Schedule(ctx context.Context, notification core.Notification, serviceUrl string) error {
+	notificationJson, err := json.Marshal(mapNotificationToJson(notification))
 	if err != nil {
-		common.ErrorLogger.Printf("Could not marshal birthday: %v to json, due to: %v\n", birthday, err)
-		return
+		return fmt.Errorf("could not marshal notification: %v to json: %w", notification, err)
 	}
-	taskName := fmt.Sprintf("%s/tasks/%v%v%v", scheduler.queuePath, birthday.ChatId, birthday.UserId, scheduler.clock.Now().YearDay())
+	taskName := fmt.Sprintf("%s/tasks/%s", scheduler.queuePath, createTaskId(notification, scheduler.clock.Now()))
 	req := &taskspb.CreateTaskRequest{
 		Parent: scheduler.queuePath,
 		Task: &taskspb.Task{
@@ -54,15 +52,37 @@ func (scheduler *CloudTasksScheduler) Schedule(ctx context.Context, birthday cor
 				HttpRequest: &taskspb.HttpRequest{
 					HttpMethod: taskspb.HttpMethod_POST,
 					Url:        serviceUrl,
-					Body:       birthdayJson,
+					Body:       notificationJson,
 				},
 			},
 		},
 	}
 	task, err := scheduler.client.CreateTask(ctx, req)
+	if status.Code(err) == codes.AlreadyExists {
+		log.Printf("A task %s already exists for notification: %v\n", taskName, string(notificationJson))
+		return nil
+	}
 	if err != nil {
-		common.ErrorLogger.Printf("Could not create birthday task: %v (%s), due to: %v\n", string(birthdayJson), taskName, err)
-		return
+		return fmt.Errorf("could not create notification task: %v (%s): %w", string(notificationJson), taskName, err)
+	}
+	log.Printf("Created a task %s for notification: %v\n", task, string(notificationJson))
+	return nil
+}
+
+// createTaskId returns the same id for the same notification scheduled on the same day, so that it's deduplicated.
+func createTaskId(notification core.Notification, now time.Time) string {
+	yearDay := now.YearDay()
+	if notification.Belated {
+		// A belated notification gets the id it would have had on the missed day, so it's rejected as a duplicate if it was already scheduled back then.
+		yearDay = notification.Date.YearDay()
+	}
+	if notification.Kind == core.NOTIFICATION_KIND_BIRTHDAY && len(notification.Birthdays) == 1 {
+		return fmt.Sprintf("%v%v%v", notification.ChatId, notification.Birthdays[0].UserId, yearDay)
+	}
+	if notification.Kind == core.NOTIFICATION_KIND_EVENT && len(notification.Events) == 1 {
+		nameHash := fnv.New32a()
+		nameHash.Write([]byte(strings.ToLower(notification.Events[0].Name)))
+		return fmt.Sprintf("%s%v%x%v", notification.Kind, notification.ChatId, nameHash.Sum32(), yearDay)
 	}
-	log.Printf("Created a task %s for birthday: %v\n", task, string(birthdayJson))
+	return fmt.Sprintf("%s%v%v", notification.Kind, notification.ChatId, yearDay)
 }
@@ -0,0 +1,66 @@
+package adapters
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/4Kaze/birthdaybot/notifier/core"
+)
+
+// DailyTrigger calls a function every day at the same time, taking the place of Cloud Scheduler when the notifier runs on its own.
+// When the process starts after today's time, the function is called right away unless today's run already happened,
+// and days missed while the process was down are caught up by the notifier on that run.
+type DailyTrigger struct {
+	clock    core.Clock
+	hour     int
+	minute   int
+	location *time.Location
+}
+
+func NewDailyTrigger(clock core.Clock, hour int, minute int, location *time.Location) *DailyTrigger {
+	return &DailyTrigger{clock: clock, hour: hour, minute: minute, location: location}
+}
+
+// Run calls run every day until the context is cancelled. isDoneToday tells whether today's run already happened,
+// when it can't tell, today is run again, as the run fails on its own when its dependencies are unavailable.
+func (trigger *DailyTrigger) Run(ctx context.Context, run func(ctx context.Context), isDoneToday func(ctx context.Context) (bool, error)) {
+	if trigger.isTodayRunMissed() {
+		isDone, err := isDoneToday(ctx)
+		if err != nil {
+			common.ErrorLogger.Printf("Could not check whether today's run already happened, running it now: %v\n", err)
+		}
+		if !isDone {
+			log.Printf("Today's run at %02d:%02d was missed, running it now\n", trigger.hour, trigger.minute)
+			run(ctx)
+		}
+	}
+	for {
+		nextRun := trigger.getNextRun()
+		log.Printf("Next daily run at: %v\n", nextRun)
+		timer := time.NewTimer(nextRun.Sub(trigger.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			run(ctx)
+		}
+	}
+}
+
+func (trigger *DailyTrigger) isTodayRunMissed() bool {
+	now := trigger.clock.Now().In(trigger.location)
+	todayRun := time.Date(now.Year(), now.Month(), now.Day(), trigger.hour, trigger.minute, 0, 0, trigger.location)
+	return !todayRun.After(now)
+}
+
+func (trigger *DailyTrigger) getNextRun() time.Time {
+	now := trigger.clock.Now().In(trigger.location)
+	nextRun := time.Date(now.Year(), now.Month(), now.Day(), trigger.hour, trigger.minute, 0, 0, trigger.location)
+	if !nextRun.After(now) {
+		nextRun = nextRun.AddDate(0, 0, 1)
+	}
+	return nextRun
+}
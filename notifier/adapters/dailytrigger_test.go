@@ -0,0 +1,70 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Daily trigger", func() {
+	var clock FakeClock
+	var trigger *DailyTrigger
+	warsaw, _ := time.LoadLocation("Europe/Warsaw")
+
+	BeforeEach(func() {
+		clock = FakeClock{}
+		trigger = NewDailyTrigger(&clock, 9, 0, warsaw)
+	})
+
+	DescribeTable("should run next at the given time in its time zone",
+		func(now time.Time, expectedNextRun time.Time) {
+			clock.now = now
+
+			Expect(trigger.getNextRun()).To(Equal(expectedNextRun))
+		},
+		Entry("later today", time.Date(2024, 3, 14, 7, 0, 0, 0, time.UTC), time.Date(2024, 3, 14, 9, 0, 0, 0, warsaw)),
+		Entry("tomorrow when today's time passed", time.Date(2024, 3, 14, 8, 0, 0, 0, time.UTC), time.Date(2024, 3, 15, 9, 0, 0, 0, warsaw)),
+		Entry("tomorrow at the exact time", time.Date(2024, 3, 14, 9, 0, 0, 0, warsaw), time.Date(2024, 3, 15, 9, 0, 0, 0, warsaw)),
+		Entry("on a day that's already tomorrow in its time zone", time.Date(2024, 3, 14, 23, 30, 0, 0, time.UTC), time.Date(2024, 3, 15, 9, 0, 0, 0, warsaw)),
+		Entry("across a daylight saving time change", time.Date(2024, 3, 30, 10, 0, 0, 0, warsaw), time.Date(2024, 3, 31, 9, 0, 0, 0, warsaw)),
+	)
+
+	// runOnStart starts the trigger with a cancelled context, so it only does what it does on start.
+	runOnStart := func(isDoneToday func(context.Context) (bool, error)) int {
+		runs := 0
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		trigger.Run(ctx, func(context.Context) { runs++ }, isDoneToday)
+		return runs
+	}
+
+	It("should run on start when today's time passed and today wasn't run yet", func() {
+		clock.now = time.Date(2024, 3, 14, 12, 0, 0, 0, warsaw)
+
+		Expect(runOnStart(func(context.Context) (bool, error) { return false, nil })).To(Equal(1))
+	})
+
+	It("should not run on start when today was already run", func() {
+		clock.now = time.Date(2024, 3, 14, 12, 0, 0, 0, warsaw)
+
+		Expect(runOnStart(func(context.Context) (bool, error) { return true, nil })).To(Equal(0))
+	})
+
+	It("should run on start when it can't tell whether today was run", func() {
+		clock.now = time.Date(2024, 3, 14, 12, 0, 0, 0, warsaw)
+
+		Expect(runOnStart(func(context.Context) (bool, error) { return false, errors.New("test") })).To(Equal(1))
+	})
+
+	It("should not run on start before today's time", func() {
+		clock.now = time.Date(2024, 3, 14, 8, 0, 0, 0, warsaw)
+
+		Expect(runOnStart(func(context.Context) (bool, error) {
+			Fail("should not check whether today was run")
+			return false, nil
+		})).To(Equal(0))
+	})
+})
@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/4Kaze/birthdaybot/notifier/core"
+)
+
+// HttpDeliveryLedger keeps the delivery ledger in the manager's database, so retried notifications see the steps
+// completed by any instance of the notifier.
+type HttpDeliveryLedger struct {
+	repositoryUrl string
+	apiSecret     string
+}
+
+func NewHttpDeliveryLedger(repositoryUrl string, apiSecret string) *HttpDeliveryLedger {
+	return &HttpDeliveryLedger{repositoryUrl: repositoryUrl, apiSecret: apiSecret}
+}
+
+func (ledger HttpDeliveryLedger) GetCompletedSteps(ctx context.Context, delivery core.Delivery) ([]core.DeliveryStep, error) {
+	query := url.Values{}
+	query.Set("chatId", fmt.Sprint(delivery.ChatId))
+	query.Set("userId", fmt.Sprint(delivery.UserId))
+	query.Set("date", delivery.Date.Format(common.DATE_LAYOUT))
+	query.Set("kind", string(delivery.Kind))
+	url := fmt.Sprintf("%s/deliveries?%s", ledger.repositoryUrl, query.Encode())
+	log.Printf("Sending a request to get delivery steps: GET %v\n", url)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to fetch delivery steps: %v\n", err)
+		return nil, err
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ledger.apiSecret))
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to fetch delivery steps: %v\n", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching delivery steps failed with status: %v", response.Status)
+	}
+	var deliveries common.DeliveriesJson
+	err = json.NewDecoder(response.Body).Decode(&deliveries)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to decode a response with delivery steps: %v\n", err)
+		return nil, err
+	}
+	log.Printf("Received a response with delivery steps: %v\n", deliveries)
+	steps := make([]core.DeliveryStep, len(deliveries.Deliveries))
+	for index, deliveryJson := range deliveries.Deliveries {
+		steps[index] = core.DeliveryStep(deliveryJson.Step)
+	}
+	return steps, nil
+}
+
+func (ledger HttpDeliveryLedger) CompleteStep(ctx context.Context, delivery core.Delivery, step core.DeliveryStep) error {
+	url := fmt.Sprintf("%s/deliveries", ledger.repositoryUrl)
+	deliveryJson := common.DeliveryJson{
+		ChatId: delivery.ChatId,
+		UserId: delivery.UserId,
+		Date:   delivery.Date.Format(common.DATE_LAYOUT),
+		Kind:   string(delivery.Kind),
+		Step:   string(step),
+	}
+	log.Printf("Sending a request to record a delivery step: POST %v %v\n", url, deliveryJson)
+	body, err := json.Marshal(deliveryJson)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to encode a delivery step: %v\n", err)
+		return err
+	}
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to record a delivery step: %v\n", err)
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ledger.apiSecret))
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to record a delivery step: %v\n", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("recording a delivery step failed with status: %v", response.Status)
+	}
+	return nil
+}
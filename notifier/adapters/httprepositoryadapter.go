@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -14,20 +15,246 @@ import (
 
 type HttpRepositoryAdapter struct {
 	repositoryUrl string
+	apiSecret     string
 }
 
-func NewHttpRepositoryAdapter(repositoryUrl string) *HttpRepositoryAdapter {
-	return &HttpRepositoryAdapter{repositoryUrl: repositoryUrl}
+// NewHttpRepositoryAdapter creates an adapter that authenticates to the manager's endpoints with the apiSecret.
+func NewHttpRepositoryAdapter(repositoryUrl string, apiSecret string) *HttpRepositoryAdapter {
+	return &HttpRepositoryAdapter{repositoryUrl: repositoryUrl, apiSecret: apiSecret}
 }
 
 func (adapter HttpRepositoryAdapter) GetBirthdays(ctx context.Context, date time.Time) ([]core.Birthday, error) {
-	url := fmt.Sprintf("%s/birthdays?date=%s", adapter.repositoryUrl, date.Format(DATE_LAYOUT))
+	return adapter.fetchBirthdays(ctx, fmt.Sprintf("%s/birthdays?date=%s", adapter.repositoryUrl, date.Format(common.DATE_LAYOUT)))
+}
+
+func (adapter HttpRepositoryAdapter) GetDigestBirthdays(ctx context.Context, date time.Time) ([]core.Birthday, error) {
+	return adapter.fetchBirthdays(ctx, fmt.Sprintf("%s/digests?date=%s", adapter.repositoryUrl, date.Format(common.DATE_LAYOUT)))
+}
+
+func (adapter HttpRepositoryAdapter) GetEvents(ctx context.Context, date time.Time) ([]core.Event, error) {
+	url := fmt.Sprintf("%s/events?date=%s", adapter.repositoryUrl, date.Format(common.DATE_LAYOUT))
+	log.Printf("Sending a request to get events: GET %v\n", url)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to fetch events: %v\n", err)
+		return nil, err
+	}
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to fetch events: %v\n", err)
+		return nil, err
+	}
+	var events common.EventsJson
+	err = json.NewDecoder(response.Body).Decode(&events)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to decode a response with events: %v\n", err)
+		return nil, err
+	}
+	log.Printf("Received a response with events: %v\n", events)
+	return mapEvents(events.Events)
+}
+
+func (adapter HttpRepositoryAdapter) GetNameDays(ctx context.Context, date time.Time) ([]core.NameDay, error) {
+	url := fmt.Sprintf("%s/namedays?date=%s", adapter.repositoryUrl, date.Format(common.DATE_LAYOUT))
+	log.Printf("Sending a request to get name days: GET %v\n", url)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to fetch name days: %v\n", err)
+		return nil, err
+	}
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to fetch name days: %v\n", err)
+		return nil, err
+	}
+	var nameDays common.NameDaysJson
+	err = json.NewDecoder(response.Body).Decode(&nameDays)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to decode a response with name days: %v\n", err)
+		return nil, err
+	}
+	log.Printf("Received a response with name days: %v\n", nameDays)
+	return mapNameDays(nameDays.NameDays), nil
+}
+
+func (adapter HttpRepositoryAdapter) GetWishes(ctx context.Context, chatId int64, userId int64, date time.Time) ([]core.Wish, error) {
+	url := fmt.Sprintf("%s/wishes?chatId=%v&userId=%v&date=%s", adapter.repositoryUrl, chatId, userId, date.Format(common.DATE_LAYOUT))
+	log.Printf("Sending a request to get wishes: GET %v\n", url)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to fetch wishes: %v\n", err)
+		return nil, err
+	}
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to fetch wishes: %v\n", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching wishes failed with status: %v", response.Status)
+	}
+	var wishes common.WishesJson
+	err = json.NewDecoder(response.Body).Decode(&wishes)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to decode a response with wishes: %v\n", err)
+		return nil, err
+	}
+	log.Printf("Received a response with wishes: %v\n", wishes)
+	return mapWishes(wishes.Wishes), nil
+}
+
+func (adapter HttpRepositoryAdapter) GetCustomPicture(ctx context.Context, chatId int64, userId int64) (*core.ProfilePicture, error) {
+	url := fmt.Sprintf("%s/custompictures?chatId=%v&userId=%v", adapter.repositoryUrl, chatId, userId)
+	log.Printf("Sending a request to get a custom picture: GET %v\n", url)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to fetch a custom picture: %v\n", err)
+		return nil, err
+	}
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to fetch a custom picture: %v\n", err)
+		return nil, err
+	}
+	defer response.Body.Close()
+	var picture common.CustomPictureJson
+	err = json.NewDecoder(response.Body).Decode(&picture)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to decode a response with a custom picture: %v\n", err)
+		return nil, err
+	}
+	log.Printf("Received a response with a custom picture: %v\n", picture)
+	if len(picture.FileId) == 0 {
+		return nil, nil
+	}
+	return &core.ProfilePicture{FileId: picture.FileId, FileUniqueId: picture.FileUniqueId}, nil
+}
+
+func (adapter HttpRepositoryAdapter) SaveVideo(ctx context.Context, video core.Video) error {
+	url := fmt.Sprintf("%s/videos", adapter.repositoryUrl)
+	log.Printf("Sending a request to save a video: POST %v %v\n", url, video)
+	body, err := json.Marshal(common.VideoJson(video))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to encode a video: %v\n", err)
+		return err
+	}
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to save a video: %v\n", err)
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to save a video: %v\n", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("saving a video failed with status: %v", response.Status)
+	}
+	return nil
+}
+
+func (adapter HttpRepositoryAdapter) GetLastScheduledDate(ctx context.Context) (time.Time, error) {
+	url := fmt.Sprintf("%s/scheduleddates", adapter.repositoryUrl)
+	log.Printf("Sending a request to get the last scheduled date: GET %v\n", url)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to fetch the last scheduled date: %v\n", err)
+		return time.Time{}, err
+	}
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to fetch the last scheduled date: %v\n", err)
+		return time.Time{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("fetching the last scheduled date failed with status: %v", response.Status)
+	}
+	var scheduledDate common.ScheduledDateJson
+	err = json.NewDecoder(response.Body).Decode(&scheduledDate)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to decode a response with the last scheduled date: %v\n", err)
+		return time.Time{}, err
+	}
+	log.Printf("Received a response with the last scheduled date: %v\n", scheduledDate)
+	if scheduledDate.Date == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(common.DATE_LAYOUT, scheduledDate.Date)
+}
+
+func (adapter HttpRepositoryAdapter) SaveScheduledDate(ctx context.Context, date time.Time) error {
+	url := fmt.Sprintf("%s/scheduleddates", adapter.repositoryUrl)
+	log.Printf("Sending a request to save a scheduled date: POST %v %v\n", url, date.Format(common.DATE_LAYOUT))
+	body, err := json.Marshal(common.ScheduledDateJson{Date: date.Format(common.DATE_LAYOUT)})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to encode a scheduled date: %v\n", err)
+		return err
+	}
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to save a scheduled date: %v\n", err)
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to save a scheduled date: %v\n", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("saving a scheduled date failed with status: %v", response.Status)
+	}
+	return nil
+}
+
+func (adapter HttpRepositoryAdapter) ReportUnavailableChat(ctx context.Context, chatId int64, reason string) error {
+	url := fmt.Sprintf("%s/unavailablechats", adapter.repositoryUrl)
+	log.Printf("Sending a request to report an unavailable chat: POST %v %v\n", url, chatId)
+	body, err := json.Marshal(common.UnavailableChatJson{ChatId: chatId, Reason: reason})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to encode an unavailable chat: %v\n", err)
+		return err
+	}
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to report an unavailable chat: %v\n", err)
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	adapter.authenticate(request)
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to report an unavailable chat: %v\n", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("reporting an unavailable chat failed with status: %v", response.Status)
+	}
+	return nil
+}
+
+func (adapter HttpRepositoryAdapter) fetchBirthdays(ctx context.Context, url string) ([]core.Birthday, error) {
 	log.Printf("Sending a request to get birthdays: GET %v\n", url)
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to create a request to fetch birthdays: %v\n", err)
 		return nil, err
 	}
+	adapter.authenticate(request)
 	response, err := http.DefaultClient.Do(request.WithContext(ctx))
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to send a request to fetch birthdays: %v\n", err)
@@ -40,19 +267,43 @@ func (adapter HttpRepositoryAdapter) GetBirthdays(ctx context.Context, date time
 		return nil, err
 	}
 	log.Printf("Received a response with birthdays: %v\n", birthdays)
-	return mapBirthdays(birthdays), nil
+	return mapBirthdays(birthdays)
+}
+
+func (adapter HttpRepositoryAdapter) authenticate(request *http.Request) {
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adapter.apiSecret))
 }
 
-func mapBirthdays(birthdaysJson common.BirthdaysJson) []core.Birthday {
+func mapBirthdays(birthdaysJson common.BirthdaysJson) ([]core.Birthday, error) {
 	birthdays := make([]core.Birthday, len(birthdaysJson.Birthdays))
 	for index, birthday := range birthdaysJson.Birthdays {
-		birthdays[index] = core.Birthday{
-			ChatId: birthday.ChatId,
-			UserId: birthday.UserId,
-			Name:   birthday.Name,
+		mappedBirthday, err := mapBirthdayJson(birthday)
+		if err != nil {
+			common.ErrorLogger.Printf("Failed to map a birthday: %v\n", err)
+			return nil, err
 		}
+		birthdays[index] = mappedBirthday
 	}
-	return birthdays
+	return birthdays, nil
 }
 
-const DATE_LAYOUT = "2006-01-02"
+func mapEvents(eventsJson []common.EventJson) ([]core.Event, error) {
+	events := make([]core.Event, len(eventsJson))
+	for index, eventJson := range eventsJson {
+		event, err := mapEventJson(eventJson)
+		if err != nil {
+			common.ErrorLogger.Printf("Failed to map an event: %v\n", err)
+			return nil, err
+		}
+		events[index] = event
+	}
+	return events, nil
+}
+
+func mapWishes(wishesJson []common.WishJson) []core.Wish {
+	wishes := make([]core.Wish, len(wishesJson))
+	for index, wish := range wishesJson {
+		wishes[index] = core.Wish(wish)
+	}
+	return wishes
+}
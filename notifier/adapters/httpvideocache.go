@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/4Kaze/birthdaybot/common"
+)
+
+// HttpVideoCache keeps fileIds of uploaded birthday videos in the manager's database, so they survive the notifier scaling to zero.
+type HttpVideoCache struct {
+	repositoryUrl string
+	apiSecret     string
+}
+
+func NewHttpVideoCache(repositoryUrl string, apiSecret string) *HttpVideoCache {
+	return &HttpVideoCache{repositoryUrl: repositoryUrl, apiSecret: apiSecret}
+}
+
+func (cache HttpVideoCache) Get(ctx context.Context, userId int64, pictureUniqueId string) (string, error) {
+	query := url.Values{}
+	query.Set("userId", fmt.Sprint(userId))
+	query.Set("pictureUniqueId", pictureUniqueId)
+	url := fmt.Sprintf("%s/videocache?%s", cache.repositoryUrl, query.Encode())
+	log.Printf("Sending a request to get a cached video: GET %v\n", url)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to fetch a cached video: %v\n", err)
+		return "", err
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cache.apiSecret))
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to fetch a cached video: %v\n", err)
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching a cached video failed with status: %v", response.Status)
+	}
+	var cachedVideo common.CachedVideoJson
+	err = json.NewDecoder(response.Body).Decode(&cachedVideo)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to decode a response with a cached video: %v\n", err)
+		return "", err
+	}
+	log.Printf("Received a response with a cached video: %v\n", cachedVideo)
+	return cachedVideo.FileId, nil
+}
+
+func (cache HttpVideoCache) Put(ctx context.Context, userId int64, pictureUniqueId string, fileId string) error {
+	url := fmt.Sprintf("%s/videocache", cache.repositoryUrl)
+	cachedVideo := common.CachedVideoJson{UserId: userId, PictureUniqueId: pictureUniqueId, FileId: fileId}
+	log.Printf("Sending a request to cache a video: POST %v %v\n", url, cachedVideo)
+	body, err := json.Marshal(cachedVideo)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to encode a cached video: %v\n", err)
+		return err
+	}
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a request to cache a video: %v\n", err)
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cache.apiSecret))
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send a request to cache a video: %v\n", err)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("caching a video failed with status: %v", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package adapters
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/4Kaze/birthdaybot/notifier/core"
+)
+
+// InProcessScheduler sends notifications from the notifier's own process, so it can run without Cloud Tasks.
+// Failed notifications are retried with exponential backoff until they run out of attempts.
+// Pending notifications are kept in memory and lost on restart - the delivery ledger and the catch-up of missed days make up for it.
+type InProcessScheduler struct {
+	clock       core.Clock
+	delay       time.Duration
+	deadline    time.Duration
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	workers     chan struct{}
+	mutex       sync.Mutex
+	jobs        jobQueue
+	wakeUp      chan struct{}
+}
+
+func NewInProcessScheduler(clock core.Clock, delay time.Duration, deadline time.Duration, maxAttempts int, minBackoff time.Duration, maxBackoff time.Duration, concurrency int) *InProcessScheduler {
+	return &InProcessScheduler{
+		clock:       clock,
+		delay:       delay,
+		deadline:    deadline,
+		maxAttempts: max(maxAttempts, 1),
+		minBackoff:  minBackoff,
+		maxBackoff:  max(maxBackoff, minBackoff),
+		workers:     make(chan struct{}, max(concurrency, 1)),
+		wakeUp:      make(chan struct{}, 1),
+	}
+}
+
+// Schedule queues the notification to be sent after the scheduler's delay. The service url is ignored,
+// because notifications are passed to the sending function given to Run.
+func (scheduler *InProcessScheduler) Schedule(_ context.Context, notification core.Notification, _ string) error {
+	scheduler.enqueue(&scheduledJob{notification: notification, runAt: scheduler.clock.Now().Add(scheduler.delay)})
+	log.Printf("Queued a notification: %v\n", notification)
+	return nil
+}
+
+// Run sends due notifications until the context is cancelled, at most as many at once as the scheduler's concurrency.
+func (scheduler *InProcessScheduler) Run(ctx context.Context, send func(context.Context, core.Notification) error) {
+	for {
+		job, wait := scheduler.nextDueJob()
+		if job != nil {
+			select {
+			case scheduler.workers <- struct{}{}:
+				go scheduler.process(ctx, job, send)
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-scheduler.wakeUp:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (scheduler *InProcessScheduler) process(ctx context.Context, job *scheduledJob, send func(context.Context, core.Notification) error) {
+	defer func() { <-scheduler.workers }()
+	job.attempt++
+	attemptCtx := ctx
+	if scheduler.deadline > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, scheduler.deadline)
+		defer cancel()
+	}
+	err := send(attemptCtx, job.notification)
+	if err == nil {
+		return
+	}
+	if job.attempt >= scheduler.maxAttempts {
+		common.ErrorLogger.Printf("Giving up on notification: %v after %v attempts, last error: %v\n", job.notification, job.attempt, err)
+		return
+	}
+	backoff := getBackoff(scheduler.minBackoff, scheduler.maxBackoff, job.attempt)
+	common.ErrorLogger.Printf("Notification: %v failed in attempt %v/%v, retrying in %v, due to: %v\n", job.notification, job.attempt, scheduler.maxAttempts, backoff, err)
+	job.runAt = scheduler.clock.Now().Add(backoff)
+	scheduler.enqueue(job)
+}
+
+// getBackoff doubles the minimal backoff with every failed attempt, up to the maximal one.
+func getBackoff(minBackoff time.Duration, maxBackoff time.Duration, attempt int) time.Duration {
+	backoff := minBackoff
+	for range attempt - 1 {
+		if backoff >= maxBackoff/2 {
+			return maxBackoff
+		}
+		backoff *= 2
+	}
+	return backoff
+}
+
+func (scheduler *InProcessScheduler) enqueue(job *scheduledJob) {
+	scheduler.mutex.Lock()
+	heap.Push(&scheduler.jobs, job)
+	scheduler.mutex.Unlock()
+	select {
+	case scheduler.wakeUp <- struct{}{}:
+	default:
+	}
+}
+
+// nextDueJob takes the earliest job off the queue if it's due, otherwise it returns how long to wait for it.
+func (scheduler *InProcessScheduler) nextDueJob() (*scheduledJob, time.Duration) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if len(scheduler.jobs) == 0 {
+		return nil, time.Hour
+	}
+	wait := scheduler.jobs[0].runAt.Sub(scheduler.clock.Now())
+	if wait > 0 {
+		return nil, wait
+	}
+	return heap.Pop(&scheduler.jobs).(*scheduledJob), 0
+}
+
+type scheduledJob struct {
+	notification core.Notification
+	runAt        time.Time
+	attempt      int
+}
+
+// jobQueue is a heap of jobs ordered by the time they should run at.
+type jobQueue []*scheduledJob
+
+func (queue jobQueue) Len() int {
+	return len(queue)
+}
+
+func (queue jobQueue) Less(i, j int) bool {
+	return queue[i].runAt.Before(queue[j].runAt)
+}
+
+func (queue jobQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *jobQueue) Push(job any) {
+	*queue = append(*queue, job.(*scheduledJob))
+}
+
+func (queue *jobQueue) Pop() any {
+	old := *queue
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	*queue = old[:len(old)-1]
+	return job
+}
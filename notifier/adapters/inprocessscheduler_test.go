@@ -0,0 +1,164 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/notifier/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("In-process scheduler", func() {
+	const maxAttempts = 3
+	const minBackoff = time.Minute
+	const maxBackoff = 5 * time.Minute
+	var clock FakeClock
+	var scheduler *InProcessScheduler
+	notification := core.Notification{Kind: core.NOTIFICATION_KIND_BIRTHDAY, ChatId: 1}
+
+	BeforeEach(func() {
+		clock = FakeClock{now: NOW}
+		scheduler = NewInProcessScheduler(&clock, time.Minute, time.Minute, maxAttempts, minBackoff, maxBackoff, 2)
+	})
+
+	// processNext sends the next queued notification regardless of when it's due, the way Run does once it's due.
+	processNext := func(send func(context.Context, core.Notification) error) {
+		scheduler.mutex.Lock()
+		job := scheduler.jobs[0]
+		scheduler.jobs = scheduler.jobs[1:]
+		scheduler.mutex.Unlock()
+		scheduler.workers <- struct{}{}
+		scheduler.process(context.Background(), job, send)
+	}
+
+	failingSend := func(context.Context, core.Notification) error {
+		return errors.New("test")
+	}
+
+	It("should queue the notification after the delay", func() {
+		// when
+		err := scheduler.Schedule(context.Background(), notification, "")
+
+		// then
+		Expect(err).To(BeNil())
+		Expect(scheduler.jobs).To(HaveLen(1))
+		Expect(scheduler.jobs[0].runAt).To(Equal(NOW.Add(time.Minute)))
+		Expect(scheduler.jobs[0].notification).To(Equal(notification))
+	})
+
+	It("should not queue the notification again when it's sent", func() {
+		// given
+		scheduler.Schedule(context.Background(), notification, "")
+
+		// when
+		processNext(func(context.Context, core.Notification) error { return nil })
+
+		// then
+		Expect(scheduler.jobs).To(BeEmpty())
+	})
+
+	It("should retry a failed notification after the backoff", func() {
+		// given
+		scheduler.Schedule(context.Background(), notification, "")
+
+		// when
+		processNext(failingSend)
+		clock.now = NOW.Add(10 * time.Minute)
+		processNext(failingSend)
+
+		// then
+		Expect(scheduler.jobs).To(HaveLen(1))
+		Expect(scheduler.jobs[0].attempt).To(Equal(2))
+		Expect(scheduler.jobs[0].runAt).To(Equal(clock.now.Add(2 * minBackoff)))
+	})
+
+	It("should give up on a notification after the last attempt", func() {
+		// given
+		scheduler.Schedule(context.Background(), notification, "")
+		attempts := 0
+
+		// when
+		for range maxAttempts {
+			processNext(func(context.Context, core.Notification) error {
+				attempts++
+				return errors.New("test")
+			})
+		}
+
+		// then
+		Expect(attempts).To(Equal(maxAttempts))
+		Expect(scheduler.jobs).To(BeEmpty())
+	})
+
+	It("should send a notification with the deadline", func() {
+		// given
+		scheduler.Schedule(context.Background(), notification, "")
+		var deadline time.Time
+
+		// when
+		processNext(func(ctx context.Context, _ core.Notification) error {
+			deadline, _ = ctx.Deadline()
+			return nil
+		})
+
+		// then
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+	})
+
+	It("should send at most as many notifications at once as its concurrency", func() {
+		// given
+		scheduler = NewInProcessScheduler(&clock, 0, time.Minute, maxAttempts, minBackoff, maxBackoff, 2)
+		for range 5 {
+			scheduler.Schedule(context.Background(), notification, "")
+		}
+		var mutex sync.Mutex
+		running, maxRunning, sent := 0, 0, 0
+		release := make(chan struct{})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// when
+		go scheduler.Run(ctx, func(context.Context, core.Notification) error {
+			mutex.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mutex.Unlock()
+			<-release
+			mutex.Lock()
+			running--
+			sent++
+			mutex.Unlock()
+			return nil
+		})
+
+		// then
+		getRunning := func() int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return running
+		}
+		Eventually(getRunning).Should(Equal(2))
+		Consistently(getRunning, 50*time.Millisecond).Should(Equal(2))
+		close(release)
+		Eventually(func() int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return sent
+		}).Should(Equal(5))
+		Expect(maxRunning).To(Equal(2))
+	})
+
+	DescribeTable("should double the backoff with every attempt up to the maximum",
+		func(attempt int, expectedBackoff time.Duration) {
+			Expect(getBackoff(minBackoff, maxBackoff, attempt)).To(Equal(expectedBackoff))
+		},
+		Entry("for the first attempt", 1, minBackoff),
+		Entry("for the second attempt", 2, 2*minBackoff),
+		Entry("for the third attempt", 3, 4*minBackoff),
+		Entry("when doubling would exceed the maximum", 4, maxBackoff),
+		Entry("for many attempts", 100, maxBackoff),
+	)
+})
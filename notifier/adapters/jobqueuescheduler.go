@@ -0,0 +1,163 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/4Kaze/birthdaybot/notifier/core"
+)
+
+// JobQueueScheduler keeps notifications in a job queue stored in the manager's database, so they survive restarts
+// without Cloud Tasks. Any number of notifier instances can work on the queue - the manager hands every due job to
+// a single instance and gives it back to the queue when the instance doesn't report the result within the visibility timeout.
+// Jobs that fail too many times are marked as dead and kept for inspection.
+type JobQueueScheduler struct {
+	repositoryUrl     string
+	apiSecret         string
+	clock             core.Clock
+	delay             time.Duration
+	deadline          time.Duration
+	visibilityTimeout time.Duration
+	maxAttempts       int
+	minBackoff        time.Duration
+	maxBackoff        time.Duration
+	pollInterval      time.Duration
+	workers           chan struct{}
+}
+
+func NewJobQueueScheduler(repositoryUrl string, apiSecret string, clock core.Clock, delay time.Duration, deadline time.Duration, maxAttempts int, minBackoff time.Duration, maxBackoff time.Duration, pollInterval time.Duration, concurrency int) *JobQueueScheduler {
+	return &JobQueueScheduler{
+		repositoryUrl: repositoryUrl,
+		apiSecret:     apiSecret,
+		clock:         clock,
+		delay:         delay,
+		deadline:      deadline,
+		// A job is given back to the queue only after the instance that claimed it surely stopped working on it.
+		visibilityTimeout: deadline + time.Minute,
+		maxAttempts:       max(maxAttempts, 1),
+		minBackoff:        minBackoff,
+		maxBackoff:        max(maxBackoff, minBackoff),
+		pollInterval:      pollInterval,
+		workers:           make(chan struct{}, max(concurrency, 1)),
+	}
+}
+
+// Schedule adds the notification to the queue. A notification that was already queued today is ignored,
+// just like a Cloud Tasks task with the same name.
+func (scheduler *JobQueueScheduler) Schedule(ctx context.Context, notification core.Notification, _ string) error {
+	now := scheduler.clock.Now()
+	job := common.NotificationJobJson{
+		TaskId:       createTaskId(notification, now),
+		Notification: mapNotificationToJson(notification),
+		RunAt:        now.Add(scheduler.delay).Format(time.RFC3339),
+	}
+	url := fmt.Sprintf("%s/jobs", scheduler.repositoryUrl)
+	log.Printf("Sending a request to queue a notification: POST %v %v\n", url, job)
+	if err := scheduler.postJson(ctx, url, job, nil); err != nil {
+		return fmt.Errorf("could not queue notification: %v (%s): %w", notification, job.TaskId, err)
+	}
+	return nil
+}
+
+// Run claims due jobs and sends their notifications until the context is cancelled.
+func (scheduler *JobQueueScheduler) Run(ctx context.Context, send func(context.Context, core.Notification) error) {
+	for {
+		jobs, err := scheduler.claimJobs(ctx, cap(scheduler.workers)-len(scheduler.workers))
+		if err != nil {
+			common.ErrorLogger.Printf("Could not claim notification jobs: %v\n", err)
+		}
+		for _, job := range jobs {
+			scheduler.workers <- struct{}{}
+			go scheduler.process(ctx, job, send)
+		}
+		if len(jobs) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(scheduler.pollInterval):
+		}
+	}
+}
+
+func (scheduler *JobQueueScheduler) claimJobs(ctx context.Context, limit int) ([]common.NotificationJobJson, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	claim := common.ClaimJobsJson{Limit: limit, VisibilityTimeoutS: int(scheduler.visibilityTimeout.Seconds()), MaxAttempts: scheduler.maxAttempts}
+	var jobs common.NotificationJobsJson
+	if err := scheduler.postJson(ctx, fmt.Sprintf("%s/jobs/claim", scheduler.repositoryUrl), claim, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs.Jobs, nil
+}
+
+func (scheduler *JobQueueScheduler) process(ctx context.Context, job common.NotificationJobJson, send func(context.Context, core.Notification) error) {
+	defer func() { <-scheduler.workers }()
+	result := common.JobResultJson{Id: job.Id, Attempts: job.Attempts}
+	err := scheduler.send(ctx, job, send)
+	if err != nil {
+		result.Error = err.Error()
+		if job.Attempts >= scheduler.maxAttempts {
+			result.Dead = true
+			common.ErrorLogger.Printf("Giving up on notification job: %v after %v attempts, last error: %v\n", job.Id, job.Attempts, err)
+		} else {
+			backoff := getBackoff(scheduler.minBackoff, scheduler.maxBackoff, job.Attempts)
+			result.RetryAt = scheduler.clock.Now().Add(backoff).Format(time.RFC3339)
+			common.ErrorLogger.Printf("Notification job: %v failed in attempt %v/%v, retrying in %v, due to: %v\n", job.Id, job.Attempts, scheduler.maxAttempts, backoff, err)
+		}
+	}
+	endpoint := "complete"
+	if err != nil {
+		endpoint = "fail"
+	}
+	if err := scheduler.postJson(ctx, fmt.Sprintf("%s/jobs/%s", scheduler.repositoryUrl, endpoint), result, nil); err != nil {
+		common.ErrorLogger.Printf("Could not report the result of notification job: %v, it will be claimed again after the visibility timeout: %v\n", job.Id, err)
+	}
+}
+
+func (scheduler *JobQueueScheduler) send(ctx context.Context, job common.NotificationJobJson, send func(context.Context, core.Notification) error) error {
+	notification, err := MapNotificationJson(job.Notification)
+	if err != nil {
+		return err
+	}
+	if scheduler.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, scheduler.deadline)
+		defer cancel()
+	}
+	return send(ctx, notification)
+}
+
+// postJson sends the payload to the manager and decodes its response into the given value, unless it's nil.
+func (scheduler *JobQueueScheduler) postJson(ctx context.Context, url string, payload any, response any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", scheduler.apiSecret))
+	httpResponse, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("POST %v failed with status: %v", url, httpResponse.Status)
+	}
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
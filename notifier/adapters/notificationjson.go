@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+	"github.com/4Kaze/birthdaybot/notifier/core"
+)
+
+func MapNotificationJson(notificationJson common.NotificationJson) (core.Notification, error) {
+	birthdays := make([]core.Birthday, len(notificationJson.Birthdays))
+	for index, birthdayJson := range notificationJson.Birthdays {
+		birthday, err := mapBirthdayJson(birthdayJson)
+		if err != nil {
+			return core.Notification{}, err
+		}
+		birthdays[index] = birthday
+	}
+	events, err := mapEvents(notificationJson.Events)
+	if err != nil {
+		return core.Notification{}, err
+	}
+	notification := core.Notification{
+		Kind:      core.NotificationKind(notificationJson.Kind),
+		ChatId:    notificationJson.ChatId,
+		Birthdays: birthdays,
+		Events:    events,
+		NameDays:  mapNameDays(notificationJson.NameDays),
+		Belated:   notificationJson.Belated,
+	}
+	if notificationJson.Date != "" {
+		date, err := time.Parse(common.DATE_LAYOUT, notificationJson.Date)
+		if err != nil {
+			return core.Notification{}, err
+		}
+		notification.Date = date
+	}
+	return notification, nil
+}
+
+func mapNotificationToJson(notification core.Notification) common.NotificationJson {
+	birthdays := make([]common.BirthdayJson, len(notification.Birthdays))
+	for index, birthday := range notification.Birthdays {
+		birthdays[index] = mapBirthdayToJson(birthday)
+	}
+	events := make([]common.EventJson, len(notification.Events))
+	for index, event := range notification.Events {
+		events[index] = mapEventToJson(event)
+	}
+	nameDays := make([]common.NameDayJson, len(notification.NameDays))
+	for index, nameDay := range notification.NameDays {
+		nameDays[index] = common.NameDayJson(nameDay)
+	}
+	notificationJson := common.NotificationJson{
+		Kind:      string(notification.Kind),
+		ChatId:    notification.ChatId,
+		Birthdays: birthdays,
+		Events:    events,
+		NameDays:  nameDays,
+		Belated:   notification.Belated,
+	}
+	if !notification.Date.IsZero() {
+		notificationJson.Date = notification.Date.Format(common.DATE_LAYOUT)
+	}
+	return notificationJson
+}
+
+func mapBirthdayJson(birthdayJson common.BirthdayJson) (core.Birthday, error) {
+	birthday := core.Birthday{
+		ChatId:    birthdayJson.ChatId,
+		UserId:    birthdayJson.UserId,
+		Name:      birthdayJson.Name,
+		FirstName: birthdayJson.FirstName,
+		LastName:  birthdayJson.LastName,
+	}
+	if birthdayJson.Date != "" {
+		date, err := time.Parse(common.DATE_LAYOUT, birthdayJson.Date)
+		if err != nil {
+			return core.Birthday{}, err
+		}
+		birthday.Date = date
+	}
+	return birthday, nil
+}
+
+func mapBirthdayToJson(birthday core.Birthday) common.BirthdayJson {
+	birthdayJson := common.BirthdayJson{
+		ChatId:    birthday.ChatId,
+		UserId:    birthday.UserId,
+		Name:      birthday.Name,
+		FirstName: birthday.FirstName,
+		LastName:  birthday.LastName,
+	}
+	if !birthday.Date.IsZero() {
+		birthdayJson.Date = birthday.Date.Format(common.DATE_LAYOUT)
+	}
+	return birthdayJson
+}
+
+func mapEventJson(eventJson common.EventJson) (core.Event, error) {
+	date, err := time.Parse(common.DATE_LAYOUT, eventJson.Date)
+	if err != nil {
+		return core.Event{}, err
+	}
+	return core.Event{
+		ChatId: eventJson.ChatId,
+		Name:   eventJson.Name,
+		Kind:   eventJson.Kind,
+		Date:   date,
+		Year:   eventJson.Year,
+	}, nil
+}
+
+func mapEventToJson(event core.Event) common.EventJson {
+	return common.EventJson{
+		ChatId: event.ChatId,
+		Name:   event.Name,
+		Kind:   event.Kind,
+		Date:   event.Date.Format(common.DATE_LAYOUT),
+		Year:   event.Year,
+	}
+}
+
+func mapNameDays(nameDaysJson []common.NameDayJson) []core.NameDay {
+	nameDays := make([]core.NameDay, len(nameDaysJson))
+	for index, nameDay := range nameDaysJson {
+		nameDays[index] = core.NameDay(nameDay)
+	}
+	return nameDays
+}
@@ -3,79 +3,132 @@ package adapters
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/4Kaze/birthdaybot/common"
+	"github.com/4Kaze/birthdaybot/notifier/core"
 	telegram "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
+// PERMANENT_TELEGRAM_ERRORS are descriptions of errors that won't go away when a message is sent to the chat again,
+// because the chat is gone or the bot can't even send text to it. Errors of missing rights to send media, like
+// "not enough rights to send videos to the chat", only mean the video can't be sent and must not be listed here.
+var PERMANENT_TELEGRAM_ERRORS = []string{
+	"bot was kicked",
+	"bot is not a member",
+	"chat not found",
+	"have no rights to send a message",
+	"not enough rights to send text messages",
+	"CHAT_WRITE_FORBIDDEN",
+}
+
 type TelegramBotWrapper struct {
-	bot *telegram.Bot
+	bot    *telegram.Bot
+	sender *common.TelegramSender
 }
 
 func NewTelegramWrapper(bot *telegram.Bot) *TelegramBotWrapper {
 	return &TelegramBotWrapper{
-		bot: bot,
+		bot:    bot,
+		sender: common.NewTelegramSender(classifyTelegramError),
 	}
 }
 
 func (wrapper *TelegramBotWrapper) SendMessage(ctx context.Context, chatId int64, text string) error {
 	log.Printf("Sending message to chatId: %v, text: %v\n", chatId, text)
-	_, err := wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
-		ChatID:    chatId,
-		Text:      text,
-		ParseMode: models.ParseModeHTML,
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.SendMessage(ctx, &telegram.SendMessageParams{
+			ChatID:    chatId,
+			Text:      text,
+			ParseMode: models.ParseModeHTML,
+		})
+		return err
 	})
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to send message: %v to chatId: %v due to: %v\n", text, chatId, err)
 	}
-	return err
+	return mapPermanentTelegramError(err)
 }
 
 func (wrapper *TelegramBotWrapper) SendVideo(ctx context.Context, chatId int64, pathToVideo string) (fileId string, err error) {
 	log.Printf("Sending video to chatId: %v, path: %v\n", chatId, pathToVideo)
-	videoFile, err := os.Open(pathToVideo)
-	if err != nil {
-		common.ErrorLogger.Printf("Failed to open video file: %v due to: %v\n", pathToVideo, err)
-		return "", err
-	}
-	defer videoFile.Close()
-	sentVideo, err := wrapper.bot.SendVideo(ctx, &telegram.SendVideoParams{
-		ChatID: chatId,
-		Video: &models.InputFileUpload{
-			Filename: "happy birthday!.mp4",
-			Data:     videoFile,
-		},
+	var sentVideo *models.Message
+	err = wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		// The file is reopened for every attempt, as a failed upload may have read it partially.
+		videoFile, err := os.Open(pathToVideo)
+		if err != nil {
+			common.ErrorLogger.Printf("Failed to open video file: %v due to: %v\n", pathToVideo, err)
+			return err
+		}
+		defer videoFile.Close()
+		sentVideo, err = wrapper.bot.SendVideo(ctx, &telegram.SendVideoParams{
+			ChatID: chatId,
+			Video: &models.InputFileUpload{
+				Filename: "happy birthday!.mp4",
+				Data:     videoFile,
+			},
+		})
+		return err
 	})
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to send video to chatId: %v due to: %v\n", chatId, err)
-		return "", err
+		return "", mapPermanentTelegramError(err)
 	}
 	return sentVideo.Video.FileID, nil
 }
 
 func (wrapper *TelegramBotWrapper) SendVideoFromFileId(ctx context.Context, chatId int64, fileId string) error {
 	log.Printf("Sending video to chatId: %v, fileId: %v\n", chatId, fileId)
-	_, err := wrapper.bot.SendVideo(ctx, &telegram.SendVideoParams{
-		ChatID: chatId,
-		Video: &models.InputFileString{
-			Data: fileId,
-		},
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.SendVideo(ctx, &telegram.SendVideoParams{
+			ChatID: chatId,
+			Video: &models.InputFileString{
+				Data: fileId,
+			},
+		})
+		return err
 	})
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to send video to chatId: %v from fileID: %v due to: %v\n", chatId, fileId, err)
+		return mapPermanentTelegramError(err)
+	}
+	return nil
+}
+
+func (wrapper *TelegramBotWrapper) SendVoiceFromFileId(ctx context.Context, chatId int64, fileId string, caption string) error {
+	log.Printf("Sending voice to chatId: %v, fileId: %v\n", chatId, fileId)
+	err := wrapper.sender.Send(ctx, chatId, func(ctx context.Context) error {
+		_, err := wrapper.bot.SendVoice(ctx, &telegram.SendVoiceParams{
+			ChatID: chatId,
+			Voice: &models.InputFileString{
+				Data: fileId,
+			},
+			Caption:   caption,
+			ParseMode: models.ParseModeHTML,
+		})
 		return err
+	})
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to send voice to chatId: %v from fileID: %v due to: %v\n", chatId, fileId, err)
+		return mapPermanentTelegramError(err)
 	}
 	return nil
 }
 
-func (wrapper *TelegramBotWrapper) GetProfilePictureFileIds(ctx context.Context, userId int64) ([]string, error) {
+func (wrapper *TelegramBotWrapper) GetProfilePictures(ctx context.Context, userId int64) ([]core.ProfilePicture, error) {
 	log.Printf("Getting profile pictures for userId: %v\n", userId)
-	photos, err := wrapper.bot.GetUserProfilePhotos(ctx, &telegram.GetUserProfilePhotosParams{
-		UserID: userId,
-		Limit:  1,
+	var photos *models.UserProfilePhotos
+	err := wrapper.sender.Call(ctx, func(ctx context.Context) (err error) {
+		photos, err = wrapper.bot.GetUserProfilePhotos(ctx, &telegram.GetUserProfilePhotosParams{
+			UserID: userId,
+			Limit:  1,
+		})
+		return err
 	})
 	if err != nil {
 		if errors.Is(err, telegram.ErrorBadRequest) {
@@ -90,14 +143,18 @@ func (wrapper *TelegramBotWrapper) GetProfilePictureFileIds(ctx context.Context,
 		return nil, nil
 	}
 
-	fileId := photos.Photos[0][0].FileID
-	return []string{fileId}, nil
+	photo := photos.Photos[0][0]
+	return []core.ProfilePicture{{FileId: photo.FileID, FileUniqueId: photo.FileUniqueID}}, nil
 }
 
 func (wrapper *TelegramBotWrapper) GetFileLink(ctx context.Context, fileId string) (string, error) {
 	log.Printf("Getting a link to a fileID: %v\n", fileId)
-	file, err := wrapper.bot.GetFile(ctx, &telegram.GetFileParams{
-		FileID: fileId,
+	var file *models.File
+	err := wrapper.sender.Call(ctx, func(ctx context.Context) (err error) {
+		file, err = wrapper.bot.GetFile(ctx, &telegram.GetFileParams{
+			FileID: fileId,
+		})
+		return err
 	})
 	if err != nil {
 		if errors.Is(err, telegram.ErrorBadRequest) {
@@ -110,3 +167,31 @@ func (wrapper *TelegramBotWrapper) GetFileLink(ctx context.Context, fileId strin
 	link := wrapper.bot.FileDownloadLink(file)
 	return link, nil
 }
+
+func classifyTelegramError(err error) *common.TelegramRetryableError {
+	var tooManyRequestsError *telegram.TooManyRequestsError
+	if errors.As(err, &tooManyRequestsError) {
+		return &common.TelegramRetryableError{
+			StatusCode: 429,
+			RetryAfter: time.Duration(tooManyRequestsError.RetryAfter) * time.Second,
+			Err:        err,
+		}
+	}
+	return common.ParseTelegramErrorResponse(err)
+}
+
+// mapPermanentTelegramError marks errors of sending to a chat the bot can no longer post to with core.ErrChatUnavailable.
+func mapPermanentTelegramError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, telegram.ErrorForbidden) {
+		return fmt.Errorf("%w: %v", core.ErrChatUnavailable, err)
+	}
+	for _, description := range PERMANENT_TELEGRAM_ERRORS {
+		if strings.Contains(err.Error(), description) {
+			return fmt.Errorf("%w: %v", core.ErrChatUnavailable, err)
+		}
+	}
+	return err
+}
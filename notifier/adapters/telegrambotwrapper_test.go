@@ -0,0 +1,42 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/4Kaze/birthdaybot/notifier/core"
+	telegram "github.com/go-telegram/bot"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Telegram bot wrapper", func() {
+	DescribeTable("should mark errors of chats the bot can no longer post to as unavailable",
+		func(err error) {
+			Expect(mapPermanentTelegramError(err)).To(MatchError(core.ErrChatUnavailable))
+		},
+		Entry("when the bot was kicked", fmt.Errorf("%w, %s", telegram.ErrorForbidden, "Forbidden: bot was kicked from the supergroup chat")),
+		Entry("when the bot is not a member", fmt.Errorf("%w, %s", telegram.ErrorForbidden, "Forbidden: bot is not a member of the channel chat")),
+		Entry("when the chat is gone", fmt.Errorf("%w, %s", telegram.ErrorBadRequest, "Bad Request: chat not found")),
+		Entry("when the bot can't send messages", fmt.Errorf("%w, %s", telegram.ErrorBadRequest, "Bad Request: have no rights to send a message")),
+		Entry("when the bot can't send text", fmt.Errorf("%w, %s", telegram.ErrorBadRequest, "Bad Request: not enough rights to send text messages to the chat")),
+		Entry("when writing to the chat is forbidden", fmt.Errorf("%w, %s", telegram.ErrorBadRequest, "Bad Request: CHAT_WRITE_FORBIDDEN")),
+	)
+
+	DescribeTable("should keep other errors so the chat isn't removed",
+		func(err error) {
+			mappedError := mapPermanentTelegramError(err)
+
+			Expect(mappedError).To(Equal(err))
+			Expect(errors.Is(mappedError, core.ErrChatUnavailable)).To(BeFalse())
+		},
+		Entry("when the bot can't send videos", fmt.Errorf("%w, %s", telegram.ErrorBadRequest, "Bad Request: not enough rights to send videos to the chat")),
+		Entry("when the bot can't send voice notes", fmt.Errorf("%w, %s", telegram.ErrorBadRequest, "Bad Request: not enough rights to send voice notes to the chat")),
+		Entry("when the video is too big", fmt.Errorf("%w, %s", telegram.ErrorBadRequest, "Bad Request: file is too big")),
+		Entry("when telegram fails", errors.New("error response from telegram for method sendVideo, 502 Bad Gateway")),
+	)
+
+	It("should not map a missing error", func() {
+		Expect(mapPermanentTelegramError(nil)).To(BeNil())
+	})
+})
@@ -1,98 +1,162 @@
 package adapters
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/4Kaze/birthdaybot/common"
 )
 
 const (
-	VIDEO_WIDTH       = 872
-	VIDEO_HEIGHT      = 480
-	BACKGROUND_1      = "background-1.png"
-	BACKGROUND_2      = "background-2.png"
-	PART_1_VIDEO      = "part-1.mp4"
-	PART_3_VIDEO      = "part-3.mp4"
-	PART_6_VIDEO      = "part-6.mp4"
-	PART_7_VIDEO      = "part-7.mp4"
-	TRANSPARENT_IMAGE = "transparent.png"
-	CAKE              = "birthday-cake.png"
-	AUDIO_TRACK       = "audio.mp3"
-
-	PART_2_FILTER = `[1]scale=400:400, split[avatar1][avatar2]; \
-	[avatar2]geq=lum='if(lte(sqrt((X-W/2)*(X-W/2)+(Y-H/2)*(Y-H/2)),min(W,H)/2),255,0)':a=255 [mask]; \
-	[avatar1][mask] alphamerge[maskedavatar]; \
-	[0][maskedavatar] overlay=(main_w-overlay_w)/2-5:(main_h-overlay_h-60)[background with avatar]; \
-	[background with avatar][2]overlay=x=(W-w)/2:y=H-h[avatar with cake]; \
-	[avatar with cake]scale='%[1]v*4':'%[2]v*4',zoompan=z='zoom+0.0005':d=700:x='iw/2-(iw/zoom/2)':y='ih/2-(ih/zoom/2)',scale=%[1]v:%[2]v`
-	PART_4_FILTER = `[1]scale=400:400, split[avatar1][avatar2]; \
-	[avatar2]geq=lum='if(lte(sqrt((X-W/2)*(X-W/2)+(Y-H/2)*(Y-H/2)),min(W,H)/2),255,0)':a=255 [mask]; \
-	[avatar1][mask] alphamerge[masked avatar]; \
-	[masked avatar]rotate=-0.05:c=none[rotated avatar]; \
-	[0][rotated avatar] overlay=(main_w-overlay_w)/2-t*5:main_h-overlay_h-10-t*10`
-	PART_5_FILTER = `[1]scale=400:400, split[avatar1][avatar2]; \
-	[avatar2]geq=lum='if(lte(sqrt((X-W/2)*(X-W/2)+(Y-H/2)*(Y-H/2)),min(W,H)/2),255,0)':a=255 [mask]; \
-	[avatar1][mask] alphamerge[masked avatar]; \
-	[masked avatar]rotate=-0.1:c=none[rotated avatar]; \
-	[0][rotated avatar] overlay=(main_w-overlay_w)/2-20+'if(between(t,0,0.6),sin(t*25)*10,0)':main_h-overlay_h-5-'if(between(t,0,0.8),cos(t*10)*5,0)`
-	PART_6_FILTER = `[1]scale=24:24, split[avatar1][avatar2]; \
-	[avatar2]geq=lum='if(lte(sqrt((X-W/2)*(X-W/2)+(Y-H/2)*(Y-H/2)),min(W,H)/2),255,0)':a=255 [mask]; \
-	[avatar1][mask] alphamerge[masked avatar]; \
-	[masked avatar] scale=4*iw:4*ih[scaled avatar]; \
-	[0] scale='%[1]v*4':'%[2]v*4'[scaled video];\
-	[scaled video][scaled avatar] overlay=339*4:310*4+t*48*4,scale=%[1]v:%[2]v`
-	PART_7_FILTER = `[1]scale=200:200, split[avatar1][avatar2]; \
-	[avatar2]geq=lum='if(lte(sqrt((X-W/2)*(X-W/2)+(Y-H/2)*(Y-H/2)),min(W,H)/2),255,0)':a=255 [mask]; \
-	[avatar1][mask] alphamerge[masked avatar]; \
-	[2]scale='%[1]v*4':'%[2]v*4'[scaled background]; \
-	[scaled background][masked avatar]overlay=x=(W-w)/2-2:y=504[overlayed avatar]; \
-	[overlayed avatar]scale='%[1]v*4':'%[2]v*4',zoompan=z='if(lte(zoom,1.0),1.5,max(1.0001,zoom-0.0011))':d=300:x='iw/2-(iw/zoom/2)':y='ih/2-(ih/zoom/2)+time*0.8',scale=%[1]v:%[2]v[zoomed avatar]; \
-	[0][zoomed avatar] overlay=0:0:enable='between(t,0,8.51)'`
+	TEMPLATES_DIR          = "templates"
+	DEFAULT_VIDEO_TEMPLATE = "birthday"
+	TEMPLATE_MANIFEST      = "%s.json"
+	CAKE                   = "birthday-cake.png"
+	EVENT_TEMPLATE         = "event-%s.png"
+	COLLAGE_CELL_SIZE      = 400
+	COLLAGE_COLOR          = "white"
+
+	PLACEHOLDER_AVATAR = "{{avatar}}"
+	PLACEHOLDER_NAME   = "{{name}}"
+	PLACEHOLDER_WIDTH  = "{{width}}"
+	PLACEHOLDER_HEIGHT = "{{height}}"
 )
 
-var creators = []func(VideoGenerator, string, string) error{VideoGenerator.createPartTwo, VideoGenerator.createPartFour, VideoGenerator.createPartFive, VideoGenerator.createPartSix, VideoGenerator.createPartSeven}
+// VideoTemplate is a manifest describing how a video is made: its segments are rendered one by one, merged
+// in the order they're listed and the audio track is put over them. Files are relative to the resource directory.
+// Files and filters can use placeholders for the profile picture ({{avatar}}) and the size of the video ({{width}}, {{height}}).
+// Filters can also use the name of the person or event ({{name}}), escaped to be the unquoted text of drawtext,
+// e.g. drawtext=fontfile=font.ttf:text={{name}}.
+type VideoTemplate struct {
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	Segments []VideoSegment `json:"segments"`
+	Audio    string         `json:"audio"`
+}
+
+// VideoSegment is a part of the video rendered with an ffmpeg filter graph. A segment without a filter
+// is a single ready-made video that's merged as it is.
+type VideoSegment struct {
+	Inputs []VideoInput `json:"inputs"`
+	// Filter is the filter graph split into lines for readability. The lines are joined without a separator.
+	Filter   []string `json:"filter"`
+	Duration float64  `json:"duration"`
+}
+
+type VideoInput struct {
+	File string `json:"file"`
+	// Loop repeats an image input, so it lasts for the whole duration of the segment.
+	Loop bool `json:"loop"`
+}
 
 type VideoGenerator struct {
-	resourceDir string
+	resourceDir  string
+	templateName string
 }
 
-func NewVideoGenerator(resourceDir string) *VideoGenerator {
-	return &VideoGenerator{resourceDir: resourceDir}
+func NewVideoGenerator(resourceDir string, templateName string) *VideoGenerator {
+	return &VideoGenerator{resourceDir: resourceDir, templateName: templateName}
 }
 
-func (generator VideoGenerator) CreateVideo(pathToProfilePicture string) (string, error) {
-	log.Printf("Generating a video with profile picture: %v\n", pathToProfilePicture)
+// CreateEventVideo creates a video with a template image of the event kind in place of a profile picture.
+// Kinds without their own template fall back to the birthday cake.
+func (generator VideoGenerator) CreateEventVideo(eventKind string, name string) (string, error) {
+	templatePath := filepath.Join(generator.resourceDir, fmt.Sprintf(EVENT_TEMPLATE, filepath.Base(eventKind)))
+	if _, err := os.Stat(templatePath); err != nil {
+		log.Printf("No template for event kind: %v, using the default one\n", eventKind)
+		templatePath = filepath.Join(generator.resourceDir, CAKE)
+	}
+	return generator.CreateVideo(templatePath, name)
+}
+
+// CreateGroupVideo creates a video with a collage of the profile pictures in place of a single one.
+func (generator VideoGenerator) CreateGroupVideo(pathsToProfilePictures []string, name string) (string, error) {
+	if len(pathsToProfilePictures) == 1 {
+		return generator.CreateVideo(pathsToProfilePictures[0], name)
+	}
 	tmpDir, err := os.MkdirTemp("", "*")
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to create a temp dir: %v\n", err)
 		return "", err
 	}
-	files := make([]string, len(creators))
-	for index, creator := range creators {
+	collagePath := filepath.Join(tmpDir, "collage.png")
+	log.Printf("Generating a collage of profile pictures: %v\n", pathsToProfilePictures)
+	if err := createCollage(pathsToProfilePictures, collagePath); err != nil {
+		return "", err
+	}
+	return generator.CreateVideo(collagePath, name)
+}
+
+// createCollage places the pictures on a square grid, centering the last row when it isn't full.
+func createCollage(pathsToPictures []string, outputFilePath string) error {
+	columns := int(math.Ceil(math.Sqrt(float64(len(pathsToPictures)))))
+	rows := (len(pathsToPictures) + columns - 1) / columns
+	size := columns * COLLAGE_CELL_SIZE
+	topOffset := (columns - rows) * COLLAGE_CELL_SIZE / 2
+	args := make([]string, 0, 2*len(pathsToPictures)+6)
+	var filter strings.Builder
+	filter.WriteString(fmt.Sprintf("color=c=%s:s=%vx%v[collage0]", COLLAGE_COLOR, size, size))
+	for index, path := range pathsToPictures {
+		args = append(args, "-i", path)
+		row, column := index/columns, index%columns
+		leftOffset := 0
+		if picturesInRow := min(columns, len(pathsToPictures)-row*columns); picturesInRow < columns {
+			leftOffset = (columns - picturesInRow) * COLLAGE_CELL_SIZE / 2
+		}
+		x := leftOffset + column*COLLAGE_CELL_SIZE
+		y := topOffset + row*COLLAGE_CELL_SIZE
+		filter.WriteString(fmt.Sprintf("; [%[1]v]scale=%[2]v:%[2]v[picture%[1]v]; [collage%[1]v][picture%[1]v]overlay=%[3]v:%[4]v", index, COLLAGE_CELL_SIZE, x, y))
+		if index < len(pathsToPictures)-1 {
+			filter.WriteString(fmt.Sprintf("[collage%v]", index+1))
+		}
+	}
+	args = append(args, "-filter_complex", filter.String(), "-frames:v", "1", outputFilePath)
+	return execCommand("ffmpeg", args...)
+}
+
+// CreateVideo renders the template with the profile picture and the name. The manifest is read on every render,
+// so changes to the template don't need a restart.
+func (generator VideoGenerator) CreateVideo(pathToProfilePicture string, name string) (string, error) {
+	log.Printf("Generating a video from template: %v with profile picture: %v\n", generator.templateName, pathToProfilePicture)
+	template, err := generator.loadTemplate()
+	if err != nil {
+		return "", err
+	}
+	tmpDir, err := os.MkdirTemp("", "*")
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to create a temp dir: %v\n", err)
+		return "", err
+	}
+	placeholders := getPlaceholders(template, pathToProfilePicture, name)
+	files := make([]string, len(template.Segments))
+	for index, segment := range template.Segments {
 		log.Printf("Generating video %v...\n", index+1)
-		fileName := fmt.Sprintf("%v.mp4", index)
-		filePath := filepath.Join(tmpDir, fileName)
-		files[index] = filePath
-		err := creator(generator, pathToProfilePicture, filePath)
+		files[index], err = generator.renderSegment(segment, placeholders, filepath.Join(tmpDir, fmt.Sprintf("%v.mp4", index)))
 		if err != nil {
 			return "", err
 		}
 	}
 	mergedVideoPath := filepath.Join(tmpDir, "merged.mp4")
 	log.Println("Merging videos...")
-	err = generator.mergeVideos(files, tmpDir, mergedVideoPath)
+	err = mergeVideos(files, tmpDir, mergedVideoPath)
 	if err != nil {
 		return "", err
 	}
+	if len(template.Audio) == 0 {
+		log.Printf("Generated a video: %v, for profile picture: %v\n", mergedVideoPath, pathToProfilePicture)
+		return mergedVideoPath, nil
+	}
 	finalVideoPath := filepath.Join(tmpDir, "final.mp4")
 	log.Println("Adding audio...")
-	err = generator.addAudio(mergedVideoPath, finalVideoPath)
+	err = addAudio(mergedVideoPath, generator.resolvePath(template.Audio, placeholders), finalVideoPath)
 	if err != nil {
 		return "", err
 	}
@@ -100,100 +164,82 @@ func (generator VideoGenerator) CreateVideo(pathToProfilePicture string) (string
 	return finalVideoPath, nil
 }
 
-func (generator VideoGenerator) createPartTwo(profilePicturePath string, outputFilePath string) error {
-	return execCommand(
-		"ffmpeg",
-		"-i",
-		filepath.Join(generator.resourceDir, BACKGROUND_1),
-		"-i",
-		profilePicturePath,
-		"-i",
-		filepath.Join(generator.resourceDir, CAKE),
-		"-filter_complex",
-		convertToOneLine(fmt.Sprintf(PART_2_FILTER, VIDEO_WIDTH, VIDEO_HEIGHT)),
-		"-t",
-		"8",
-		outputFilePath,
-	)
+func (generator VideoGenerator) loadTemplate() (VideoTemplate, error) {
+	manifestPath := filepath.Join(generator.resourceDir, TEMPLATES_DIR, fmt.Sprintf(TEMPLATE_MANIFEST, filepath.Base(generator.templateName)))
+	manifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to read video template manifest: %v due to: %v\n", manifestPath, err)
+		return VideoTemplate{}, err
+	}
+	var template VideoTemplate
+	if err := json.Unmarshal(manifest, &template); err != nil {
+		common.ErrorLogger.Printf("Failed to decode video template manifest: %v due to: %v\n", manifestPath, err)
+		return VideoTemplate{}, err
+	}
+	if template.Width <= 0 || template.Height <= 0 || len(template.Segments) == 0 {
+		return VideoTemplate{}, fmt.Errorf("video template manifest: %v must have a size and at least one segment", manifestPath)
+	}
+	for index, segment := range template.Segments {
+		if len(segment.Inputs) == 0 || (len(segment.Filter) == 0 && len(segment.Inputs) > 1) {
+			return VideoTemplate{}, fmt.Errorf("segment %v of video template manifest: %v must have a single input or a filter", index+1, manifestPath)
+		}
+	}
+	return template, nil
 }
 
-func (generator VideoGenerator) createPartFour(profilePicturePath string, outputFilePath string) error {
-	return execCommand(
-		"ffmpeg",
-		"-loop",
-		"1",
-		"-i",
-		filepath.Join(generator.resourceDir, BACKGROUND_2),
-		"-i",
-		profilePicturePath,
-		"-filter_complex",
-		convertToOneLine(PART_4_FILTER),
-		"-t",
-		"0.9",
-		outputFilePath,
+func getPlaceholders(template VideoTemplate, pathToProfilePicture string, name string) *strings.Replacer {
+	return strings.NewReplacer(
+		PLACEHOLDER_AVATAR, pathToProfilePicture,
+		PLACEHOLDER_NAME, escapeDrawtext(name),
+		PLACEHOLDER_WIDTH, strconv.Itoa(template.Width),
+		PLACEHOLDER_HEIGHT, strconv.Itoa(template.Height),
 	)
 }
 
-func (generator VideoGenerator) createPartFive(profilePicturePath string, outputFilePath string) error {
-	return execCommand(
-		"ffmpeg",
-		"-loop",
-		"1",
-		"-i",
-		filepath.Join(generator.resourceDir, BACKGROUND_2),
-		"-i",
-		profilePicturePath,
-		"-filter_complex",
-		convertToOneLine(PART_5_FILTER),
-		"-t",
-		"1.1",
-		outputFilePath,
-	)
+// escapeDrawtext escapes the text for each level ffmpeg parses it at, from the innermost one: the text expansion
+// of drawtext, the value of a filter option and the filter graph. Any name can then be used as an unquoted text option.
+func escapeDrawtext(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, `%`, `\%`).Replace(text)
+	text = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`).Replace(text)
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(text)
 }
 
-func (generator VideoGenerator) createPartSix(profilePicturePath string, outputFilePath string) error {
-	return execCommand(
-		"ffmpeg",
-		"-i",
-		filepath.Join(generator.resourceDir, PART_6_VIDEO),
-		"-i",
-		profilePicturePath,
-		"-filter_complex",
-		convertToOneLine(fmt.Sprintf(PART_6_FILTER, VIDEO_WIDTH, VIDEO_HEIGHT)),
-		outputFilePath,
-	)
+// renderSegment returns the path to the rendered segment, which is the input itself for segments without a filter.
+func (generator VideoGenerator) renderSegment(segment VideoSegment, placeholders *strings.Replacer, outputFilePath string) (string, error) {
+	if len(segment.Filter) == 0 {
+		return generator.resolvePath(segment.Inputs[0].File, placeholders), nil
+	}
+	args := make([]string, 0, 3*len(segment.Inputs)+5)
+	for _, input := range segment.Inputs {
+		if input.Loop {
+			args = append(args, "-loop", "1")
+		}
+		args = append(args, "-i", generator.resolvePath(input.File, placeholders))
+	}
+	args = append(args, "-filter_complex", placeholders.Replace(strings.Join(segment.Filter, "")))
+	if segment.Duration > 0 {
+		args = append(args, "-t", strconv.FormatFloat(segment.Duration, 'f', -1, 64))
+	}
+	args = append(args, outputFilePath)
+	return outputFilePath, execCommand("ffmpeg", args...)
 }
 
-func (generator VideoGenerator) createPartSeven(profilePicturePath string, outputFilePath string) error {
-	return execCommand(
-		"ffmpeg",
-		"-i",
-		filepath.Join(generator.resourceDir, PART_7_VIDEO),
-		"-i",
-		profilePicturePath,
-		"-i",
-		filepath.Join(generator.resourceDir, TRANSPARENT_IMAGE),
-		"-filter_complex",
-		convertToOneLine(fmt.Sprintf(PART_7_FILTER, VIDEO_WIDTH, VIDEO_HEIGHT)),
-		outputFilePath,
-	)
+func (generator VideoGenerator) resolvePath(file string, placeholders *strings.Replacer) string {
+	path := placeholders.Replace(file)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(generator.resourceDir, path)
 }
 
-func (generator VideoGenerator) mergeVideos(files []string, tempDir string, outputFilePath string) error {
-	part1Video := filepath.Join(generator.resourceDir, PART_1_VIDEO)
-	part3Video := filepath.Join(generator.resourceDir, PART_3_VIDEO)
-	allFiles := make([]string, 3, 7)
-	allFiles[0] = part1Video
-	allFiles[1] = files[0]
-	allFiles[2] = part3Video
-	allFiles = append(allFiles, files[1:]...)
+func mergeVideos(files []string, tempDir string, outputFilePath string) error {
 	listFilePath := filepath.Join(tempDir, "videos.txt")
-	err := createFileList(allFiles, listFilePath)
+	err := createFileList(files, listFilePath)
 	if err != nil {
 		common.ErrorLogger.Printf("Failed to create a file list: %v\n", err)
 		return err
 	}
-	err = execCommand(
+	return execCommand(
 		"ffmpeg",
 		"-f",
 		"concat",
@@ -205,10 +251,6 @@ func (generator VideoGenerator) mergeVideos(files []string, tempDir string, outp
 		"copy",
 		outputFilePath,
 	)
-	if err != nil {
-		return err
-	}
-	return nil
 }
 
 func createFileList(files []string, listFilePath string) error {
@@ -221,13 +263,13 @@ func createFileList(files []string, listFilePath string) error {
 	return os.WriteFile(listFilePath, fileList, fs.ModePerm)
 }
 
-func (generator VideoGenerator) addAudio(videoFilePath string, outputFilePath string) error {
+func addAudio(videoFilePath string, audioFilePath string, outputFilePath string) error {
 	return execCommand(
 		"ffmpeg",
 		"-i",
 		videoFilePath,
 		"-i",
-		filepath.Join(generator.resourceDir, AUDIO_TRACK),
+		audioFilePath,
 		"-c:v",
 		"copy",
 		"-map",
@@ -246,7 +288,3 @@ func execCommand(name string, arg ...string) error {
 	}
 	return nil
 }
-
-func convertToOneLine(str string) string {
-	return strings.ReplaceAll(str, "\\\n", "")
-}
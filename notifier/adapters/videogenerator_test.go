@@ -0,0 +1,121 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Video generator", func() {
+	var resourceDir string
+	var generator *VideoGenerator
+
+	writeManifest := func(manifest string) {
+		Expect(os.WriteFile(filepath.Join(resourceDir, TEMPLATES_DIR, "test.json"), []byte(manifest), 0o644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		resourceDir = GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(resourceDir, TEMPLATES_DIR), 0o755)).To(Succeed())
+		generator = NewVideoGenerator(resourceDir, "test")
+	})
+
+	Describe("loading templates", func() {
+		It("should load a valid manifest", func() {
+			writeManifest(`{
+				"width": 872,
+				"height": 480,
+				"segments": [
+					{"inputs": [{"file": "intro.mp4"}]},
+					{"inputs": [{"file": "background.png", "loop": true}, {"file": "{{avatar}}"}], "filter": ["[0][1]", "overlay"], "duration": 2.5}
+				],
+				"audio": "audio.mp3"
+			}`)
+
+			template, err := generator.loadTemplate()
+
+			Expect(err).To(BeNil())
+			Expect(template).To(Equal(VideoTemplate{
+				Width:  872,
+				Height: 480,
+				Segments: []VideoSegment{
+					{Inputs: []VideoInput{{File: "intro.mp4"}}},
+					{Inputs: []VideoInput{{File: "background.png", Loop: true}, {File: "{{avatar}}"}}, Filter: []string{"[0][1]", "overlay"}, Duration: 2.5},
+				},
+				Audio: "audio.mp3",
+			}))
+		})
+
+		It("should fail when there's no manifest", func() {
+			_, err := generator.loadTemplate()
+
+			Expect(err).To(Not(BeNil()))
+		})
+
+		It("should not read a manifest outside of the templates", func() {
+			Expect(os.WriteFile(filepath.Join(resourceDir, "test.json"), []byte(`{"width": 1, "height": 1, "segments": [{"inputs": [{"file": "a.mp4"}]}]}`), 0o644)).To(Succeed())
+			generator = NewVideoGenerator(resourceDir, "../test")
+			writeManifest(`{}`)
+
+			_, err := generator.loadTemplate()
+
+			Expect(err).To(Not(BeNil()))
+		})
+
+		DescribeTable("should reject an invalid manifest",
+			func(manifest string) {
+				writeManifest(manifest)
+
+				_, err := generator.loadTemplate()
+
+				Expect(err).To(Not(BeNil()))
+			},
+			Entry("when it isn't json", `width: 872`),
+			Entry("without a size", `{"segments": [{"inputs": [{"file": "a.mp4"}]}]}`),
+			Entry("with a negative size", `{"width": -1, "height": 480, "segments": [{"inputs": [{"file": "a.mp4"}]}]}`),
+			Entry("without segments", `{"width": 872, "height": 480, "segments": []}`),
+			Entry("with a segment without inputs", `{"width": 872, "height": 480, "segments": [{"inputs": [], "filter": ["null"]}]}`),
+			Entry("with many inputs without a filter", `{"width": 872, "height": 480, "segments": [{"inputs": [{"file": "a.mp4"}, {"file": "b.mp4"}]}]}`),
+		)
+	})
+
+	Describe("placeholders", func() {
+		template := VideoTemplate{Width: 872, Height: 480}
+
+		It("should put the profile picture, the name and the size in place of the placeholders", func() {
+			placeholders := getPlaceholders(template, "/tmp/avatar.png", "Haruhi Suzumiya")
+
+			Expect(placeholders.Replace("[1]scale={{width}}:{{height}},drawtext=text={{name}}")).To(Equal("[1]scale=872:480,drawtext=text=Haruhi Suzumiya"))
+			Expect(placeholders.Replace("{{avatar}}")).To(Equal("/tmp/avatar.png"))
+		})
+
+		It("should resolve the files of a segment without a filter", func() {
+			segment := VideoSegment{Inputs: []VideoInput{{File: "{{avatar}}"}}}
+
+			path, err := generator.renderSegment(segment, getPlaceholders(template, "/tmp/avatar.png", ""), "output.mp4")
+
+			Expect(err).To(BeNil())
+			Expect(path).To(Equal("/tmp/avatar.png"))
+		})
+
+		It("should resolve relative files against the resources", func() {
+			path := generator.resolvePath("intro.mp4", getPlaceholders(template, "", ""))
+
+			Expect(path).To(Equal(filepath.Join(resourceDir, "intro.mp4")))
+		})
+
+		DescribeTable("should escape the name for drawtext",
+			func(name string, expectedText string) {
+				Expect(escapeDrawtext(name)).To(Equal(expectedText))
+			},
+			Entry("for a plain name", "Zoë Łukasz", "Zoë Łukasz"),
+			Entry("for an apostrophe", "O'Brien", `O\\\'Brien`),
+			Entry("for a colon", "a:b", `a\\:b`),
+			Entry("for a percent sign", "100%", `100\\\\%`),
+			Entry("for a backslash", `a\b`, `a\\\\\\\\b`),
+			Entry("for the separators of the filter graph", "[a],b;c", `\[a\]\,b\;c`),
+		)
+	})
+})
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+)
+
+// scheduleBelatedBirthdayNotifications catches up on the days missed since the last scheduled date,
+// going back at most catchUpLookbackDays. Nothing is caught up on the very first run.
+func (notifier *BirthdayNotifier) scheduleBelatedBirthdayNotifications(ctx context.Context, today time.Time, serviceUrl string, summary *ScheduleSummary) error {
+	lastScheduledDate, err := notifier.repository.GetLastScheduledDate(ctx)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not get the last scheduled date, the catch-up will be retried: %v\n", err)
+		return err
+	}
+	if lastScheduledDate.IsZero() {
+		return nil
+	}
+	startOfToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	firstMissedDate := time.Date(lastScheduledDate.Year(), lastScheduledDate.Month(), lastScheduledDate.Day()+1, 0, 0, 0, 0, today.Location())
+	if earliestDate := startOfToday.AddDate(0, 0, -notifier.catchUpLookbackDays); firstMissedDate.Before(earliestDate) {
+		firstMissedDate = earliestDate
+	}
+	var errs []error
+	for date := firstMissedDate; date.Before(startOfToday); date = date.AddDate(0, 0, 1) {
+		birthdays, err := notifier.repository.GetBirthdays(ctx, date)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		chatIds, chatIdToBirthdays := groupBirthdaysByChat(birthdays)
+		for _, chatId := range chatIds {
+			errs = append(errs, notifier.schedule(ctx, Notification{
+				Kind:      NOTIFICATION_KIND_BIRTHDAY,
+				ChatId:    chatId,
+				Birthdays: chatIdToBirthdays[chatId],
+				Belated:   true,
+				Date:      date,
+			}, serviceUrl, summary))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsScheduledToday tells whether notifications were already scheduled today, e.g. before the process restarted.
+func (notifier *BirthdayNotifier) IsScheduledToday(ctx context.Context) (bool, error) {
+	lastScheduledDate, err := notifier.repository.GetLastScheduledDate(ctx)
+	if err != nil {
+		return false, err
+	}
+	today := notifier.clock.Now()
+	return lastScheduledDate.Year() == today.Year() && lastScheduledDate.YearDay() == today.YearDay(), nil
+}
+
+func (notifier *BirthdayNotifier) sendBelatedBirthdayNotification(ctx context.Context, birthdays []Birthday, date time.Time) error {
+	if date.IsZero() {
+		return errors.New("belated birthday notification must have a date")
+	}
+	if len(birthdays) > 1 {
+		return notifier.sendGroupBirthdayNotification(ctx, birthdays, date, BELATED_GROUP_BIRTHDAY_MESSAGE)
+	}
+	return notifier.sendBirthdayNotification(ctx, birthdays[0], date, BELATED_BIRTHDAY_MESSAGE)
+}
+
+const (
+	BELATED_BIRTHDAY_MESSAGE       = "Aah %s\nI'm so sorry I'm late, senpai! (｡•́︿•̀｡) Happy belated birthday! 🎂✨ I hope your day was as wonderful as you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+	BELATED_GROUP_BIRTHDAY_MESSAGE = "Aah %s\nI'm so sorry I'm late, senpais! (｡•́︿•̀｡) Happy belated birthday to all of you! 🎂✨ I hope your day was as wonderful as you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+)
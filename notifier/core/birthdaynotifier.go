@@ -2,94 +2,408 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/4Kaze/birthdaybot/common"
+	"golang.org/x/sync/singleflight"
 )
 
+// BirthdayNotifier is safe for concurrent use. At most maxConcurrentNotifications notifications are sent at once
+// and the others wait for a free worker.
 type BirthdayNotifier struct {
-	repository                Repository
-	scheduler                 BirthdayNotificationScheduler
-	telegram                  Telegram
-	fileDownloader            FileDownloader
-	videoGenerator            VideoGenerator
-	clock                     Clock
-	userIdToCachedVideoFileId map[int64]string
+	repository               Repository
+	scheduler                BirthdayNotificationScheduler
+	telegram                 Telegram
+	fileDownloader           FileDownloader
+	videoGenerator           VideoGenerator
+	avatarGenerator          AvatarGenerator
+	videoCache               VideoCache
+	ledger                   DeliveryLedger
+	clock                    Clock
+	catchUpLookbackDays      int
+	workers                  chan struct{}
+	videoRenders             singleflight.Group
+	eventVideosMutex         sync.Mutex
+	eventToCachedVideoFileId map[string]string
 }
 
-func NewBirthdayNotifier(repository Repository, telegram Telegram, scheduler BirthdayNotificationScheduler, fileDownloader FileDownloader, videoGenerator VideoGenerator, clock Clock) *BirthdayNotifier {
+func NewBirthdayNotifier(repository Repository, telegram Telegram, scheduler BirthdayNotificationScheduler, fileDownloader FileDownloader, videoGenerator VideoGenerator, avatarGenerator AvatarGenerator, videoCache VideoCache, ledger DeliveryLedger, clock Clock, maxConcurrentNotifications int, catchUpLookbackDays int) *BirthdayNotifier {
 	return &BirthdayNotifier{
-		repository:                repository,
-		scheduler:                 scheduler,
-		telegram:                  telegram,
-		fileDownloader:            fileDownloader,
-		videoGenerator:            videoGenerator,
-		clock:                     clock,
-		userIdToCachedVideoFileId: make(map[int64]string),
+		repository:               repository,
+		scheduler:                scheduler,
+		telegram:                 telegram,
+		fileDownloader:           fileDownloader,
+		videoGenerator:           videoGenerator,
+		avatarGenerator:          avatarGenerator,
+		videoCache:               videoCache,
+		ledger:                   ledger,
+		clock:                    clock,
+		catchUpLookbackDays:      catchUpLookbackDays,
+		workers:                  make(chan struct{}, max(maxConcurrentNotifications, 1)),
+		eventToCachedVideoFileId: make(map[string]string),
 	}
 }
 
-func (notifier BirthdayNotifier) ScheduleBirthdayNotifications(ctx context.Context, serviceUrl string) error {
+// ScheduleSummary counts the notifications of a single ScheduleBirthdayNotifications run.
+type ScheduleSummary struct {
+	Scheduled int
+	Failed    int
+}
+
+// ScheduleBirthdayNotifications schedules today's notifications and belated birthday notifications for the days
+// missed since the last run. A failure doesn't stop scheduling the rest - all failures are returned together
+// and today is recorded as scheduled only when there were none.
+func (notifier *BirthdayNotifier) ScheduleBirthdayNotifications(ctx context.Context, serviceUrl string) (ScheduleSummary, error) {
 	today := notifier.clock.Now()
+	summary := ScheduleSummary{}
+	errs := []error{
+		notifier.scheduleBelatedBirthdayNotifications(ctx, today, serviceUrl, &summary),
+		notifier.scheduleTodayBirthdayNotifications(ctx, today, serviceUrl, &summary),
+		notifier.scheduleEventNotifications(ctx, today, serviceUrl, &summary),
+		notifier.scheduleNameDayNotifications(ctx, today, serviceUrl, &summary),
+	}
+	if today.Day() == 1 {
+		errs = append(errs, notifier.scheduleDigestNotifications(ctx, today, serviceUrl, &summary))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return summary, err
+	}
+	return summary, notifier.repository.SaveScheduledDate(ctx, today)
+}
+
+func (notifier *BirthdayNotifier) scheduleTodayBirthdayNotifications(ctx context.Context, today time.Time, serviceUrl string, summary *ScheduleSummary) error {
 	todayBirthdays, err := notifier.repository.GetBirthdays(ctx, today)
 	if err != nil {
 		return err
 	}
-	for _, birthday := range todayBirthdays {
-		notifier.scheduler.Schedule(ctx, birthday, serviceUrl)
+	chatIds, chatIdToBirthdays := groupBirthdaysByChat(todayBirthdays)
+	var errs []error
+	for _, chatId := range chatIds {
+		errs = append(errs, notifier.schedule(ctx, Notification{
+			Kind:      NOTIFICATION_KIND_BIRTHDAY,
+			ChatId:    chatId,
+			Birthdays: chatIdToBirthdays[chatId],
+		}, serviceUrl, summary))
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-func (notifier BirthdayNotifier) SendBirthdayNotification(ctx context.Context, birthday Birthday) error {
-	if fileId, isCached := notifier.userIdToCachedVideoFileId[birthday.UserId]; isCached {
-		err := notifier.telegram.SendVideoFromFileId(ctx, birthday.ChatId, fileId)
-		if err != nil {
-			return err
-		}
-	} else {
-		err := notifier.generateAndSendVideo(ctx, birthday)
-		if err != nil {
-			return err
+// groupBirthdaysByChat returns the ids of chats in the order of their first birthday, so members sharing a birthday
+// in a chat are celebrated together.
+func groupBirthdaysByChat(birthdays []Birthday) ([]int64, map[int64][]Birthday) {
+	chatIds := make([]int64, 0)
+	chatIdToBirthdays := make(map[int64][]Birthday)
+	for _, birthday := range birthdays {
+		if _, exists := chatIdToBirthdays[birthday.ChatId]; !exists {
+			chatIds = append(chatIds, birthday.ChatId)
 		}
+		chatIdToBirthdays[birthday.ChatId] = append(chatIdToBirthdays[birthday.ChatId], birthday)
 	}
-	err := notifier.telegram.SendMessage(ctx, birthday.ChatId, fmt.Sprintf(BIRTHDAY_MESSAGE, birthday.Name))
-	if err != nil {
-		common.ErrorLogger.Printf("Could not send birthday message: %v\n", err)
+	return chatIds, chatIdToBirthdays
+}
+
+func (notifier *BirthdayNotifier) schedule(ctx context.Context, notification Notification, serviceUrl string, summary *ScheduleSummary) error {
+	if err := notifier.scheduler.Schedule(ctx, notification, serviceUrl); err != nil {
+		summary.Failed++
+		return fmt.Errorf("could not schedule %v notification for chat: %v: %w", notification.Kind, notification.ChatId, err)
 	}
+	summary.Scheduled++
 	return nil
 }
 
-func (notifier BirthdayNotifier) generateAndSendVideo(ctx context.Context, birthday Birthday) error {
-	profilePictureFileIds, err := notifier.telegram.GetProfilePictureFileIds(ctx, birthday.UserId)
+func (notifier *BirthdayNotifier) scheduleDigestNotifications(ctx context.Context, date time.Time, serviceUrl string, summary *ScheduleSummary) error {
+	digestBirthdays, err := notifier.repository.GetDigestBirthdays(ctx, date)
 	if err != nil {
 		return err
 	}
-	if len(profilePictureFileIds) > 0 {
-		linkToProfilePicture, err := notifier.telegram.GetFileLink(ctx, profilePictureFileIds[0])
-		if err != nil {
-			return err
+	chatIds, chatIdToBirthdays := groupBirthdaysByChat(digestBirthdays)
+	var errs []error
+	for _, chatId := range chatIds {
+		errs = append(errs, notifier.schedule(ctx, Notification{
+			Kind:      NOTIFICATION_KIND_DIGEST,
+			ChatId:    chatId,
+			Birthdays: chatIdToBirthdays[chatId],
+		}, serviceUrl, summary))
+	}
+	return errors.Join(errs...)
+}
+
+func (notifier *BirthdayNotifier) SendNotification(ctx context.Context, notification Notification) error {
+	select {
+	case notifier.workers <- struct{}{}:
+		defer func() { <-notifier.workers }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	err := notifier.sendNotification(ctx, notification)
+	if errors.Is(err, ErrChatUnavailable) {
+		return notifier.reportUnavailableChat(ctx, notification.ChatId, err)
+	}
+	return err
+}
+
+// reportUnavailableChat doesn't fail the notification, as retrying it would fail the same way.
+// It's only retried when the manager couldn't be told about the chat.
+func (notifier *BirthdayNotifier) reportUnavailableChat(ctx context.Context, chatId int64, cause error) error {
+	common.ErrorLogger.Printf("Chat: %v is no longer available: %v\n", chatId, cause)
+	if err := notifier.repository.ReportUnavailableChat(ctx, chatId, cause.Error()); err != nil {
+		return fmt.Errorf("could not report unavailable chat: %v: %w", chatId, err)
+	}
+	return nil
+}
+
+func (notifier *BirthdayNotifier) sendNotification(ctx context.Context, notification Notification) error {
+	switch notification.Kind {
+	case NOTIFICATION_KIND_BIRTHDAY:
+		if len(notification.Birthdays) == 0 {
+			return errors.New("birthday notification must contain at least one birthday")
+		}
+		if notification.Belated {
+			return notifier.sendBelatedBirthdayNotification(ctx, notification.Birthdays, notification.Date)
 		}
-		if len(linkToProfilePicture) == 0 {
-			return nil
+		if len(notification.Birthdays) > 1 {
+			return notifier.sendGroupBirthdayNotification(ctx, notification.Birthdays, notifier.clock.Now(), GROUP_BIRTHDAY_MESSAGE)
 		}
-		pathToImage, err := notifier.fileDownloader.Download(ctx, linkToProfilePicture)
+		return notifier.SendBirthdayNotification(ctx, notification.Birthdays[0])
+	case NOTIFICATION_KIND_DIGEST:
+		return notifier.sendDigestNotification(ctx, notification)
+	case NOTIFICATION_KIND_EVENT:
+		if len(notification.Events) != 1 {
+			return fmt.Errorf("event notification must contain exactly one event, has: %v", len(notification.Events))
+		}
+		return notifier.sendEventNotification(ctx, notification.Events[0])
+	case NOTIFICATION_KIND_NAME_DAY:
+		return notifier.sendNameDayNotification(ctx, notification)
+	default:
+		return fmt.Errorf("unknown notification kind: %v", notification.Kind)
+	}
+}
+
+func (notifier *BirthdayNotifier) sendDigestNotification(ctx context.Context, notification Notification) error {
+	if len(notification.Birthdays) == 0 {
+		return nil
+	}
+	birthdays := slices.Clone(notification.Birthdays)
+	slices.SortStableFunc(birthdays, func(a Birthday, b Birthday) int {
+		return a.Date.Compare(b.Date)
+	})
+	var lines strings.Builder
+	for _, birthday := range birthdays {
+		lines.WriteString(fmt.Sprintf(DIGEST_LINE, birthday.Date.Format(DIGEST_DATE_LAYOUT), birthday.Name))
+	}
+	month := birthdays[0].Date.Month()
+	return notifier.telegram.SendMessage(ctx, notification.ChatId, fmt.Sprintf(DIGEST_MESSAGE, month, lines.String()))
+}
+
+func (notifier *BirthdayNotifier) SendBirthdayNotification(ctx context.Context, birthday Birthday) error {
+	return notifier.sendBirthdayNotification(ctx, birthday, notifier.clock.Now(), BIRTHDAY_MESSAGE)
+}
+
+// sendBirthdayNotification records every completed step in the delivery ledger and skips the steps already completed
+// for the birthday on the given date, so a retried notification doesn't send the video or the message again.
+func (notifier *BirthdayNotifier) sendBirthdayNotification(ctx context.Context, birthday Birthday, date time.Time, messageTemplate string) error {
+	delivery := Delivery{
+		ChatId: birthday.ChatId,
+		UserId: birthday.UserId,
+		Date:   date,
+		Kind:   NOTIFICATION_KIND_BIRTHDAY,
+	}
+	completedSteps, err := notifier.ledger.GetCompletedSteps(ctx, delivery)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(completedSteps, DELIVERY_STEP_VIDEO) {
+		fileId, err := notifier.sendVideo(ctx, birthday)
 		if err != nil {
 			return err
 		}
-		pathToVideo, err := notifier.videoGenerator.CreateVideo(pathToImage)
-		if err != nil {
+		if len(fileId) > 0 {
+			notifier.archiveVideo(ctx, birthday, date, fileId)
+		}
+		notifier.completeStep(ctx, delivery, DELIVERY_STEP_VIDEO)
+	}
+	if !slices.Contains(completedSteps, DELIVERY_STEP_MESSAGE) {
+		err := notifier.telegram.SendMessage(ctx, birthday.ChatId, fmt.Sprintf(messageTemplate, birthday.Name))
+		if errors.Is(err, ErrChatUnavailable) {
 			return err
 		}
-		fileId, err := notifier.telegram.SendVideo(ctx, birthday.ChatId, pathToVideo)
 		if err != nil {
-			return err
+			common.ErrorLogger.Printf("Could not send birthday message: %v\n", err)
+		} else {
+			notifier.completeStep(ctx, delivery, DELIVERY_STEP_MESSAGE)
 		}
-		notifier.userIdToCachedVideoFileId[birthday.UserId] = fileId
+	}
+	if !slices.Contains(completedSteps, DELIVERY_STEP_WISHES) {
+		notifier.sendWishes(ctx, birthday, date)
+		notifier.completeStep(ctx, delivery, DELIVERY_STEP_WISHES)
 	}
 	return nil
 }
 
+func (notifier *BirthdayNotifier) completeStep(ctx context.Context, delivery Delivery, step DeliveryStep) {
+	if err := notifier.ledger.CompleteStep(ctx, delivery, step); err != nil {
+		common.ErrorLogger.Printf("Could not record step: %v of delivery: %v due to: %v\n", step, delivery, err)
+	}
+}
+
+// sendVideo sends the video cached for the user's current profile picture and name or generates a new one when either changed.
+// Users without a profile picture get a video with an avatar of their initials, cached until their initials change.
+// It returns the fileId of the sent video.
+func (notifier *BirthdayNotifier) sendVideo(ctx context.Context, birthday Birthday) (string, error) {
+	profilePicture, err := notifier.getProfilePicture(ctx, birthday)
+	if err != nil {
+		return "", err
+	}
+	if len(profilePicture.FileId) == 0 {
+		profilePicture.FileUniqueId = AVATAR_PICTURE_ID_PREFIX + getInitials(birthday)
+	}
+	videoKey := getVideoKey(profilePicture.FileUniqueId, getPlainName(birthday))
+	cachedFileId, err := notifier.videoCache.Get(ctx, birthday.UserId, videoKey)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not get cached video of user: %v due to: %v\n", birthday.UserId, err)
+	}
+	if len(cachedFileId) > 0 {
+		return cachedFileId, notifier.telegram.SendVideoFromFileId(ctx, birthday.ChatId, cachedFileId)
+	}
+	// Notifications for the same user in different chats share a single render. The first one uploads the video
+	// and the others send it by its fileId once it's done.
+	isRenderedHere := false
+	renderKey := fmt.Sprintf("%v:%v", birthday.UserId, videoKey)
+	result, err, _ := notifier.videoRenders.Do(renderKey, func() (any, error) {
+		isRenderedHere = true
+		fileId, err := notifier.generateAndSendVideo(ctx, birthday, profilePicture)
+		if err != nil || len(fileId) == 0 {
+			return fileId, err
+		}
+		if err := notifier.videoCache.Put(ctx, birthday.UserId, videoKey, fileId); err != nil {
+			common.ErrorLogger.Printf("Could not cache video of user: %v due to: %v\n", birthday.UserId, err)
+		}
+		return fileId, nil
+	})
+	if err != nil && !isRenderedHere && errors.Is(err, ErrChatUnavailable) {
+		// The render was uploaded to another chat, so this one may still be available.
+		return "", fmt.Errorf("could not render video in another chat: %v", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	fileId := result.(string)
+	if isRenderedHere || len(fileId) == 0 {
+		return fileId, nil
+	}
+	return fileId, notifier.telegram.SendVideoFromFileId(ctx, birthday.ChatId, fileId)
+}
+
+// getProfilePicture returns the picture the user chose for the chat or their first profile picture.
+// The picture is empty when they have neither. A custom picture that can't be fetched falls back to the profile picture.
+func (notifier *BirthdayNotifier) getProfilePicture(ctx context.Context, birthday Birthday) (ProfilePicture, error) {
+	customPicture, err := notifier.repository.GetCustomPicture(ctx, birthday.ChatId, birthday.UserId)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not get custom picture of user: %v due to: %v\n", birthday.UserId, err)
+	} else if customPicture != nil {
+		return *customPicture, nil
+	}
+	profilePictures, err := notifier.telegram.GetProfilePictures(ctx, birthday.UserId)
+	if err != nil || len(profilePictures) == 0 {
+		return ProfilePicture{}, err
+	}
+	return profilePictures[0], nil
+}
+
+func (notifier *BirthdayNotifier) generateAndSendVideo(ctx context.Context, birthday Birthday, profilePicture ProfilePicture) (string, error) {
+	pathToImage, err := notifier.getPathToImage(ctx, birthday, profilePicture)
+	if err != nil {
+		return "", err
+	}
+	pathToVideo, err := notifier.videoGenerator.CreateVideo(pathToImage, getPlainName(birthday))
+	if err != nil {
+		return "", err
+	}
+	return notifier.telegram.SendVideo(ctx, birthday.ChatId, pathToVideo)
+}
+
+// getPathToImage downloads the profile picture or creates an avatar in its place when there's none or it can't be downloaded.
+func (notifier *BirthdayNotifier) getPathToImage(ctx context.Context, birthday Birthday, profilePicture ProfilePicture) (string, error) {
+	if len(profilePicture.FileId) == 0 {
+		return notifier.avatarGenerator.CreateAvatar(birthday.UserId, getInitials(birthday))
+	}
+	linkToProfilePicture, err := notifier.telegram.GetFileLink(ctx, profilePicture.FileId)
+	if err != nil {
+		return "", err
+	}
+	if len(linkToProfilePicture) == 0 {
+		return notifier.avatarGenerator.CreateAvatar(birthday.UserId, getInitials(birthday))
+	}
+	return notifier.fileDownloader.Download(ctx, linkToProfilePicture)
+}
+
+// getInitials returns the first letters of the user's first and last name.
+func getInitials(birthday Birthday) string {
+	name := getPlainName(birthday)
+	initials := make([]rune, 0, AVATAR_MAX_INITIALS)
+	for _, word := range strings.Fields(name) {
+		for _, character := range word {
+			if unicode.IsLetter(character) || unicode.IsDigit(character) {
+				initials = append(initials, unicode.ToUpper(character))
+				break
+			}
+		}
+		if len(initials) == AVATAR_MAX_INITIALS {
+			break
+		}
+	}
+	return string(initials)
+}
+
+// getPlainName returns the user's first and last name without the mention around them. Notifications queued before
+// the plain name was sent only have the name to display, so it's used without its markup instead.
+func getPlainName(birthday Birthday) string {
+	name := strings.TrimSpace(birthday.FirstName + " " + birthday.LastName)
+	if len(name) == 0 {
+		name = html.UnescapeString(HTML_TAG_REGEXP.ReplaceAllString(birthday.Name, ""))
+	}
+	return name
+}
+
+// getVideoKey identifies a rendered video in the caches. The video can show the name, so it's rendered again when the name changes.
+func getVideoKey(pictureId string, name string) string {
+	return pictureId + VIDEO_KEY_SEPARATOR + name
+}
+
+// archiveVideo stores the sent video so the user can watch it again in a private chat. The notification doesn't fail when it can't be stored.
+func (notifier *BirthdayNotifier) archiveVideo(ctx context.Context, birthday Birthday, date time.Time, fileId string) {
+	video := Video{
+		ChatId: birthday.ChatId,
+		UserId: birthday.UserId,
+		Year:   date.Year(),
+		FileId: fileId,
+	}
+	if err := notifier.repository.SaveVideo(ctx, video); err != nil {
+		common.ErrorLogger.Printf("Could not archive birthday video: %v due to: %v\n", video, err)
+	}
+}
+
+const (
+	DIGEST_DATE_LAYOUT = "2 Jan"
+	// AVATAR_PICTURE_ID_PREFIX makes up a unique id of the avatar for the video cache, as it's not a Telegram file.
+	AVATAR_PICTURE_ID_PREFIX = "avatar:"
+	VIDEO_KEY_SEPARATOR      = ":"
+	AVATAR_MAX_INITIALS      = 2
+)
+
+// HTML_TAG_REGEXP matches the tags of the mention in the name to display.
+var HTML_TAG_REGEXP = regexp.MustCompile(`<[^>]*>`)
+
 const (
+	DIGEST_MESSAGE   = "Ohayo, minna! 📅 Here are the birthdays coming up in %v:\n\n%s\nDon't forget to wish them well, senpai! (｡•̀ᴗ-)✧"
+	DIGEST_LINE      = "🎂 %s — %s\n"
 	BIRTHDAY_MESSAGE = "Aah %s\nHappy birthday, senpai! 🎂✨ I hope your day is as wonderful as you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
 )
@@ -3,6 +3,9 @@ package core_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/4Kaze/birthdaybot/notifier/core"
@@ -17,8 +20,11 @@ var _ = Describe("Birthday notifier", func() {
 	var scheduler FakeBirthdayScheduler
 	var fileDownloader FakeFileDownloader
 	var videoGenerator FakeVideoGenerator
+	var avatarGenerator FakeAvatarGenerator
+	var videoCache FakeVideoCache
+	var ledger FakeDeliveryLedger
 
-	var notifier core.BirthdayNotifier
+	var notifier *core.BirthdayNotifier
 
 	BeforeEach(func() {
 		repository = FakeRepository{}
@@ -26,7 +32,10 @@ var _ = Describe("Birthday notifier", func() {
 		scheduler = FakeBirthdayScheduler{}
 		fileDownloader = FakeFileDownloader{}
 		videoGenerator = FakeVideoGenerator{}
-		notifier = *core.NewBirthdayNotifier(&repository, &telegram, &scheduler, &fileDownloader, &videoGenerator, &clock)
+		avatarGenerator = FakeAvatarGenerator{avatarPathToReturn: AVATAR_PATH}
+		videoCache = FakeVideoCache{}
+		ledger = FakeDeliveryLedger{}
+		notifier = core.NewBirthdayNotifier(&repository, &telegram, &scheduler, &fileDownloader, &videoGenerator, &avatarGenerator, &videoCache, &ledger, &clock, MAX_CONCURRENT_NOTIFICATIONS, CATCH_UP_LOOKBACK_DAYS)
 	})
 
 	Describe("scheduling", func() {
@@ -46,16 +55,16 @@ var _ = Describe("Birthday notifier", func() {
 			repository.thereAre(birthday1, birthday2)
 
 			// when
-			result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
 
 			// then
 			Expect(result).To(BeNil())
 			Expect(repository.requestedDates).To(HaveExactElements(NOW))
-			Expect(scheduler.scheduledTasks).To(ContainElements(ScheduledTask{birthday1, SERVICE_URL}, ScheduledTask{birthday2, SERVICE_URL}))
+			Expect(scheduler.scheduledTasks).To(ContainElements(birthdayTask(birthday1), birthdayTask(birthday2)))
 
 		})
 
-		It("should schedule a birthday notification for multiple birthdays in a group", func() {
+		It("should schedule a single birthday notification for multiple birthdays in a group", func() {
 			// given
 			clock.now = NOW
 			birthday1 := core.Birthday{
@@ -71,12 +80,14 @@ var _ = Describe("Birthday notifier", func() {
 			repository.thereAre(birthday1, birthday2)
 
 			// when
-			result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
 
 			// then
 			Expect(result).To(BeNil())
 			Expect(repository.requestedDates).To(HaveExactElements(NOW))
-			Expect(scheduler.scheduledTasks).To(ContainElements(ScheduledTask{birthday1, SERVICE_URL}, ScheduledTask{birthday2, SERVICE_URL}))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_BIRTHDAY, ChatId: CHAT_ID_1, Birthdays: []core.Birthday{birthday1, birthday2}}, SERVICE_URL},
+			))
 
 		})
 
@@ -86,7 +97,7 @@ var _ = Describe("Birthday notifier", func() {
 			repository.thereAreNoBirthdays()
 
 			// when
-			result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
 
 			// then
 			Expect(result).To(BeNil())
@@ -100,171 +111,1490 @@ var _ = Describe("Birthday notifier", func() {
 			repository.shouldFail = true
 
 			// when
-			result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
 
 			// then
 			Expect(repository.requestedDates).To(HaveExactElements(NOW))
 			Expect(result).To(Not(BeNil()))
 		})
-	})
 
-	Describe("notifying", func() {
-		It("should generate a video and send a birthday message for birthday", func() {
+		It("should return a summary of scheduled notifications", func() {
+			// given
+			clock.now = NOW
+			birthday1 := core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1}
+			birthday2 := core.Birthday{ChatId: CHAT_ID_2, UserId: USER_ID_1, Name: USER_NAME_1}
+			repository.thereAre(birthday1, birthday2)
+
+			// when
+			summary, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(summary).To(Equal(core.ScheduleSummary{Scheduled: 2, Failed: 0}))
+		})
+
+		It("should schedule the remaining notifications and return an error when scheduling one fails", func() {
+			// given
+			clock.now = NOW
+			birthday1 := core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1}
+			birthday2 := core.Birthday{ChatId: CHAT_ID_2, UserId: USER_ID_1, Name: USER_NAME_1}
+			repository.thereAre(birthday1, birthday2)
+			scheduler.chatIdToFail = CHAT_ID_1
+
+			// when
+			summary, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(summary).To(Equal(core.ScheduleSummary{Scheduled: 1, Failed: 1}))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(birthdayTask(birthday2)))
+			Expect(repository.savedScheduledDates).To(BeEmpty())
+		})
+
+		It("should schedule events and name days when fetching birthdays fails", func() {
+			// given
+			clock.now = NOW
+			repository.shouldFail = true
+			event := core.Event{ChatId: CHAT_ID_1, Name: EVENT_NAME, Kind: EVENT_KIND_PET, Date: EVENT_DATE}
+			repository.events = []core.Event{event}
+
+			// when
+			summary, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(summary).To(Equal(core.ScheduleSummary{Scheduled: 1, Failed: 0}))
+			Expect(repository.requestedEventDates).To(HaveExactElements(NOW))
+			Expect(repository.requestedNameDayDates).To(HaveExactElements(NOW))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_EVENT, ChatId: CHAT_ID_1, Events: []core.Event{event}}, SERVICE_URL},
+			))
+		})
+
+		It("should schedule a digest notification per chat on the first day of the month", func() {
 			// given
+			clock.now = FIRST_DAY_OF_MONTH
 			birthday := core.Birthday{
 				ChatId: CHAT_ID_1,
 				UserId: USER_ID_1,
 				Name:   USER_NAME_1,
 			}
+			repository.thereAre(birthday)
+			digestBirthday1 := core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, Date: DIGEST_DATE_1}
+			digestBirthday2 := core.Birthday{ChatId: CHAT_ID_2, UserId: USER_ID_1, Name: USER_NAME_1, Date: DIGEST_DATE_1}
+			digestBirthday3 := core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_2, Name: USER_NAME_2, Date: DIGEST_DATE_2}
+			repository.digestBirthdays = []core.Birthday{digestBirthday1, digestBirthday2, digestBirthday3}
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedDigestDates).To(HaveExactElements(FIRST_DAY_OF_MONTH))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(
+				birthdayTask(birthday),
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_DIGEST, ChatId: CHAT_ID_1, Birthdays: []core.Birthday{digestBirthday1, digestBirthday3}}, SERVICE_URL},
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_DIGEST, ChatId: CHAT_ID_2, Birthdays: []core.Birthday{digestBirthday2}}, SERVICE_URL},
+			))
+		})
+
+		It("should schedule a notification for every event of the day", func() {
+			// given
+			clock.now = NOW
+			repository.thereAreNoBirthdays()
+			event1 := core.Event{ChatId: CHAT_ID_1, Name: EVENT_NAME, Kind: EVENT_KIND_PET, Date: EVENT_DATE}
+			event2 := core.Event{ChatId: CHAT_ID_2, Name: EVENT_NAME, Kind: EVENT_KIND_FOUNDING, Date: EVENT_DATE}
+			repository.events = []core.Event{event1, event2}
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedEventDates).To(HaveExactElements(NOW))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_EVENT, ChatId: CHAT_ID_1, Events: []core.Event{event1}}, SERVICE_URL},
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_EVENT, ChatId: CHAT_ID_2, Events: []core.Event{event2}}, SERVICE_URL},
+			))
+		})
+
+		It("should return an error when fetching events fails", func() {
+			// given
+			clock.now = NOW
+			repository.shouldFailOnEvents = true
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+		})
+
+		It("should schedule one name day notification per chat", func() {
+			// given
+			clock.now = NOW
+			repository.thereAreNoBirthdays()
+			nameDay1 := core.NameDay{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY}
+			nameDay2 := core.NameDay{ChatId: CHAT_ID_2, UserId: USER_ID_1, Name: USER_NAME_1, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY}
+			nameDay3 := core.NameDay{ChatId: CHAT_ID_1, UserId: USER_ID_2, Name: USER_NAME_2, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY}
+			repository.nameDays = []core.NameDay{nameDay1, nameDay2, nameDay3}
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedNameDayDates).To(HaveExactElements(NOW))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_NAME_DAY, ChatId: CHAT_ID_1, NameDays: []core.NameDay{nameDay1, nameDay3}}, SERVICE_URL},
+				ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_NAME_DAY, ChatId: CHAT_ID_2, NameDays: []core.NameDay{nameDay2}}, SERVICE_URL},
+			))
+		})
+
+		It("should return an error when fetching name days fails", func() {
+			// given
+			clock.now = NOW
+			repository.shouldFailOnNameDays = true
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+		})
+
+		It("should not fetch digest birthdays on other days of the month", func() {
+			// given
+			clock.now = NOW
+			repository.thereAreNoBirthdays()
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedDigestDates).To(BeEmpty())
+		})
+
+		It("should return an error when fetching digest birthdays fails", func() {
+			// given
+			clock.now = FIRST_DAY_OF_MONTH
+			repository.shouldFailOnDigest = true
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(scheduler.scheduledTasks).To(BeEmpty())
+		})
+	})
+
+	Describe("catching up", func() {
+		var birthday core.Birthday
+
+		BeforeEach(func() {
+			clock.now = NOW
+			birthday = core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1}
+		})
+
+		It("should record today as scheduled", func() {
+			// given
+			repository.thereAre(birthday)
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.savedScheduledDates).To(HaveExactElements(NOW))
+		})
+
+		It("should schedule belated notifications for the days missed since the last run", func() {
+			// given
+			repository.thereAre(birthday)
+			repository.lastScheduledDate = MISSED_DATE_1.AddDate(0, 0, -1)
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedDates).To(HaveExactElements(MISSED_DATE_1, MISSED_DATE_2, NOW))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(
+				belatedBirthdayTask(birthday, MISSED_DATE_1),
+				belatedBirthdayTask(birthday, MISSED_DATE_2),
+				birthdayTask(birthday),
+			))
+		})
+
+		It("should not catch up on days older than the lookback", func() {
+			// given
+			repository.lastScheduledDate = NOW.AddDate(0, 0, -30)
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedDates).To(HaveLen(CATCH_UP_LOOKBACK_DAYS + 1))
+			Expect(repository.requestedDates[0]).To(Equal(time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("should not catch up when today was already scheduled", func() {
+			// given
+			repository.thereAre(birthday)
+			repository.lastScheduledDate = time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedDates).To(HaveExactElements(NOW))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(birthdayTask(birthday)))
+		})
+
+		It("should schedule today's notifications and fail to be retried when the last scheduled date is unavailable", func() {
+			// given
+			repository.thereAre(birthday)
+			repository.shouldFailOnLastScheduledDate = true
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(scheduler.scheduledTasks).To(HaveExactElements(birthdayTask(birthday)))
+			Expect(repository.savedScheduledDates).To(BeEmpty())
+		})
+
+		DescribeTable("should tell whether today was already scheduled", func(lastScheduledDate time.Time, expected bool) {
+			// given
+			repository.lastScheduledDate = lastScheduledDate
+
+			// when
+			result, err := notifier.IsScheduledToday(context.Background())
+
+			// then
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(expected))
+		},
+			Entry("when it was", time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC), true),
+			Entry("when the last scheduled date is yesterday", time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC), false),
+			Entry("when the last scheduled date is a year ago", time.Date(2023, 3, 14, 0, 0, 0, 0, time.UTC), false),
+			Entry("when nothing was scheduled yet", time.Time{}, false),
+		)
+
+		It("should not tell today was scheduled when the last scheduled date is unavailable", func() {
+			// given
+			repository.shouldFailOnLastScheduledDate = true
+
+			// when
+			_, err := notifier.IsScheduledToday(context.Background())
+
+			// then
+			Expect(err).To(Not(BeNil()))
+		})
+
+		It("should not record today as scheduled when scheduling fails", func() {
+			// given
+			repository.shouldFail = true
+
+			// when
+			_, result := notifier.ScheduleBirthdayNotifications(context.Background(), SERVICE_URL)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(repository.savedScheduledDates).To(BeEmpty())
+		})
+
+		It("should send a belated birthday notification for the missed day", func() {
+			// given
 			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			fileDownloader.filePathToReturn = PICTURE_PATH
 			videoGenerator.videoPathToReturn = VIDEO_PATH
+			telegram.videoFileIdToReturn = FILE_ID_2
+			lastYearMissedDate := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+			notification := core.Notification{
+				Kind:      core.NOTIFICATION_KIND_BIRTHDAY,
+				ChatId:    CHAT_ID_1,
+				Birthdays: []core.Birthday{birthday},
+				Belated:   true,
+				Date:      lastYearMissedDate,
+			}
 
 			// when
-			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+			result := notifier.SendNotification(context.Background(), notification)
 
 			// then
 			Expect(result).To(BeNil())
-			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
-			Expect(telegram.fileLinkRequests).To(HaveExactElements(FILE_ID_1))
-			Expect(fileDownloader.requests).To(HaveExactElements(FILE_LINK))
-			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(PICTURE_PATH))
 			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
-			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BELATED_MESSAGE}))
+			Expect(repository.requestedWishes).To(HaveExactElements(RequestedWishes{chatId: CHAT_ID_1, userId: USER_ID_1, date: lastYearMissedDate}))
+			Expect(repository.savedVideos).To(HaveExactElements(core.Video{ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: 2023, FileId: FILE_ID_2}))
+			Expect(ledger.deliveryToSteps).To(HaveKey(core.Delivery{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: lastYearMissedDate, Kind: core.NOTIFICATION_KIND_BIRTHDAY}))
 		})
 
-		It("should generate the video only for the first profile picture", func() {
+		It("should not send a belated birthday notification without a date", func() {
+			// given
+			notification := core.Notification{
+				Kind:      core.NOTIFICATION_KIND_BIRTHDAY,
+				ChatId:    CHAT_ID_1,
+				Birthdays: []core.Birthday{birthday},
+				Belated:   true,
+			}
+
+			// when
+			result := notifier.SendNotification(context.Background(), notification)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+	})
+
+	Describe("sending notifications", func() {
+		It("should send a birthday notification", func() {
 			// given
 			birthday := core.Birthday{
 				ChatId: CHAT_ID_1,
 				UserId: USER_ID_1,
 				Name:   USER_NAME_1,
 			}
-			telegram.thereAreProfilePictureFileIds(FILE_ID_1, FILE_ID_2)
-			telegram.fileLinkToReturn = FILE_LINK
-			fileDownloader.filePathToReturn = PICTURE_PATH
+			telegram.thereAreNoProfilePictures()
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:      core.NOTIFICATION_KIND_BIRTHDAY,
+				ChatId:    CHAT_ID_1,
+				Birthdays: []core.Birthday{birthday},
+			})
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should send a digest message sorted by date", func() {
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_DIGEST,
+				ChatId: CHAT_ID_1,
+				Birthdays: []core.Birthday{
+					{ChatId: CHAT_ID_1, UserId: USER_ID_2, Name: USER_NAME_2, Date: DIGEST_DATE_2},
+					{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, Date: DIGEST_DATE_1},
+				},
+			})
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_DIGEST_MESSAGE}))
+			Expect(telegram.sentVideos).To(BeEmpty())
+		})
+
+		It("should return an error when sending a digest message fails", func() {
+			// given
+			telegram.shouldFailOnSendingMessage = true
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:      core.NOTIFICATION_KIND_DIGEST,
+				ChatId:    CHAT_ID_1,
+				Birthdays: []core.Birthday{{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, Date: DIGEST_DATE_1}},
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+		})
+
+		It("should send a video from the kind's template and a kind-specific message for an event", func() {
+			// given
+			clock.now = NOW
 			videoGenerator.videoPathToReturn = VIDEO_PATH
 
 			// when
-			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_EVENT,
+				ChatId: CHAT_ID_1,
+				Events: []core.Event{{ChatId: CHAT_ID_1, Name: EVENT_NAME, Kind: EVENT_KIND_PET, Date: EVENT_DATE}},
+			})
 
 			// then
 			Expect(result).To(BeNil())
-			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
-			Expect(telegram.fileLinkRequests).To(HaveExactElements(FILE_ID_1))
-			Expect(fileDownloader.requests).To(HaveExactElements(FILE_LINK))
-			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(PICTURE_PATH))
+			Expect(telegram.profilePictureRequests).To(BeEmpty())
+			Expect(videoGenerator.eventVideoGenerationRequests).To(HaveExactElements(EVENT_KIND_PET))
+			Expect(videoGenerator.eventVideoNames).To(HaveExactElements(EVENT_NAME))
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_PET_EVENT_MESSAGE}))
+		})
+
+		DescribeTable("should mention how many years passed since the event",
+			func(year int, expectedText string) {
+				// given
+				clock.now = NOW
+
+				// when
+				notifier.SendNotification(context.Background(), core.Notification{
+					Kind:   core.NOTIFICATION_KIND_EVENT,
+					ChatId: CHAT_ID_1,
+					Events: []core.Event{{ChatId: CHAT_ID_1, Name: EVENT_NAME, Kind: EVENT_KIND_FOUNDING, Date: EVENT_DATE, Year: year}},
+				})
+
+				// then
+				Expect(telegram.sentMessages).To(HaveLen(1))
+				Expect(telegram.sentMessages[0].text).To(ContainSubstring(expectedText))
+			},
+			Entry("for one year", NOW.Year()-1, "It's been <b>1 year</b> already!"),
+			Entry("for many years", NOW.Year()-5, "It's been <b>5 years</b> already!"),
+		)
+
+		It("should not mention years when the event year is unknown or this year", func() {
+			// given
+			clock.now = NOW
+
+			// when
+			notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_EVENT,
+				ChatId: CHAT_ID_1,
+				Events: []core.Event{{ChatId: CHAT_ID_1, Name: EVENT_NAME, Kind: EVENT_KIND_FOUNDING, Date: EVENT_DATE, Year: NOW.Year()}},
+			})
+
+			// then
+			Expect(telegram.sentMessages[0].text).To(Not(ContainSubstring("It's been")))
+		})
+
+		It("should escape event names and use a generic message for unknown kinds", func() {
+			// when
+			notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_EVENT,
+				ChatId: CHAT_ID_1,
+				Events: []core.Event{{ChatId: CHAT_ID_1, Name: "<b>Party</b>", Kind: "unknown", Date: EVENT_DATE}},
+			})
+
+			// then
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{
+				chatId: CHAT_ID_1,
+				text:   "Ehehe~ today is <b>&lt;b&gt;Party&lt;/b&gt;</b>! 🎉 Let's celebrate together, senpai! (≧◡≦)",
+			}))
+		})
+
+		It("should not reuse the video of another event of the same kind", func() {
+			// given
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			telegram.videoFileIdToReturn = FILE_ID_2
+			event := func(name string) core.Notification {
+				return core.Notification{
+					Kind:   core.NOTIFICATION_KIND_EVENT,
+					ChatId: CHAT_ID_1,
+					Events: []core.Event{{ChatId: CHAT_ID_1, Name: name, Kind: EVENT_KIND_PET, Date: EVENT_DATE}},
+				}
+			}
+
+			// when
+			notifier.SendNotification(context.Background(), event(EVENT_NAME))
+			notifier.SendNotification(context.Background(), event(EVENT_NAME_2))
+
+			// then
+			Expect(videoGenerator.eventVideoNames).To(HaveExactElements(EVENT_NAME, EVENT_NAME_2))
 		})
 
-		It("should not send a video when there is no profile picture", func() {
-			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			telegram.thereAreNoProfilePictures()
+		It("should reuse the video of the same event kind", func() {
+			// given
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			telegram.videoFileIdToReturn = FILE_ID_2
+			event := func(chatId int64) core.Notification {
+				return core.Notification{
+					Kind:   core.NOTIFICATION_KIND_EVENT,
+					ChatId: chatId,
+					Events: []core.Event{{ChatId: chatId, Name: EVENT_NAME, Kind: EVENT_KIND_PET, Date: EVENT_DATE}},
+				}
+			}
+
+			// when
+			notifier.SendNotification(context.Background(), event(CHAT_ID_1))
+			notifier.SendNotification(context.Background(), event(CHAT_ID_2))
+
+			// then
+			Expect(videoGenerator.eventVideoGenerationRequests).To(HaveLen(1))
+			Expect(telegram.sentVideos).To(HaveExactElements(
+				Video{chatId: CHAT_ID_1, path: VIDEO_PATH},
+				Video{chatId: CHAT_ID_2, path: FILE_ID_2},
+			))
+		})
+
+		It("should return an error when generating an event video fails", func() {
+			// given
+			videoGenerator.shouldFail = true
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_EVENT,
+				ChatId: CHAT_ID_1,
+				Events: []core.Event{{ChatId: CHAT_ID_1, Name: EVENT_NAME, Kind: EVENT_KIND_PET, Date: EVENT_DATE}},
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(telegram.sentVideos).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error for an event notification without an event", func() {
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_EVENT,
+				ChatId: CHAT_ID_1,
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+		})
+
+		It("should send a name day message with a wish in the calendar's language", func() {
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_NAME_DAY,
+				ChatId: CHAT_ID_1,
+				NameDays: []core.NameDay{
+					{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY},
+					{ChatId: CHAT_ID_1, UserId: USER_ID_2, Name: USER_NAME_2, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY},
+				},
+			})
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentVideos).To(BeEmpty())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_NAME_DAY_MESSAGE}))
+		})
+
+		It("should return an error when sending a name day message fails", func() {
+			// given
+			telegram.shouldFailOnSendingMessage = true
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:     core.NOTIFICATION_KIND_NAME_DAY,
+				ChatId:   CHAT_ID_1,
+				NameDays: []core.NameDay{{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY}},
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+		})
+
+		It("should return an error for an unknown notification kind", func() {
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   "unknown",
+				ChatId: CHAT_ID_1,
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error for a birthday notification without a birthday", func() {
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_BIRTHDAY,
+				ChatId: CHAT_ID_1,
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+	})
+
+	Describe("unavailable chats", func() {
+		It("should report the chat instead of failing when the bot can no longer post to it", func() {
+			// given
+			birthday := core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1}
+			telegram.thereAreNoProfilePictures()
+			telegram.unavailableChatId = CHAT_ID_1
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:      core.NOTIFICATION_KIND_BIRTHDAY,
+				ChatId:    CHAT_ID_1,
+				Birthdays: []core.Birthday{birthday},
+			})
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.reportedUnavailableChats).To(HaveExactElements(CHAT_ID_1))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should report the chat when sending an event video fails", func() {
+			// given
+			telegram.unavailableChatId = CHAT_ID_1
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:   core.NOTIFICATION_KIND_EVENT,
+				ChatId: CHAT_ID_1,
+				Events: []core.Event{{ChatId: CHAT_ID_1, Name: EVENT_NAME, Kind: EVENT_KIND_PET, Date: EVENT_DATE}},
+			})
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.reportedUnavailableChats).To(HaveExactElements(CHAT_ID_1))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error when reporting the chat fails", func() {
+			// given
+			telegram.unavailableChatId = CHAT_ID_1
+			repository.shouldFailOnReportingChat = true
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:     core.NOTIFICATION_KIND_NAME_DAY,
+				ChatId:   CHAT_ID_1,
+				NameDays: []core.NameDay{{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY}},
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+		})
+
+		It("should not report the chat on other errors", func() {
+			// given
+			telegram.shouldFailOnSendingMessage = true
+
+			// when
+			result := notifier.SendNotification(context.Background(), core.Notification{
+				Kind:     core.NOTIFICATION_KIND_NAME_DAY,
+				ChatId:   CHAT_ID_1,
+				NameDays: []core.NameDay{{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, NameDayName: NAME_DAY_NAME, Country: NAME_DAY_COUNTRY}},
+			})
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(repository.reportedUnavailableChats).To(BeEmpty())
+		})
+	})
+
+	Describe("notifying", func() {
+		It("should generate a video and send a birthday message for birthday", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			fileDownloader.filePathToReturn = PICTURE_PATH
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
+			Expect(telegram.fileLinkRequests).To(HaveExactElements(FILE_ID_1))
+			Expect(fileDownloader.requests).To(HaveExactElements(FILE_LINK))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(PICTURE_PATH))
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should put the plain name of the user on the video", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId:    CHAT_ID_1,
+				UserId:    USER_ID_1,
+				Name:      fmt.Sprintf(`<a href="tg://user?id=%v">%v</a>`, USER_ID_1, USER_FULL_NAME_1),
+				FirstName: USER_FIRST_NAME_1,
+				LastName:  USER_LAST_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(videoGenerator.videoNames).To(HaveExactElements(USER_FULL_NAME_1))
+		})
+
+		It("should put the name without the mention on the video when the plain name wasn't sent", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   fmt.Sprintf(`<a href="tg://user?id=%v">Tom &amp; Jerry</a>`, USER_ID_1),
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(videoGenerator.videoNames).To(HaveExactElements("Tom & Jerry"))
+		})
+
+		It("should generate a new video when the name of the user changed", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_2,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			videoCache.Put(context.Background(), USER_ID_1, getVideoKey(UNIQUE_ID_PREFIX+FILE_ID_1, USER_NAME_1), FILE_ID_2)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(videoGenerator.videoNames).To(HaveExactElements(USER_NAME_2))
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+		})
+
+		It("should generate the video only for the first profile picture", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereAreProfilePictureFileIds(FILE_ID_1, FILE_ID_2)
+			telegram.fileLinkToReturn = FILE_LINK
+			fileDownloader.filePathToReturn = PICTURE_PATH
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
+			Expect(telegram.fileLinkRequests).To(HaveExactElements(FILE_ID_1))
+			Expect(fileDownloader.requests).To(HaveExactElements(FILE_LINK))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(PICTURE_PATH))
+		})
+
+		It("should send a video with an avatar when there is no profile picture", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId:    CHAT_ID_1,
+				UserId:    USER_ID_1,
+				Name:      USER_NAME_1,
+				FirstName: USER_FIRST_NAME_1,
+				LastName:  USER_LAST_NAME_1,
+			}
+			telegram.thereAreNoProfilePictures()
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
+			Expect(telegram.fileLinkRequests).To(BeEmpty())
+			Expect(avatarGenerator.requests).To(HaveExactElements(USER_INITIALS_1))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(AVATAR_PATH))
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should send a video with an avatar when there is no link to profile picture file", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId:    CHAT_ID_1,
+				UserId:    USER_ID_1,
+				Name:      USER_NAME_1,
+				FirstName: USER_FIRST_NAME_1,
+				LastName:  USER_LAST_NAME_1,
+			}
+			telegram.thereAreProfilePictureFileIds(FILE_ID_1)
+			telegram.fileLinkToReturn = ""
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(fileDownloader.requests).To(BeEmpty())
+			Expect(avatarGenerator.requests).To(HaveExactElements(USER_INITIALS_1))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(AVATAR_PATH))
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should send the cached avatar video until the user's initials change", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId:    CHAT_ID_1,
+				UserId:    USER_ID_1,
+				Name:      USER_NAME_1,
+				FirstName: USER_FIRST_NAME_1,
+				LastName:  USER_LAST_NAME_1,
+			}
+			telegram.thereAreNoProfilePictures()
+			videoCache.Put(context.Background(), USER_ID_1, getVideoKey(core.AVATAR_PICTURE_ID_PREFIX+USER_INITIALS_1, USER_FULL_NAME_1), FILE_ID_2)
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+			birthday.ChatId = CHAT_ID_2
+			birthday.FirstName = USER_FIRST_NAME_2
+			birthday.LastName = USER_LAST_NAME_2
+			secondResult := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(secondResult).To(BeNil())
+			Expect(telegram.sentVideos).To(HaveExactElements(
+				Video{chatId: CHAT_ID_1, path: FILE_ID_2},
+				Video{chatId: CHAT_ID_2, path: VIDEO_PATH},
+			))
+			Expect(avatarGenerator.requests).To(HaveExactElements(USER_INITIALS_2))
+		})
+
+		It("should key the avatar video on the plain name rather than the displayed one", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId:    CHAT_ID_1,
+				UserId:    USER_ID_1,
+				Name:      fmt.Sprintf(`<a href="tg://user?id=%v">%v %v</a>`, USER_ID_1, USER_FIRST_NAME_1, USER_LAST_NAME_1),
+				FirstName: USER_FIRST_NAME_1,
+				LastName:  USER_LAST_NAME_1,
+			}
+			telegram.thereAreNoProfilePictures()
+			videoCache.Put(context.Background(), USER_ID_1, getVideoKey(core.AVATAR_PICTURE_ID_PREFIX+USER_INITIALS_1, USER_FULL_NAME_1), FILE_ID_2)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+			birthday.ChatId = CHAT_ID_2
+			birthday.Name = "@username"
+			secondResult := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(secondResult).To(BeNil())
+			Expect(telegram.sentVideos).To(HaveExactElements(
+				Video{chatId: CHAT_ID_1, path: FILE_ID_2},
+				Video{chatId: CHAT_ID_2, path: FILE_ID_2},
+			))
+			Expect(avatarGenerator.requests).To(BeEmpty())
+		})
+
+		It("should generate the video for the custom picture instead of the profile picture", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			repository.customPictures = map[int64]core.ProfilePicture{USER_ID_1: {FileId: FILE_ID_2, FileUniqueId: CUSTOM_PICTURE_UNIQUE_ID}}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			fileDownloader.filePathToReturn = PICTURE_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.profilePictureRequests).To(BeEmpty())
+			Expect(telegram.fileLinkRequests).To(HaveExactElements(FILE_ID_2))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(PICTURE_PATH))
+		})
+
+		It("should use the profile picture when fetching the custom picture fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			repository.shouldFailOnCustomPictures = true
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
+			Expect(telegram.fileLinkRequests).To(HaveExactElements(FILE_ID_1))
+		})
+
+		It("should return an error when creating an avatar fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereAreNoProfilePictures()
+			avatarGenerator.shouldFail = true
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error when fetching profile picture file id fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.shouldFailOnGettingProfilePicture = true
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
+			Expect(telegram.sentVideos).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error when fetching profile picture file link fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			telegram.shouldFailOnGettingFile = true
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
+			Expect(telegram.sentVideos).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error when downloading profile picture fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			fileDownloader.shouldFail = true
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
+			Expect(telegram.sentVideos).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error when generating a video fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.shouldFail = true
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(telegram.sentVideos).To(BeEmpty())
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should return an error when sending a video fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			telegram.shouldFailOnSendingVideoFromPath = true
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+
+		It("should not return an error when sending a birthday message fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			telegram.shouldFailOnSendingMessage = true
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentVideos).To(Not(BeEmpty()))
+		})
+
+		It("should deliver the wishes collected for the birthday after the birthday message", func() {
+			// given
+			clock.now = NOW
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			repository.wishes = []core.Wish{
+				{AuthorName: USER_NAME_2, Text: "Stay <awesome>!"},
+				{AuthorName: USER_NAME_2, VoiceFileId: VOICE_FILE_ID},
+				{AuthorName: USER_NAME_1, Text: "Happy birthday!"},
+			}
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.requestedWishes).To(HaveExactElements(RequestedWishes{chatId: CHAT_ID_1, userId: USER_ID_1, date: NOW}))
+			Expect(telegram.sentMessages).To(HaveExactElements(
+				Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE},
+				Message{chatId: CHAT_ID_1, text: EXPECTED_WISHES_MESSAGE},
+			))
+			Expect(telegram.sentVoices).To(HaveExactElements(Voice{chatId: CHAT_ID_1, fileId: VOICE_FILE_ID, caption: EXPECTED_VOICE_WISH_CAPTION}))
+		})
+
+		It("should split the wishes into several messages when they don't fit in one", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			longWish := strings.Repeat("a", 1000)
+			for range 5 {
+				repository.wishes = append(repository.wishes, core.Wish{AuthorName: USER_NAME_2, Text: longWish})
+			}
+			expectedLine := fmt.Sprintf("💬 %v: %v\n", USER_NAME_2, longWish)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentMessages).To(HaveExactElements(
+				Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE},
+				Message{chatId: CHAT_ID_1, text: EXPECTED_WISHES_HEADER + strings.Repeat(expectedLine, 3)},
+				Message{chatId: CHAT_ID_1, text: strings.Repeat(expectedLine, 2)},
+			))
+			for _, message := range telegram.sentMessages {
+				Expect(len([]rune(message.text))).To(BeNumerically("<=", core.TELEGRAM_MAX_MESSAGE_LENGTH))
+			}
+		})
+
+		It("should not return an error when fetching wishes fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			repository.shouldFailOnWishes = true
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+			Expect(telegram.sentVoices).To(BeEmpty())
+		})
+
+		It("should reuse video's fileId when sending birthday notifications for the same user", func() {
+			// given
+			birthday1 := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			birthday2 := core.Birthday{
+				ChatId: CHAT_ID_2,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			clock.now = NOW
+			telegram.videoFileIdToReturn = FILE_ID_2
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			notifier.SendBirthdayNotification(context.Background(), birthday1)
+			notifier.SendBirthdayNotification(context.Background(), birthday2)
+
+			// then
+			Expect(videoGenerator.videoGenerationRequests).To(HaveLen(1))
+			Expect(telegram.sentVideos).To(HaveExactElements(
+				Video{chatId: CHAT_ID_1, path: VIDEO_PATH},
+				Video{chatId: CHAT_ID_2, path: FILE_ID_2},
+			))
+			Expect(telegram.sentMessages).To(HaveExactElements(
+				Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE},
+				Message{chatId: CHAT_ID_2, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE},
+			))
+			Expect(repository.savedVideos).To(HaveExactElements(
+				core.Video{ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: NOW.Year(), FileId: FILE_ID_2},
+				core.Video{ChatId: CHAT_ID_2, UserId: USER_ID_1, Year: NOW.Year(), FileId: FILE_ID_2},
+			))
+		})
+
+		It("should not archive a video when there is no profile picture", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(repository.savedVideos).To(BeEmpty())
+		})
+
+		It("should not return an error when archiving a video fails", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			telegram.videoFileIdToReturn = FILE_ID_2
+			repository.shouldFailOnVideos = true
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should reuse the cached video after the notifier restarts", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoCache.Put(context.Background(), USER_ID_1, getVideoKey(UNIQUE_ID_PREFIX+FILE_ID_1, USER_NAME_1), FILE_ID_2)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
+			Expect(telegram.fileLinkRequests).To(BeEmpty())
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: FILE_ID_2}))
+		})
+
+		It("should generate a new video when the profile picture changed", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			telegram.videoFileIdToReturn = VIDEO_FILE_ID
+			fileDownloader.filePathToReturn = PICTURE_PATH
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			videoCache.Put(context.Background(), USER_ID_1, getVideoKey(UNIQUE_ID_PREFIX+FILE_ID_2, USER_NAME_1), FILE_ID_2)
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(videoGenerator.videoGenerationRequests).To(HaveExactElements(PICTURE_PATH))
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			fileId, _ := videoCache.Get(context.Background(), USER_ID_1, getVideoKey(UNIQUE_ID_PREFIX+FILE_ID_1, USER_NAME_1))
+			Expect(fileId).To(Equal(VIDEO_FILE_ID))
+		})
+
+		It("should generate the video when the cache is unavailable", func() {
+			// given
+			birthday := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+			videoCache.shouldFail = true
+
+			// when
+			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should return an error when sending video from fileId fails", func() {
+			// given
+			birthday1 := core.Birthday{
+				ChatId: CHAT_ID_1,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			birthday2 := core.Birthday{
+				ChatId: CHAT_ID_2,
+				UserId: USER_ID_1,
+				Name:   USER_NAME_1,
+			}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			telegram.videoFileIdToReturn = FILE_ID_2
+			telegram.shouldFailOnSendingVideoFromFileId = true
+
+			// when
+			result1 := notifier.SendBirthdayNotification(context.Background(), birthday1)
+			result2 := notifier.SendBirthdayNotification(context.Background(), birthday2)
+
+			// then
+			Expect(result1).To(BeNil())
+			Expect(result2).To(Not(BeNil()))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveLen(1))
+			Expect(telegram.sentMessages).To(HaveLen(1))
+		})
+	})
+	Describe("group birthdays", func() {
+		var birthday1 core.Birthday
+		var birthday2 core.Birthday
+		var groupNotification core.Notification
+
+		BeforeEach(func() {
+			clock.now = NOW
+			birthday1 = core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1, FirstName: USER_FIRST_NAME_1, LastName: USER_LAST_NAME_1}
+			birthday2 = core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_2, Name: USER_NAME_2, FirstName: USER_FIRST_NAME_2, LastName: USER_LAST_NAME_2}
+			groupNotification = core.Notification{Kind: core.NOTIFICATION_KIND_BIRTHDAY, ChatId: CHAT_ID_1, Birthdays: []core.Birthday{birthday1, birthday2}}
+		})
+
+		It("should send a single video of everyone's profile pictures and a single message mentioning everyone", func() {
+			// given
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			telegram.videoFileIdToReturn = FILE_ID_2
+			fileDownloader.filePathToReturn = PICTURE_PATH
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendNotification(context.Background(), groupNotification)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1, USER_ID_2))
+			Expect(videoGenerator.groupVideoGenerationRequests).To(Equal([][]string{{PICTURE_PATH, PICTURE_PATH}}))
+			Expect(videoGenerator.groupVideoNames).To(HaveExactElements(USER_FULL_NAME_1 + " and " + USER_FIRST_NAME_2))
+			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_GROUP_BIRTHDAY_MESSAGE}))
+			Expect(repository.savedVideos).To(HaveExactElements(
+				core.Video{ChatId: CHAT_ID_1, UserId: USER_ID_1, Year: NOW.Year(), FileId: FILE_ID_2},
+				core.Video{ChatId: CHAT_ID_1, UserId: USER_ID_2, Year: NOW.Year(), FileId: FILE_ID_2},
+			))
+			Expect(repository.requestedWishes).To(HaveExactElements(
+				RequestedWishes{chatId: CHAT_ID_1, userId: USER_ID_1, date: NOW},
+				RequestedWishes{chatId: CHAT_ID_1, userId: USER_ID_2, date: NOW},
+			))
+		})
+
+		It("should put avatars in the video in place of missing profile pictures", func() {
+			// given
+			telegram.thereAreNoProfilePictures()
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+
+			// when
+			result := notifier.SendNotification(context.Background(), groupNotification)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(avatarGenerator.requests).To(HaveExactElements(USER_INITIALS_1, USER_INITIALS_2))
+			Expect(videoGenerator.groupVideoGenerationRequests).To(Equal([][]string{{AVATAR_PATH, AVATAR_PATH}}))
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_GROUP_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should record the steps for everyone and skip the ones completed for everyone", func() {
+			// given
+			telegram.thereAreNoProfilePictures()
+			delivery1 := core.Delivery{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: NOW, Kind: core.NOTIFICATION_KIND_BIRTHDAY}
+			delivery2 := core.Delivery{ChatId: CHAT_ID_1, UserId: USER_ID_2, Date: NOW, Kind: core.NOTIFICATION_KIND_BIRTHDAY}
+			ledger.CompleteStep(context.Background(), delivery1, core.DELIVERY_STEP_VIDEO)
+			ledger.CompleteStep(context.Background(), delivery2, core.DELIVERY_STEP_VIDEO)
+			ledger.CompleteStep(context.Background(), delivery1, core.DELIVERY_STEP_MESSAGE)
+
+			// when
+			result := notifier.SendNotification(context.Background(), groupNotification)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.profilePictureRequests).To(BeEmpty())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_GROUP_BIRTHDAY_MESSAGE}))
+			Expect(ledger.deliveryToSteps[delivery1]).To(ContainElements(core.DELIVERY_STEP_VIDEO, core.DELIVERY_STEP_MESSAGE, core.DELIVERY_STEP_WISHES))
+			Expect(ledger.deliveryToSteps[delivery2]).To(ContainElements(core.DELIVERY_STEP_VIDEO, core.DELIVERY_STEP_MESSAGE, core.DELIVERY_STEP_WISHES))
+		})
+
+		It("should send a belated message mentioning everyone for a belated group notification", func() {
+			// given
+			telegram.thereAreNoProfilePictures()
+			groupNotification.Belated = true
+			groupNotification.Date = MISSED_DATE_1
+
+			// when
+			result := notifier.SendNotification(context.Background(), groupNotification)
+
+			// then
+			Expect(result).To(BeNil())
+			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_BELATED_GROUP_BIRTHDAY_MESSAGE}))
+		})
+
+		It("should return an error when generating the group video fails", func() {
+			// given
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.shouldFail = true
+
+			// when
+			result := notifier.SendNotification(context.Background(), groupNotification)
+
+			// then
+			Expect(result).To(Not(BeNil()))
+			Expect(telegram.sentMessages).To(BeEmpty())
+		})
+	})
+
+	Describe("delivery ledger", func() {
+		var birthday core.Birthday
+		var delivery core.Delivery
+
+		BeforeEach(func() {
+			clock.now = NOW
+			birthday = core.Birthday{ChatId: CHAT_ID_1, UserId: USER_ID_1, Name: USER_NAME_1}
+			delivery = core.Delivery{ChatId: CHAT_ID_1, UserId: USER_ID_1, Date: NOW, Kind: core.NOTIFICATION_KIND_BIRTHDAY}
+			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
+			videoGenerator.videoPathToReturn = VIDEO_PATH
+		})
 
+		It("should record every completed step of a birthday notification", func() {
 			// when
 			result := notifier.SendBirthdayNotification(context.Background(), birthday)
 
 			// then
 			Expect(result).To(BeNil())
-			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
-			Expect(telegram.sentVideos).To(BeEmpty())
-			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+			Expect(ledger.deliveryToSteps[delivery]).To(HaveExactElements(
+				core.DELIVERY_STEP_VIDEO,
+				core.DELIVERY_STEP_MESSAGE,
+				core.DELIVERY_STEP_WISHES,
+			))
 		})
 
-		It("should not send a video when there is no link to profile picture file", func() {
+		It("should resume a retried notification after the video was sent", func() {
 			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			telegram.thereAreProfilePictureFileIds(FILE_ID_1)
-			telegram.fileLinkToReturn = ""
+			ledger.CompleteStep(context.Background(), delivery, core.DELIVERY_STEP_VIDEO)
 
 			// when
 			result := notifier.SendBirthdayNotification(context.Background(), birthday)
 
 			// then
 			Expect(result).To(BeNil())
-			Expect(telegram.profilePictureRequests).To(HaveExactElements(USER_ID_1))
+			Expect(telegram.profilePictureRequests).To(BeEmpty())
 			Expect(telegram.sentVideos).To(BeEmpty())
 			Expect(telegram.sentMessages).To(HaveExactElements(Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE}))
+			Expect(repository.requestedWishes).To(HaveLen(1))
 		})
 
-		It("should return an error when fetching profile picture file id fails", func() {
+		It("should not send anything for a notification that was already delivered", func() {
 			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			telegram.shouldFailOnGettingProfilePicture = true
+			ledger.CompleteStep(context.Background(), delivery, core.DELIVERY_STEP_VIDEO)
+			ledger.CompleteStep(context.Background(), delivery, core.DELIVERY_STEP_MESSAGE)
+			ledger.CompleteStep(context.Background(), delivery, core.DELIVERY_STEP_WISHES)
 
 			// when
 			result := notifier.SendBirthdayNotification(context.Background(), birthday)
 
 			// then
-			Expect(result).To(Not(BeNil()))
-			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
+			Expect(result).To(BeNil())
 			Expect(telegram.sentVideos).To(BeEmpty())
 			Expect(telegram.sentMessages).To(BeEmpty())
+			Expect(repository.requestedWishes).To(BeEmpty())
 		})
 
-		It("should return an error when fetching profile picture file link fails", func() {
+		It("should keep track of deliveries of the same user in other chats separately", func() {
 			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			telegram.shouldFailOnGettingFile = true
-			videoGenerator.videoPathToReturn = VIDEO_PATH
+			ledger.CompleteStep(context.Background(), core.Delivery{ChatId: CHAT_ID_2, UserId: USER_ID_1, Date: NOW, Kind: core.NOTIFICATION_KIND_BIRTHDAY}, core.DELIVERY_STEP_VIDEO)
 
 			// when
 			result := notifier.SendBirthdayNotification(context.Background(), birthday)
 
 			// then
-			Expect(result).To(Not(BeNil()))
-			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
-			Expect(telegram.sentVideos).To(BeEmpty())
-			Expect(telegram.sentMessages).To(BeEmpty())
+			Expect(result).To(BeNil())
+			Expect(telegram.sentVideos).To(HaveExactElements(Video{chatId: CHAT_ID_1, path: VIDEO_PATH}))
 		})
 
-		It("should return an error when downloading profile picture fails", func() {
+		It("should not record the message step when sending the message fails", func() {
 			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			fileDownloader.shouldFail = true
-			videoGenerator.videoPathToReturn = VIDEO_PATH
+			telegram.shouldFailOnSendingMessage = true
 
 			// when
 			result := notifier.SendBirthdayNotification(context.Background(), birthday)
 
 			// then
-			Expect(result).To(Not(BeNil()))
-			Expect(videoGenerator.videoGenerationRequests).To(BeEmpty())
-			Expect(telegram.sentVideos).To(BeEmpty())
-			Expect(telegram.sentMessages).To(BeEmpty())
+			Expect(result).To(BeNil())
+			Expect(ledger.deliveryToSteps[delivery]).To(HaveExactElements(core.DELIVERY_STEP_VIDEO, core.DELIVERY_STEP_WISHES))
 		})
 
-		It("should return an error when generating a video fails", func() {
+		It("should return an error without sending anything when the ledger is unavailable", func() {
 			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			videoGenerator.shouldFail = true
+			ledger.shouldFail = true
 
 			// when
 			result := notifier.SendBirthdayNotification(context.Background(), birthday)
@@ -275,101 +1605,108 @@ var _ = Describe("Birthday notifier", func() {
 			Expect(telegram.sentMessages).To(BeEmpty())
 		})
 
-		It("should return an error when sending a video fails", func() {
+		It("should not return an error when recording a step fails", func() {
 			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			telegram.shouldFailOnSendingVideoFromPath = true
-			videoGenerator.videoPathToReturn = VIDEO_PATH
+			ledger.shouldFailOnCompleteStep = true
 
 			// when
 			result := notifier.SendBirthdayNotification(context.Background(), birthday)
 
 			// then
-			Expect(result).To(Not(BeNil()))
-			Expect(telegram.sentMessages).To(BeEmpty())
+			Expect(result).To(BeNil())
+			Expect(telegram.sentVideos).To(HaveLen(1))
+			Expect(telegram.sentMessages).To(HaveLen(1))
 		})
+	})
 
-		It("should not return an error when sending a birthday message fails", func() {
-			// given
-			birthday := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
+	Describe("concurrency", func() {
+		sendConcurrently := func(notifications ...core.Notification) (wait func() []error) {
+			var waitGroup sync.WaitGroup
+			results := make([]error, len(notifications))
+			for index, notification := range notifications {
+				waitGroup.Add(1)
+				go func() {
+					defer waitGroup.Done()
+					results[index] = notifier.SendNotification(context.Background(), notification)
+				}()
 			}
+			return func() []error {
+				waitGroup.Wait()
+				return results
+			}
+		}
+		birthdayNotification := func(chatId int64, userId int64) core.Notification {
+			return core.Notification{
+				Kind:      core.NOTIFICATION_KIND_BIRTHDAY,
+				ChatId:    chatId,
+				Birthdays: []core.Birthday{{ChatId: chatId, UserId: userId, Name: USER_NAME_1}},
+			}
+		}
+
+		It("should render the video once for concurrent notifications of the same user", func() {
+			// given
 			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			telegram.shouldFailOnSendingMessage = true
+			telegram.videoFileIdToReturn = VIDEO_FILE_ID
 			videoGenerator.videoPathToReturn = VIDEO_PATH
+			videoGenerator.release = make(chan struct{})
 
 			// when
-			result := notifier.SendBirthdayNotification(context.Background(), birthday)
+			wait := sendConcurrently(birthdayNotification(CHAT_ID_1, USER_ID_1), birthdayNotification(CHAT_ID_2, USER_ID_1))
+			Eventually(videoGenerator.getActiveRenders).Should(Equal(1))
+			Eventually(videoCache.getRequestCount).Should(Equal(2))
+			time.Sleep(50 * time.Millisecond)
+			close(videoGenerator.release)
+			results := wait()
 
 			// then
-			Expect(result).To(BeNil())
-			Expect(telegram.sentVideos).To(Not(BeEmpty()))
+			Expect(results).To(HaveEach(BeNil()))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveLen(1))
+			Expect(telegram.sentVideos).To(HaveLen(2))
+			sentVideoPaths := []string{telegram.sentVideos[0].path, telegram.sentVideos[1].path}
+			Expect(sentVideoPaths).To(ConsistOf(VIDEO_PATH, VIDEO_FILE_ID))
+			Expect(telegram.sentMessages).To(HaveLen(2))
 		})
 
-		It("should reuse video's fileId when sending birthday notifications for the same user", func() {
+		It("should send at most the configured number of notifications at once", func() {
 			// given
-			birthday1 := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			birthday2 := core.Birthday{
-				ChatId: CHAT_ID_2,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
 			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			telegram.videoFileIdToReturn = FILE_ID_2
+			telegram.videoFileIdToReturn = VIDEO_FILE_ID
 			videoGenerator.videoPathToReturn = VIDEO_PATH
+			videoGenerator.release = make(chan struct{})
 
 			// when
-			notifier.SendBirthdayNotification(context.Background(), birthday1)
-			notifier.SendBirthdayNotification(context.Background(), birthday2)
+			wait := sendConcurrently(
+				birthdayNotification(CHAT_ID_1, USER_ID_1),
+				birthdayNotification(CHAT_ID_1, USER_ID_2),
+				birthdayNotification(CHAT_ID_2, USER_ID_3),
+			)
+			Eventually(videoGenerator.getActiveRenders).Should(Equal(MAX_CONCURRENT_NOTIFICATIONS))
+			Consistently(videoGenerator.getActiveRenders, 50*time.Millisecond).Should(Equal(MAX_CONCURRENT_NOTIFICATIONS))
+			close(videoGenerator.release)
+			results := wait()
 
 			// then
-			Expect(videoGenerator.videoGenerationRequests).To(HaveLen(1))
-			Expect(telegram.sentVideos).To(HaveExactElements(
-				Video{chatId: CHAT_ID_1, path: VIDEO_PATH},
-				Video{chatId: CHAT_ID_2, path: FILE_ID_2},
-			))
-			Expect(telegram.sentMessages).To(HaveExactElements(
-				Message{chatId: CHAT_ID_1, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE},
-				Message{chatId: CHAT_ID_2, text: EXPECTED_USER_1_BIRTHDAY_MESSAGE},
-			))
+			Expect(results).To(HaveEach(BeNil()))
+			Expect(videoGenerator.videoGenerationRequests).To(HaveLen(3))
+			Expect(videoGenerator.maxActiveRenders).To(Equal(MAX_CONCURRENT_NOTIFICATIONS))
 		})
 
-		It("should return an error when sending video from fileId fails", func() {
+		It("should stop waiting for a free worker when the request is cancelled", func() {
 			// given
-			birthday1 := core.Birthday{
-				ChatId: CHAT_ID_1,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
-			birthday2 := core.Birthday{
-				ChatId: CHAT_ID_2,
-				UserId: USER_ID_1,
-				Name:   USER_NAME_1,
-			}
 			telegram.thereIsProfilePicture(FILE_ID_1, FILE_LINK)
-			telegram.videoFileIdToReturn = FILE_ID_2
-			telegram.shouldFailOnSendingVideoFromFileId = true
+			videoGenerator.release = make(chan struct{})
+			wait := sendConcurrently(birthdayNotification(CHAT_ID_1, USER_ID_1), birthdayNotification(CHAT_ID_1, USER_ID_2))
+			Eventually(videoGenerator.getActiveRenders).Should(Equal(MAX_CONCURRENT_NOTIFICATIONS))
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
 
 			// when
-			result1 := notifier.SendBirthdayNotification(context.Background(), birthday1)
-			result2 := notifier.SendBirthdayNotification(context.Background(), birthday2)
+			result := notifier.SendNotification(ctx, birthdayNotification(CHAT_ID_2, USER_ID_3))
 
 			// then
-			Expect(result1).To(BeNil())
-			Expect(result2).To(Not(BeNil()))
-			Expect(videoGenerator.videoGenerationRequests).To(HaveLen(1))
-			Expect(telegram.sentMessages).To(HaveLen(1))
+			Expect(result).To(MatchError(context.Canceled))
+			close(videoGenerator.release)
+			Expect(wait()).To(HaveEach(BeNil()))
 		})
 	})
 })
@@ -377,12 +1714,42 @@ var _ = Describe("Birthday notifier", func() {
 // ===== FAKES =====
 
 type FakeRepository struct {
-	birthdays      []core.Birthday
-	requestedDates []time.Time
-	shouldFail     bool
+	mutex                         sync.Mutex
+	birthdays                     []core.Birthday
+	digestBirthdays               []core.Birthday
+	requestedDates                []time.Time
+	requestedDigestDates          []time.Time
+	events                        []core.Event
+	requestedEventDates           []time.Time
+	shouldFail                    bool
+	shouldFailOnDigest            bool
+	shouldFailOnEvents            bool
+	nameDays                      []core.NameDay
+	requestedNameDayDates         []time.Time
+	shouldFailOnNameDays          bool
+	wishes                        []core.Wish
+	requestedWishes               []RequestedWishes
+	shouldFailOnWishes            bool
+	customPictures                map[int64]core.ProfilePicture
+	shouldFailOnCustomPictures    bool
+	savedVideos                   []core.Video
+	shouldFailOnVideos            bool
+	lastScheduledDate             time.Time
+	savedScheduledDates           []time.Time
+	shouldFailOnLastScheduledDate bool
+	reportedUnavailableChats      []int64
+	shouldFailOnReportingChat     bool
+}
+
+type RequestedWishes struct {
+	chatId int64
+	userId int64
+	date   time.Time
 }
 
 func (repository *FakeRepository) GetBirthdays(_ context.Context, date time.Time) ([]core.Birthday, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
 	repository.requestedDates = append(repository.requestedDates, date)
 	if repository.shouldFail {
 		return nil, errors.New("test")
@@ -390,11 +1757,107 @@ func (repository *FakeRepository) GetBirthdays(_ context.Context, date time.Time
 	return repository.birthdays, nil
 }
 
+func (repository *FakeRepository) GetDigestBirthdays(_ context.Context, date time.Time) ([]core.Birthday, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	repository.requestedDigestDates = append(repository.requestedDigestDates, date)
+	if repository.shouldFailOnDigest {
+		return nil, errors.New("test")
+	}
+	return repository.digestBirthdays, nil
+}
+
+func (repository *FakeRepository) GetEvents(_ context.Context, date time.Time) ([]core.Event, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	repository.requestedEventDates = append(repository.requestedEventDates, date)
+	if repository.shouldFailOnEvents {
+		return nil, errors.New("test")
+	}
+	return repository.events, nil
+}
+
+func (repository *FakeRepository) GetNameDays(_ context.Context, date time.Time) ([]core.NameDay, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	repository.requestedNameDayDates = append(repository.requestedNameDayDates, date)
+	if repository.shouldFailOnNameDays {
+		return nil, errors.New("test")
+	}
+	return repository.nameDays, nil
+}
+
+func (repository *FakeRepository) GetWishes(_ context.Context, chatId int64, userId int64, date time.Time) ([]core.Wish, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	repository.requestedWishes = append(repository.requestedWishes, RequestedWishes{chatId: chatId, userId: userId, date: date})
+	if repository.shouldFailOnWishes {
+		return nil, errors.New("test")
+	}
+	return repository.wishes, nil
+}
+
+func (repository *FakeRepository) GetCustomPicture(_ context.Context, chatId int64, userId int64) (*core.ProfilePicture, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	if repository.shouldFailOnCustomPictures {
+		return nil, errors.New("test")
+	}
+	picture, present := repository.customPictures[userId]
+	if !present {
+		return nil, nil
+	}
+	return &picture, nil
+}
+
+func (repository *FakeRepository) SaveVideo(_ context.Context, video core.Video) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	if repository.shouldFailOnVideos {
+		return errors.New("test")
+	}
+	repository.savedVideos = append(repository.savedVideos, video)
+	return nil
+}
+
+func (repository *FakeRepository) GetLastScheduledDate(_ context.Context) (time.Time, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	if repository.shouldFailOnLastScheduledDate {
+		return time.Time{}, errors.New("test")
+	}
+	return repository.lastScheduledDate, nil
+}
+
+func (repository *FakeRepository) SaveScheduledDate(_ context.Context, date time.Time) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	if repository.shouldFail {
+		return errors.New("test")
+	}
+	repository.savedScheduledDates = append(repository.savedScheduledDates, date)
+	return nil
+}
+
+func (repository *FakeRepository) ReportUnavailableChat(_ context.Context, chatId int64, _ string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	if repository.shouldFailOnReportingChat {
+		return errors.New("test")
+	}
+	repository.reportedUnavailableChats = append(repository.reportedUnavailableChats, chatId)
+	return nil
+}
+
 func (repository *FakeRepository) thereAre(birthday ...core.Birthday) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
 	repository.birthdays = append(repository.birthdays, birthday...)
 }
 
 func (repository *FakeRepository) thereAreNoBirthdays() {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
 	repository.birthdays = make([]core.Birthday, 0)
 }
 
@@ -408,9 +1871,17 @@ type Video struct {
 	path   string
 }
 
+type Voice struct {
+	chatId  int64
+	fileId  string
+	caption string
+}
+
 type FakeTelegram struct {
+	mutex                              sync.Mutex
 	sentMessages                       []Message
 	sentVideos                         []Video
+	sentVoices                         []Voice
 	profilePictureRequests             []int64
 	profilePictureFileIdsToReturn      []string
 	fileLinkRequests                   []string
@@ -421,12 +1892,25 @@ type FakeTelegram struct {
 	shouldFailOnSendingVideoFromFileId bool
 	shouldFailOnGettingProfilePicture  bool
 	shouldFailOnGettingFile            bool
+	unavailableChatId                  int64
+}
+
+func (fake *FakeTelegram) checkChatAvailable(chatId int64) error {
+	if fake.unavailableChatId != 0 && chatId == fake.unavailableChatId {
+		return fmt.Errorf("%w: test error", core.ErrChatUnavailable)
+	}
+	return nil
 }
 
 func (fake *FakeTelegram) SendMessage(_ context.Context, chatId int64, text string) error {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	if fake.shouldFailOnSendingMessage {
 		return errors.New("test error")
 	}
+	if err := fake.checkChatAvailable(chatId); err != nil {
+		return err
+	}
 	fake.sentMessages = append(fake.sentMessages, Message{
 		chatId: chatId,
 		text:   text,
@@ -435,9 +1919,14 @@ func (fake *FakeTelegram) SendMessage(_ context.Context, chatId int64, text stri
 }
 
 func (fake *FakeTelegram) SendVideo(_ context.Context, chatId int64, pathToVideo string) (fileId string, err error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	if fake.shouldFailOnSendingVideoFromPath {
 		return "", errors.New("test error")
 	}
+	if err := fake.checkChatAvailable(chatId); err != nil {
+		return "", err
+	}
 	fake.sentVideos = append(fake.sentVideos, Video{
 		chatId: chatId,
 		path:   pathToVideo,
@@ -446,6 +1935,8 @@ func (fake *FakeTelegram) SendVideo(_ context.Context, chatId int64, pathToVideo
 }
 
 func (fake *FakeTelegram) SendVideoFromFileId(ctx context.Context, chatId int64, fileId string) error {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	if fake.shouldFailOnSendingVideoFromFileId {
 		return errors.New("test error")
 	}
@@ -456,15 +1947,30 @@ func (fake *FakeTelegram) SendVideoFromFileId(ctx context.Context, chatId int64,
 	return nil
 }
 
-func (fake *FakeTelegram) GetProfilePictureFileIds(_ context.Context, userId int64) ([]string, error) {
+func (fake *FakeTelegram) SendVoiceFromFileId(_ context.Context, chatId int64, fileId string, caption string) error {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.sentVoices = append(fake.sentVoices, Voice{chatId: chatId, fileId: fileId, caption: caption})
+	return nil
+}
+
+func (fake *FakeTelegram) GetProfilePictures(_ context.Context, userId int64) ([]core.ProfilePicture, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	if fake.shouldFailOnGettingProfilePicture {
 		return nil, errors.New("test error")
 	}
 	fake.profilePictureRequests = append(fake.profilePictureRequests, userId)
-	return fake.profilePictureFileIdsToReturn, nil
+	var profilePictures []core.ProfilePicture
+	for _, fileId := range fake.profilePictureFileIdsToReturn {
+		profilePictures = append(profilePictures, core.ProfilePicture{FileId: fileId, FileUniqueId: UNIQUE_ID_PREFIX + fileId})
+	}
+	return profilePictures, nil
 }
 
 func (fake *FakeTelegram) GetFileLink(_ context.Context, fileId string) (string, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	if fake.shouldFailOnGettingFile {
 		return "", errors.New("test error")
 	}
@@ -473,19 +1979,103 @@ func (fake *FakeTelegram) GetFileLink(_ context.Context, fileId string) (string,
 }
 
 func (fake *FakeTelegram) thereAreProfilePictureFileIds(fileIds ...string) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	fake.profilePictureFileIdsToReturn = fileIds
 
 }
 
 func (fake *FakeTelegram) thereIsProfilePicture(fileId string, fileLink string) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	fake.profilePictureFileIdsToReturn = append(fake.profilePictureFileIdsToReturn, fileId)
 	fake.fileLinkToReturn = fileLink
 }
 
 func (fake *FakeTelegram) thereAreNoProfilePictures() {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	fake.profilePictureFileIdsToReturn = nil
 }
 
+func getVideoKey(pictureId string, name string) string {
+	return pictureId + core.VIDEO_KEY_SEPARATOR + name
+}
+
+type FakeVideoCache struct {
+	mutex               sync.Mutex
+	userIdToCachedVideo map[int64]CachedVideo
+	requestCount        int
+	shouldFail          bool
+}
+
+type CachedVideo struct {
+	pictureUniqueId string
+	fileId          string
+}
+
+func (cache *FakeVideoCache) Get(_ context.Context, userId int64, pictureUniqueId string) (string, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.requestCount++
+	if cache.shouldFail {
+		return "", errors.New("test error")
+	}
+	cachedVideo := cache.userIdToCachedVideo[userId]
+	if cachedVideo.pictureUniqueId != pictureUniqueId {
+		return "", nil
+	}
+	return cachedVideo.fileId, nil
+}
+
+func (cache *FakeVideoCache) getRequestCount() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.requestCount
+}
+
+func (cache *FakeVideoCache) Put(_ context.Context, userId int64, pictureUniqueId string, fileId string) error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.shouldFail {
+		return errors.New("test error")
+	}
+	if cache.userIdToCachedVideo == nil {
+		cache.userIdToCachedVideo = make(map[int64]CachedVideo)
+	}
+	cache.userIdToCachedVideo[userId] = CachedVideo{pictureUniqueId: pictureUniqueId, fileId: fileId}
+	return nil
+}
+
+type FakeDeliveryLedger struct {
+	mutex                    sync.Mutex
+	deliveryToSteps          map[core.Delivery][]core.DeliveryStep
+	shouldFail               bool
+	shouldFailOnCompleteStep bool
+}
+
+func (ledger *FakeDeliveryLedger) GetCompletedSteps(_ context.Context, delivery core.Delivery) ([]core.DeliveryStep, error) {
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+	if ledger.shouldFail {
+		return nil, errors.New("test error")
+	}
+	return ledger.deliveryToSteps[delivery], nil
+}
+
+func (ledger *FakeDeliveryLedger) CompleteStep(_ context.Context, delivery core.Delivery, step core.DeliveryStep) error {
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+	if ledger.shouldFail || ledger.shouldFailOnCompleteStep {
+		return errors.New("test error")
+	}
+	if ledger.deliveryToSteps == nil {
+		ledger.deliveryToSteps = make(map[core.Delivery][]core.DeliveryStep)
+	}
+	ledger.deliveryToSteps[delivery] = append(ledger.deliveryToSteps[delivery], step)
+	return nil
+}
+
 type FakeClock struct {
 	now time.Time
 }
@@ -495,12 +2085,15 @@ func (clock FakeClock) Now() time.Time {
 }
 
 type FakeFileDownloader struct {
+	mutex            sync.Mutex
 	requests         []string
 	filePathToReturn string
 	shouldFail       bool
 }
 
 func (fake *FakeFileDownloader) Download(ctx context.Context, link string) (string, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
 	if fake.shouldFail {
 		return "", errors.New("test error")
 	}
@@ -509,48 +2102,167 @@ func (fake *FakeFileDownloader) Download(ctx context.Context, link string) (stri
 }
 
 type FakeVideoGenerator struct {
-	videoPathToReturn       string
-	videoGenerationRequests []string
-	shouldFail              bool
+	mutex                        sync.Mutex
+	videoPathToReturn            string
+	videoGenerationRequests      []string
+	eventVideoGenerationRequests []string
+	groupVideoGenerationRequests [][]string
+	videoNames                   []string
+	eventVideoNames              []string
+	groupVideoNames              []string
+	shouldFail                   bool
+	// release makes renders wait until it's closed
+	release          chan struct{}
+	activeRenders    int
+	maxActiveRenders int
+}
+
+func (fake *FakeVideoGenerator) CreateGroupVideo(pathsToProfilePictures []string, name string) (string, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if fake.shouldFail {
+		return "", errors.New("test error")
+	}
+	fake.groupVideoGenerationRequests = append(fake.groupVideoGenerationRequests, pathsToProfilePictures)
+	fake.groupVideoNames = append(fake.groupVideoNames, name)
+	return fake.videoPathToReturn, nil
 }
 
-func (fake *FakeVideoGenerator) CreateVideo(linkToProfilePicture string) (string, error) {
+func (fake *FakeVideoGenerator) CreateVideo(linkToProfilePicture string, name string) (string, error) {
+	fake.mutex.Lock()
 	if fake.shouldFail {
+		fake.mutex.Unlock()
 		return "", errors.New("test error")
 	}
 	fake.videoGenerationRequests = append(fake.videoGenerationRequests, linkToProfilePicture)
+	fake.videoNames = append(fake.videoNames, name)
+	fake.activeRenders++
+	fake.maxActiveRenders = max(fake.maxActiveRenders, fake.activeRenders)
+	release := fake.release
+	fake.mutex.Unlock()
+
+	if release != nil {
+		<-release
+	}
+
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.activeRenders--
+	return fake.videoPathToReturn, nil
+}
+
+func (fake *FakeVideoGenerator) getActiveRenders() int {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return fake.activeRenders
+}
+
+func (fake *FakeVideoGenerator) CreateEventVideo(eventKind string, name string) (string, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if fake.shouldFail {
+		return "", errors.New("test error")
+	}
+	fake.eventVideoGenerationRequests = append(fake.eventVideoGenerationRequests, eventKind)
+	fake.eventVideoNames = append(fake.eventVideoNames, name)
 	return fake.videoPathToReturn, nil
 }
 
+type FakeAvatarGenerator struct {
+	mutex              sync.Mutex
+	requests           []string
+	avatarPathToReturn string
+	shouldFail         bool
+}
+
+func (fake *FakeAvatarGenerator) CreateAvatar(_ int64, initials string) (string, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if fake.shouldFail {
+		return "", errors.New("test error")
+	}
+	fake.requests = append(fake.requests, initials)
+	return fake.avatarPathToReturn, nil
+}
+
 type FakeBirthdayScheduler struct {
 	scheduledTasks []ScheduledTask
+	chatIdToFail   int64
 }
 
 type ScheduledTask struct {
-	birthday core.Birthday
-	url      string
+	notification core.Notification
+	url          string
+}
+
+func (fake *FakeBirthdayScheduler) Schedule(ctx context.Context, notification core.Notification, serviceUrl string) error {
+	if fake.chatIdToFail != 0 && notification.ChatId == fake.chatIdToFail {
+		return errors.New("test error")
+	}
+	fake.scheduledTasks = append(fake.scheduledTasks, ScheduledTask{notification, serviceUrl})
+	return nil
+}
+
+func birthdayTask(birthday core.Birthday) ScheduledTask {
+	return ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_BIRTHDAY, ChatId: birthday.ChatId, Birthdays: []core.Birthday{birthday}}, SERVICE_URL}
 }
 
-func (fake *FakeBirthdayScheduler) Schedule(ctx context.Context, birthday core.Birthday, serviceUrl string) {
-	fake.scheduledTasks = append(fake.scheduledTasks, ScheduledTask{birthday, serviceUrl})
+func belatedBirthdayTask(birthday core.Birthday, date time.Time) ScheduledTask {
+	return ScheduledTask{core.Notification{Kind: core.NOTIFICATION_KIND_BIRTHDAY, ChatId: birthday.ChatId, Birthdays: []core.Birthday{birthday}, Belated: true, Date: date}, SERVICE_URL}
 }
 
 // ===== TEST DATA =====
 const (
-	CHAT_ID_1    int64 = 981
-	CHAT_ID_2    int64 = 881
-	USER_ID_1    int64 = 123
-	USER_ID_2    int64 = 456
-	USER_NAME_1        = "test 1"
-	USER_NAME_2        = "test 2"
-	FILE_ID_1          = "file_id_1"
-	FILE_ID_2          = "file_id_2"
-	FILE_LINK          = "some://file-link"
-	PICTURE_PATH       = "/some/path"
-	VIDEO_PATH         = "some/video.mp4"
-	SERVICE_URL        = "http://this-service/test"
-
-	EXPECTED_USER_1_BIRTHDAY_MESSAGE = "Aah test 1\nHappy birthday, senpai! 🎂✨ I hope your day is as wonderful as you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+	CHAT_ID_1                    int64 = 981
+	CHAT_ID_2                    int64 = 881
+	USER_ID_1                    int64 = 123
+	USER_ID_2                    int64 = 456
+	USER_ID_3                    int64 = 789
+	USER_NAME_1                        = "test 1"
+	USER_NAME_2                        = "test 2"
+	USER_FIRST_NAME_1                  = "Haruhi"
+	USER_LAST_NAME_1                   = "Suzumiya"
+	USER_FULL_NAME_1                   = USER_FIRST_NAME_1 + " " + USER_LAST_NAME_1
+	USER_INITIALS_1                    = "HS"
+	USER_FIRST_NAME_2                  = "Kyon"
+	USER_LAST_NAME_2                   = ""
+	USER_INITIALS_2                    = "K"
+	FILE_ID_1                          = "file_id_1"
+	FILE_ID_2                          = "file_id_2"
+	CUSTOM_PICTURE_UNIQUE_ID           = "custom_picture_unique_id"
+	FILE_LINK                          = "some://file-link"
+	PICTURE_PATH                       = "/some/path"
+	AVATAR_PATH                        = "/avatar/path"
+	VIDEO_PATH                         = "some/video.mp4"
+	SERVICE_URL                        = "http://this-service/test"
+	EVENT_NAME                         = "Rex's birthday"
+	EVENT_NAME_2                       = "Luna's birthday"
+	EVENT_KIND_PET                     = "pet"
+	EVENT_KIND_FOUNDING                = "founding"
+	NAME_DAY_NAME                      = "Anna"
+	NAME_DAY_COUNTRY                   = "pl"
+	VOICE_FILE_ID                      = "voice_file_id"
+	VIDEO_FILE_ID                      = "video_file_id"
+	UNIQUE_ID_PREFIX                   = "unique_"
+	MAX_CONCURRENT_NOTIFICATIONS       = 2
+	CATCH_UP_LOOKBACK_DAYS             = 3
+
+	EXPECTED_USER_1_BIRTHDAY_MESSAGE        = "Aah test 1\nHappy birthday, senpai! 🎂✨ I hope your day is as wonderful as you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+	EXPECTED_GROUP_BIRTHDAY_MESSAGE         = "Aah test 1 and test 2\nIt's a birthday party today! Happy birthday, senpais! 🎂✨ I hope your day is as wonderful as all of you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+	EXPECTED_BELATED_GROUP_BIRTHDAY_MESSAGE = "Aah test 1 and test 2\nI'm so sorry I'm late, senpais! (｡•́︿•̀｡) Happy belated birthday to all of you! 🎂✨ I hope your day was as wonderful as you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+	EXPECTED_USER_1_BELATED_MESSAGE         = "Aah test 1\nI'm so sorry I'm late, senpai! (｡•́︿•̀｡) Happy belated birthday! 🎂✨ I hope your day was as wonderful as you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+	EXPECTED_WISHES_HEADER                  = "Psst~ test 1, your friends left some wishes for you with me! 💌\n\n"
+	EXPECTED_WISHES_MESSAGE                 = EXPECTED_WISHES_HEADER + "💬 test 2: Stay &lt;awesome&gt;!\n💬 test 1: Happy birthday!\n"
+	EXPECTED_VOICE_WISH_CAPTION             = "🎙️ A wish from test 2~ ♡"
+	EXPECTED_NAME_DAY_MESSAGE               = "Kyaa~ it's a name day today! 💐\n\n🌸 test 1 as <b>Anna</b>\n🌸 test 2 as <b>Anna</b>\n\n<i>Wszystkiego najlepszego z okazji imienin!</i> Hehe~ I hope you get lots of flowers, senpai! (˶ᵔ ᵕ ᵔ˶)♡"
+	EXPECTED_PET_EVENT_MESSAGE              = "Waaah~ today is <b>Rex&#39;s birthday</b>! 🐾 Give them lots of treats and head pats from me, okay? (=^･ω･^=)♡"
+	EXPECTED_DIGEST_MESSAGE                 = "Ohayo, minna! 📅 Here are the birthdays coming up in March:\n\n🎂 3 Mar — test 1\n🎂 21 Mar — test 2\n\nDon't forget to wish them well, senpai! (｡•̀ᴗ-)✧"
 )
 
-var NOW = time.Now()
+var NOW = time.Date(2024, 3, 14, 7, 0, 0, 0, time.UTC)
+var MISSED_DATE_1 = time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)
+var MISSED_DATE_2 = time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)
+var FIRST_DAY_OF_MONTH = time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+var DIGEST_DATE_1 = time.Date(2000, 3, 3, 0, 0, 0, 0, time.UTC)
+var DIGEST_DATE_2 = time.Date(2000, 3, 21, 0, 0, 0, 0, time.UTC)
+var EVENT_DATE = time.Date(2000, 3, 14, 0, 0, 0, 0, time.UTC)
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+)
+
+func (notifier *BirthdayNotifier) scheduleEventNotifications(ctx context.Context, date time.Time, serviceUrl string, summary *ScheduleSummary) error {
+	events, err := notifier.repository.GetEvents(ctx, date)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, event := range events {
+		errs = append(errs, notifier.schedule(ctx, Notification{
+			Kind:   NOTIFICATION_KIND_EVENT,
+			ChatId: event.ChatId,
+			Events: []Event{event},
+		}, serviceUrl, summary))
+	}
+	return errors.Join(errs...)
+}
+
+// sendEventNotification sends a video made from the template image of the event's kind,
+// which is the same for every event of that kind and name and can be reused.
+func (notifier *BirthdayNotifier) sendEventNotification(ctx context.Context, event Event) error {
+	if fileId, isCached := notifier.getCachedEventVideo(event); isCached {
+		if err := notifier.telegram.SendVideoFromFileId(ctx, event.ChatId, fileId); err != nil {
+			return err
+		}
+	} else {
+		pathToVideo, err := notifier.videoGenerator.CreateEventVideo(event.Kind, event.Name)
+		if err != nil {
+			return err
+		}
+		fileId, err := notifier.telegram.SendVideo(ctx, event.ChatId, pathToVideo)
+		if err != nil {
+			return err
+		}
+		notifier.cacheEventVideo(event, fileId)
+	}
+	err := notifier.telegram.SendMessage(ctx, event.ChatId, notifier.createEventMessage(event))
+	if err != nil {
+		common.ErrorLogger.Printf("Could not send event message: %v\n", err)
+	}
+	return nil
+}
+
+func (notifier *BirthdayNotifier) getCachedEventVideo(event Event) (string, bool) {
+	notifier.eventVideosMutex.Lock()
+	defer notifier.eventVideosMutex.Unlock()
+	fileId, isCached := notifier.eventToCachedVideoFileId[getVideoKey(event.Kind, event.Name)]
+	return fileId, isCached
+}
+
+func (notifier *BirthdayNotifier) cacheEventVideo(event Event, fileId string) {
+	notifier.eventVideosMutex.Lock()
+	defer notifier.eventVideosMutex.Unlock()
+	notifier.eventToCachedVideoFileId[getVideoKey(event.Kind, event.Name)] = fileId
+}
+
+func (notifier *BirthdayNotifier) createEventMessage(event Event) string {
+	template, isKnownKind := EVENT_MESSAGES[event.Kind]
+	if !isKnownKind {
+		template = EVENT_MESSAGE_OTHER
+	}
+	message := fmt.Sprintf(template, html.EscapeString(event.Name))
+	if event.Year != 0 {
+		if years := notifier.clock.Now().Year() - event.Year; years > 0 {
+			message += fmt.Sprintf(EVENT_YEARS_MESSAGE, formatYears(years))
+		}
+	}
+	return message
+}
+
+func formatYears(years int) string {
+	if years == 1 {
+		return "1 year"
+	}
+	return fmt.Sprintf("%v years", years)
+}
+
+const (
+	EVENT_MESSAGE_ANNIVERSARY = "Kyaa~ today is <b>%s</b>! 🥂 Happy anniversary, everyone! (ﾉ´ヮ`)ﾉ*: ･ﾟ"
+	EVENT_MESSAGE_FOUNDING    = "Senpai, senpai! Today is <b>%s</b>! 🎊 This chat wouldn't be the same without all of you~ (˶ᵔ ᵕ ᵔ˶)♡"
+	EVENT_MESSAGE_PET         = "Waaah~ today is <b>%s</b>! 🐾 Give them lots of treats and head pats from me, okay? (=^･ω･^=)♡"
+	EVENT_MESSAGE_OTHER       = "Ehehe~ today is <b>%s</b>! 🎉 Let's celebrate together, senpai! (≧◡≦)"
+	EVENT_YEARS_MESSAGE       = "\nIt's been <b>%v</b> already! Time flies when I'm with you~ (⁄ ⁄•⁄ω⁄•⁄ ⁄)"
+)
+
+var EVENT_MESSAGES = map[string]string{
+	"anniversary": EVENT_MESSAGE_ANNIVERSARY,
+	"founding":    EVENT_MESSAGE_FOUNDING,
+	"pet":         EVENT_MESSAGE_PET,
+	"other":       EVENT_MESSAGE_OTHER,
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
+)
+
+// sendGroupBirthdayNotification celebrates members of a chat who share a birthday together: with a single video
+// of all their profile pictures and a single message mentioning everyone, followed by the wishes for each of them.
+// The shared steps are recorded for every member and skipped only when they were completed for all of them.
+func (notifier *BirthdayNotifier) sendGroupBirthdayNotification(ctx context.Context, birthdays []Birthday, date time.Time, messageTemplate string) error {
+	chatId := birthdays[0].ChatId
+	deliveries := make([]Delivery, len(birthdays))
+	completedSteps := make([][]DeliveryStep, len(birthdays))
+	for index, birthday := range birthdays {
+		deliveries[index] = Delivery{
+			ChatId: chatId,
+			UserId: birthday.UserId,
+			Date:   date,
+			Kind:   NOTIFICATION_KIND_BIRTHDAY,
+		}
+		steps, err := notifier.ledger.GetCompletedSteps(ctx, deliveries[index])
+		if err != nil {
+			return err
+		}
+		completedSteps[index] = steps
+	}
+	isCompletedForEveryone := func(step DeliveryStep) bool {
+		for _, steps := range completedSteps {
+			if !slices.Contains(steps, step) {
+				return false
+			}
+		}
+		return true
+	}
+	completeStepForEveryone := func(step DeliveryStep) {
+		for _, delivery := range deliveries {
+			notifier.completeStep(ctx, delivery, step)
+		}
+	}
+	if !isCompletedForEveryone(DELIVERY_STEP_VIDEO) {
+		fileId, err := notifier.sendGroupVideo(ctx, chatId, birthdays)
+		if err != nil {
+			return err
+		}
+		if len(fileId) > 0 {
+			for _, birthday := range birthdays {
+				notifier.archiveVideo(ctx, birthday, date, fileId)
+			}
+		}
+		completeStepForEveryone(DELIVERY_STEP_VIDEO)
+	}
+	if !isCompletedForEveryone(DELIVERY_STEP_MESSAGE) {
+		names := make([]string, len(birthdays))
+		for index, birthday := range birthdays {
+			names[index] = birthday.Name
+		}
+		err := notifier.telegram.SendMessage(ctx, chatId, fmt.Sprintf(messageTemplate, joinNames(names)))
+		if errors.Is(err, ErrChatUnavailable) {
+			return err
+		}
+		if err != nil {
+			common.ErrorLogger.Printf("Could not send group birthday message: %v\n", err)
+		} else {
+			completeStepForEveryone(DELIVERY_STEP_MESSAGE)
+		}
+	}
+	for index, birthday := range birthdays {
+		if !slices.Contains(completedSteps[index], DELIVERY_STEP_WISHES) {
+			notifier.sendWishes(ctx, birthday, date)
+			notifier.completeStep(ctx, deliveries[index], DELIVERY_STEP_WISHES)
+		}
+	}
+	return nil
+}
+
+// sendGroupVideo returns the fileId of the sent video. Members without a profile picture appear in it as their avatars.
+// The video isn't cached, as it's unlikely that the same members share a birthday with the same pictures again.
+func (notifier *BirthdayNotifier) sendGroupVideo(ctx context.Context, chatId int64, birthdays []Birthday) (string, error) {
+	pathsToImages := make([]string, 0, len(birthdays))
+	names := make([]string, 0, len(birthdays))
+	for _, birthday := range birthdays {
+		profilePicture, err := notifier.getProfilePicture(ctx, birthday)
+		if err != nil {
+			return "", err
+		}
+		pathToImage, err := notifier.getPathToImage(ctx, birthday, profilePicture)
+		if err != nil {
+			return "", err
+		}
+		pathsToImages = append(pathsToImages, pathToImage)
+		names = append(names, getPlainName(birthday))
+	}
+	pathToVideo, err := notifier.videoGenerator.CreateGroupVideo(pathsToImages, joinNames(names))
+	if err != nil {
+		return "", err
+	}
+	return notifier.telegram.SendVideo(ctx, chatId, pathToVideo)
+}
+
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return fmt.Sprintf("%v and %v", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+}
+
+const (
+	GROUP_BIRTHDAY_MESSAGE = "Aah %s\nIt's a birthday party today! Happy birthday, senpais! 🎂✨ I hope your day is as wonderful as all of you are!\n(⁄ ⁄>⁄ ▽ ⁄&lt;⁄ ⁄)♡"
+)
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (notifier *BirthdayNotifier) scheduleNameDayNotifications(ctx context.Context, date time.Time, serviceUrl string, summary *ScheduleSummary) error {
+	nameDays, err := notifier.repository.GetNameDays(ctx, date)
+	if err != nil {
+		return err
+	}
+	chatIds := make([]int64, 0)
+	chatIdToNameDays := make(map[int64][]NameDay)
+	for _, nameDay := range nameDays {
+		if _, exists := chatIdToNameDays[nameDay.ChatId]; !exists {
+			chatIds = append(chatIds, nameDay.ChatId)
+		}
+		chatIdToNameDays[nameDay.ChatId] = append(chatIdToNameDays[nameDay.ChatId], nameDay)
+	}
+	var errs []error
+	for _, chatId := range chatIds {
+		errs = append(errs, notifier.schedule(ctx, Notification{
+			Kind:     NOTIFICATION_KIND_NAME_DAY,
+			ChatId:   chatId,
+			NameDays: chatIdToNameDays[chatId],
+		}, serviceUrl, summary))
+	}
+	return errors.Join(errs...)
+}
+
+func (notifier *BirthdayNotifier) sendNameDayNotification(ctx context.Context, notification Notification) error {
+	if len(notification.NameDays) == 0 {
+		return nil
+	}
+	var lines strings.Builder
+	for _, nameDay := range notification.NameDays {
+		lines.WriteString(fmt.Sprintf(NAME_DAY_LINE, nameDay.Name, nameDay.NameDayName))
+	}
+	wish := NAME_DAY_WISHES[notification.NameDays[0].Country]
+	return notifier.telegram.SendMessage(ctx, notification.ChatId, fmt.Sprintf(NAME_DAY_MESSAGE, lines.String(), wish))
+}
+
+const (
+	NAME_DAY_MESSAGE = "Kyaa~ it's a name day today! 💐\n\n%s\n%s Hehe~ I hope you get lots of flowers, senpai! (˶ᵔ ᵕ ᵔ˶)♡"
+	NAME_DAY_LINE    = "🌸 %s as <b>%s</b>\n"
+)
+
+var NAME_DAY_WISHES = map[string]string{
+	"pl": "<i>Wszystkiego najlepszego z okazji imienin!</i>",
+	"cs": "<i>Všechno nejlepší k svátku!</i>",
+	"hu": "<i>Boldog névnapot!</i>",
+	"el": "<i>Χρόνια πολλά!</i>",
+}
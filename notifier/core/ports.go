@@ -2,37 +2,161 @@ package core
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/4Kaze/birthdaybot/common"
 )
 
+// ErrChatUnavailable is returned by Telegram when the bot can no longer post to a chat, e.g. after being kicked.
+var ErrChatUnavailable = errors.New("chat unavailable")
+
 type Repository interface {
 	GetBirthdays(ctx context.Context, date time.Time) ([]Birthday, error)
+	GetDigestBirthdays(ctx context.Context, date time.Time) ([]Birthday, error)
+	GetEvents(ctx context.Context, date time.Time) ([]Event, error)
+	GetNameDays(ctx context.Context, date time.Time) ([]NameDay, error)
+	GetWishes(ctx context.Context, chatId int64, userId int64, date time.Time) ([]Wish, error)
+	// GetCustomPicture returns the picture the user chose for their birthday video in the chat or nil when they didn't choose any.
+	GetCustomPicture(ctx context.Context, chatId int64, userId int64) (*ProfilePicture, error)
+	SaveVideo(ctx context.Context, video Video) error
+	// GetLastScheduledDate returns a zero time when notifications were never scheduled.
+	GetLastScheduledDate(ctx context.Context) (time.Time, error)
+	SaveScheduledDate(ctx context.Context, date time.Time) error
+	// ReportUnavailableChat lets the manager remove a chat the bot can no longer post to, so it's not notified again.
+	ReportUnavailableChat(ctx context.Context, chatId int64, reason string) error
 }
 
+// Birthday has the name to display, which may be a link or a @username, and the user's plain first and last name.
 type Birthday struct {
+	ChatId    int64
+	UserId    int64
+	Name      string
+	FirstName string
+	LastName  string
+	Date      time.Time
+}
+
+// Event is a yearly occasion of a chat that isn't tied to any of its members, so it has no profile picture.
+type Event struct {
 	ChatId int64
-	UserId int64
 	Name   string
+	Kind   string
+	Date   time.Time
+	Year   int
+}
+
+// NameDay is a member celebrating the name they chose from the calendar of the chat's country.
+type NameDay struct {
+	ChatId      int64
+	UserId      int64
+	Name        string
+	NameDayName string
+	Country     string
+}
+
+// Wish is a text or a voice message a member sent to be delivered on someone's birthday.
+type Wish struct {
+	AuthorName  string
+	Text        string
+	VoiceFileId string
+}
+
+// Video is a birthday video sent to a chat, archived by its Telegram fileId.
+type Video struct {
+	ChatId int64
+	UserId int64
+	Year   int
+	FileId string
+}
+
+type NotificationKind string
+
+const (
+	NOTIFICATION_KIND_BIRTHDAY NotificationKind = common.NOTIFICATION_KIND_BIRTHDAY
+	NOTIFICATION_KIND_DIGEST   NotificationKind = common.NOTIFICATION_KIND_DIGEST
+	NOTIFICATION_KIND_EVENT    NotificationKind = common.NOTIFICATION_KIND_EVENT
+	NOTIFICATION_KIND_NAME_DAY NotificationKind = common.NOTIFICATION_KIND_NAME_DAY
+)
+
+type Notification struct {
+	Kind      NotificationKind
+	ChatId    int64
+	Birthdays []Birthday
+	Events    []Event
+	NameDays  []NameDay
+	// Belated notifications are sent for a past Date that was missed, e.g. when the scheduler was down.
+	Belated bool
+	Date    time.Time
 }
 
 type Telegram interface {
 	SendMessage(ctx context.Context, chatId int64, text string) error
 	SendVideo(ctx context.Context, chatId int64, pathToVideo string) (fileId string, err error)
 	SendVideoFromFileId(ctx context.Context, chatId int64, fileId string) error
-	GetProfilePictureFileIds(ctx context.Context, userId int64) ([]string, error)
+	SendVoiceFromFileId(ctx context.Context, chatId int64, fileId string, caption string) error
+	GetProfilePictures(ctx context.Context, userId int64) ([]ProfilePicture, error)
 	GetFileLink(ctx context.Context, fileId string) (string, error)
 }
 
+// ProfilePicture identifies a user's profile picture. FileUniqueId stays the same for the same picture, while FileId can change over time.
+type ProfilePicture struct {
+	FileId       string
+	FileUniqueId string
+}
+
+// VideoCache remembers uploaded birthday videos across restarts, so a video is generated again only when the user changes
+// their profile picture or name. The unique id of the picture is combined with the name for that.
+type VideoCache interface {
+	// Get returns an empty string when no video was uploaded for the user's profile picture.
+	Get(ctx context.Context, userId int64, pictureUniqueId string) (fileId string, err error)
+	Put(ctx context.Context, userId int64, pictureUniqueId string, fileId string) error
+}
+
+type DeliveryStep string
+
+const (
+	DELIVERY_STEP_VIDEO   DeliveryStep = common.DELIVERY_STEP_VIDEO
+	DELIVERY_STEP_MESSAGE DeliveryStep = common.DELIVERY_STEP_MESSAGE
+	DELIVERY_STEP_WISHES  DeliveryStep = common.DELIVERY_STEP_WISHES
+)
+
+// Delivery identifies a notification sent to a chat for a user on a given day.
+type Delivery struct {
+	ChatId int64
+	UserId int64
+	Date   time.Time
+	Kind   NotificationKind
+}
+
+// DeliveryLedger records the steps of every delivery, so a retried notification can resume where it stopped.
+type DeliveryLedger interface {
+	GetCompletedSteps(ctx context.Context, delivery Delivery) ([]DeliveryStep, error)
+	CompleteStep(ctx context.Context, delivery Delivery, step DeliveryStep) error
+}
+
 type Clock interface {
 	Now() time.Time
 }
 
+// VideoGenerator takes plain names without any markup, as they can be drawn on the video.
 type VideoGenerator interface {
-	CreateVideo(pathToProfilePicture string) (string, error)
+	CreateVideo(pathToProfilePicture string, name string) (string, error)
+	// CreateGroupVideo creates a single video with all the profile pictures and their names joined together.
+	CreateGroupVideo(pathsToProfilePictures []string, name string) (string, error)
+	CreateEventVideo(eventKind string, name string) (string, error)
+}
+
+// AvatarGenerator creates a stand-in for the profile picture of users who have none or keep it private.
+type AvatarGenerator interface {
+	// CreateAvatar returns the path to an image with the initials on a background picked by the user id,
+	// so the same user and initials always give the same image.
+	CreateAvatar(userId int64, initials string) (string, error)
 }
 
 type BirthdayNotificationScheduler interface {
-	Schedule(ctx context.Context, birthday Birthday, serviceUrl string)
+	// Schedule returns no error when the notification was already scheduled.
+	Schedule(ctx context.Context, notification Notification, serviceUrl string) error
 }
 
 type FileDownloader interface {
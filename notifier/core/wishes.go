@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+	"unicode/utf16"
+
+	"github.com/4Kaze/birthdaybot/common"
+)
+
+// sendWishes posts wishes other members sent ahead of the birthday: text wishes compiled into as few messages
+// as Telegram's length limit allows, followed by every voice wish. A failure is only logged, so that the birthday video is never sent twice.
+func (notifier *BirthdayNotifier) sendWishes(ctx context.Context, birthday Birthday, date time.Time) {
+	wishes, err := notifier.repository.GetWishes(ctx, birthday.ChatId, birthday.UserId, date)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not get wishes for birthday: %v due to: %v\n", birthday, err)
+		return
+	}
+	var lines []string
+	for _, wish := range wishes {
+		if len(wish.Text) > 0 {
+			lines = append(lines, fmt.Sprintf(WISH_LINE, wish.AuthorName, html.EscapeString(wish.Text)))
+		}
+	}
+	if len(lines) > 0 {
+		for _, message := range splitWishesMessage(fmt.Sprintf(WISHES_MESSAGE_HEADER, birthday.Name), lines) {
+			if err := notifier.telegram.SendMessage(ctx, birthday.ChatId, message); err != nil {
+				common.ErrorLogger.Printf("Could not send wishes message: %v\n", err)
+			}
+		}
+	}
+	for _, wish := range wishes {
+		if len(wish.VoiceFileId) > 0 {
+			err := notifier.telegram.SendVoiceFromFileId(ctx, birthday.ChatId, wish.VoiceFileId, fmt.Sprintf(VOICE_WISH_CAPTION, wish.AuthorName))
+			if err != nil {
+				common.ErrorLogger.Printf("Could not send voice wish: %v\n", err)
+			}
+		}
+	}
+}
+
+// splitWishesMessage puts the header and as many lines as fit in the first message and the remaining lines in the following ones.
+// The length is counted on the html, which is never shorter than the text Telegram counts, so no message goes over the limit.
+// A line that doesn't fit in a message on its own is still sent alone, as wishes are short enough for the text to fit.
+func splitWishesMessage(header string, lines []string) []string {
+	var messages []string
+	message := header
+	hasLines := false
+	for _, line := range lines {
+		if hasLines && getTelegramLength(message+line) > TELEGRAM_MAX_MESSAGE_LENGTH {
+			messages = append(messages, message)
+			message = ""
+		}
+		message += line
+		hasLines = true
+	}
+	return append(messages, message)
+}
+
+// getTelegramLength counts the text the way Telegram does, in UTF-16 code units.
+func getTelegramLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+const (
+	WISHES_MESSAGE_HEADER       = "Psst~ %s, your friends left some wishes for you with me! 💌\n\n"
+	WISH_LINE                   = "💬 %s: %s\n"
+	VOICE_WISH_CAPTION          = "🎙️ A wish from %s~ ♡"
+	TELEGRAM_MAX_MESSAGE_LENGTH = 4096
+)
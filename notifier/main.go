@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"strconv"
 	"time"
+	_ "time/tzdata"
 
 	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
 	"github.com/4Kaze/birthdaybot/common"
@@ -19,81 +21,242 @@ import (
 
 var birthdayNotifier *core.BirthdayNotifier
 
+// apiSecret authenticates requests to the endpoints of a notifier that runs on its own, without Cloud Scheduler and Cloud Tasks.
+var apiSecret string
+
+// getNotificationEndpointUrl returns the url notification tasks are sent to. Schedulers other than Cloud Tasks don't need one.
+var getNotificationEndpointUrl = getCloudRunNotificationEndpointUrl
+
+const (
+	DEFAULT_MAX_CONCURRENT_NOTIFICATIONS = 4
+	DEFAULT_CATCH_UP_LOOKBACK_DAYS       = 3
+	DEFAULT_TASK_DELAY_S                 = 10
+	DEFAULT_TASK_DEADLINE_S              = 3 * 60
+	DEFAULT_TASK_MAX_ATTEMPTS            = 5
+	DEFAULT_TASK_MIN_BACKOFF_S           = 30
+	DEFAULT_TASK_MAX_BACKOFF_S           = 60 * 60
+	DEFAULT_JOB_POLL_INTERVAL_S          = 5
+	DEFAULT_SCHEDULE_TIME                = "09:00"
+	DEFAULT_SCHEDULE_TIME_ZONE           = "CET"
+)
+
+const (
+	SCHEDULER_CLOUD_TASKS = "cloudtasks"
+	SCHEDULER_IN_PROCESS  = "inprocess"
+	SCHEDULER_JOB_QUEUE   = "jobqueue"
+)
+
 func main() {
 	token := os.Getenv("BOT_TOKEN")
 	managerUrl := os.Getenv("MANAGER_URL")
 	port := os.Getenv("PORT")
-	cloudTasksQueueId := os.Getenv("QUEUE_ID")
-	cloudTasksDeadlineInSecondsStr := os.Getenv("TASK_DEADLINE_S")
-	cloudTasksDeadlineInSeconds, err := strconv.Atoi(cloudTasksDeadlineInSecondsStr)
-	if err != nil {
-		log.Fatalf("Incorrect cloud task deadline format - must be a number, is: %v\n", cloudTasksDeadlineInSecondsStr)
-	}
-	cloudTasksDelayInSecondsStr := os.Getenv("TASK_DELAY_S")
-	cloudTasksDelayInSeconds, err := strconv.Atoi(cloudTasksDelayInSecondsStr)
-	if err != nil {
-		log.Fatalf("Incorrect cloud task delay format - must be a number, is: %v\n", cloudTasksDelayInSecondsStr)
+	maxConcurrentNotifications := getIntEnv("MAX_CONCURRENT_NOTIFICATIONS", DEFAULT_MAX_CONCURRENT_NOTIFICATIONS)
+	catchUpLookbackDays := getIntEnv("CATCH_UP_LOOKBACK_DAYS", DEFAULT_CATCH_UP_LOOKBACK_DAYS)
+	apiSecret = os.Getenv("API_SECRET")
+	clock := common.SystemClock{}
+	ctx := context.Background()
+	switch schedulerKind := getEnv("SCHEDULER", SCHEDULER_CLOUD_TASKS); schedulerKind {
+	case SCHEDULER_CLOUD_TASKS:
+		scheduler := createCloudTasksScheduler(ctx, clock)
+		birthdayNotifier = createNotifier(token, managerUrl, scheduler, clock, maxConcurrentNotifications, catchUpLookbackDays)
+		http.HandleFunc("/schedule", HandleScheduleBirthdayNotifications)
+		http.HandleFunc("/notify", HandleSendNotification)
+	case SCHEDULER_IN_PROCESS:
+		scheduler := createInProcessScheduler(clock, maxConcurrentNotifications)
+		birthdayNotifier = createNotifier(token, managerUrl, scheduler, clock, maxConcurrentNotifications, catchUpLookbackDays)
+		getNotificationEndpointUrl = func() (string, error) { return "", nil }
+		go scheduler.Run(ctx, birthdayNotifier.SendNotification)
+		go createDailyTrigger(clock).Run(ctx, scheduleBirthdayNotifications, birthdayNotifier.IsScheduledToday)
+		// Notifications are sent by the scheduler itself, so only scheduling can be triggered, e.g. to run it again after a failure.
+		http.HandleFunc("/schedule", authenticated(HandleScheduleBirthdayNotifications))
+	case SCHEDULER_JOB_QUEUE:
+		scheduler := createJobQueueScheduler(managerUrl, clock, maxConcurrentNotifications)
+		birthdayNotifier = createNotifier(token, managerUrl, scheduler, clock, maxConcurrentNotifications, catchUpLookbackDays)
+		getNotificationEndpointUrl = func() (string, error) { return "", nil }
+		go scheduler.Run(ctx, birthdayNotifier.SendNotification)
+		go createDailyTrigger(clock).Run(ctx, scheduleBirthdayNotifications, birthdayNotifier.IsScheduledToday)
+		// Notifications are sent by the scheduler itself, so only scheduling can be triggered, e.g. to run it again after a failure.
+		http.HandleFunc("/schedule", authenticated(HandleScheduleBirthdayNotifications))
+	default:
+		log.Fatalf("Unknown scheduler: %v, must be one of: %v, %v, %v\n", schedulerKind, SCHEDULER_CLOUD_TASKS, SCHEDULER_IN_PROCESS, SCHEDULER_JOB_QUEUE)
 	}
-	birthdayNotifier = createNotifier(token, managerUrl, cloudTasksQueueId, cloudTasksDeadlineInSeconds, cloudTasksDelayInSeconds)
-	http.HandleFunc("/schedule", HandleScheduleBirthdayNotifications)
-	http.HandleFunc("/notify", HandleSendBirthdayNotification)
-	err = http.ListenAndServe(fmt.Sprintf(":%v", port), nil)
+	err := http.ListenAndServe(fmt.Sprintf(":%v", port), nil)
 	if err != nil {
 		log.Fatalf("Failed to start an http server due to: %v\n", err)
 	}
 }
 
-func createNotifier(token string, managerUrl string, cloudTasksQueueId string, cloudTasksDeadline int, cloudTasksDelay int) *core.BirthdayNotifier {
+func createNotifier(token string, managerUrl string, scheduler core.BirthdayNotificationScheduler, clock core.Clock, maxConcurrentNotifications int, catchUpLookbackDays int) *core.BirthdayNotifier {
 	telegramBot, err := telegram.New(token)
 	if err != nil {
 		log.Fatalf("Failed to instantiate telegram bot due to: %v\n", err)
 	}
 	botWrapper := adapters.NewTelegramWrapper(telegramBot)
-	repository := adapters.NewHttpRepositoryAdapter(managerUrl)
-	ctx := context.Background()
+	repository := adapters.NewHttpRepositoryAdapter(managerUrl, os.Getenv("API_SECRET"))
+	fileDownloader := adapters.NewHttpFileDownloader()
+	videoGenerator := adapters.NewVideoGenerator("/resources", getEnv("VIDEO_TEMPLATE", adapters.DEFAULT_VIDEO_TEMPLATE))
+	avatarGenerator := adapters.NewAvatarGenerator()
+	videoCache := adapters.NewHttpVideoCache(managerUrl, os.Getenv("API_SECRET"))
+	ledger := adapters.NewHttpDeliveryLedger(managerUrl, os.Getenv("API_SECRET"))
+	return core.NewBirthdayNotifier(repository, botWrapper, scheduler, fileDownloader, videoGenerator, avatarGenerator, videoCache, ledger, clock, maxConcurrentNotifications, catchUpLookbackDays)
+}
+
+func createCloudTasksScheduler(ctx context.Context, clock core.Clock) *adapters.CloudTasksScheduler {
+	cloudTasksQueueId := os.Getenv("QUEUE_ID")
+	cloudTasksDeadlineInSecondsStr := os.Getenv("TASK_DEADLINE_S")
+	cloudTasksDeadlineInSeconds, err := strconv.Atoi(cloudTasksDeadlineInSecondsStr)
+	if err != nil {
+		log.Fatalf("Incorrect cloud task deadline format - must be a number, is: %v\n", cloudTasksDeadlineInSecondsStr)
+	}
+	cloudTasksDelayInSecondsStr := os.Getenv("TASK_DELAY_S")
+	cloudTasksDelayInSeconds, err := strconv.Atoi(cloudTasksDelayInSecondsStr)
+	if err != nil {
+		log.Fatalf("Incorrect cloud task delay format - must be a number, is: %v\n", cloudTasksDelayInSecondsStr)
+	}
 	cloudTasksClient, err := cloudtasks.NewClient(ctx)
 	if err != nil {
 		log.Fatalf("Failed to instantiate PubSub client due to: %v\n", err)
 	}
-	clock := common.SystemClock{}
-	scheduler := adapters.NewCloudTasksScheduler(
+	return adapters.NewCloudTasksScheduler(
 		cloudTasksClient,
 		clock,
 		cloudTasksQueueId,
-		time.Duration(cloudTasksDeadline)*time.Second,
-		time.Duration(cloudTasksDelay)*time.Second,
+		time.Duration(cloudTasksDeadlineInSeconds)*time.Second,
+		time.Duration(cloudTasksDelayInSeconds)*time.Second,
 	)
-	fileDownloader := adapters.NewHttpFileDownloader()
-	videoGenerator := adapters.NewVideoGenerator("/resources")
-	birthdayNotifier = core.NewBirthdayNotifier(repository, botWrapper, scheduler, fileDownloader, videoGenerator, clock)
-	return birthdayNotifier
 }
 
-func HandleScheduleBirthdayNotifications(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Received a request to schedule birthdays")
+func createInProcessScheduler(clock core.Clock, concurrency int) *adapters.InProcessScheduler {
+	return adapters.NewInProcessScheduler(
+		clock,
+		time.Duration(getIntEnv("TASK_DELAY_S", DEFAULT_TASK_DELAY_S))*time.Second,
+		time.Duration(getIntEnv("TASK_DEADLINE_S", DEFAULT_TASK_DEADLINE_S))*time.Second,
+		getIntEnv("TASK_MAX_ATTEMPTS", DEFAULT_TASK_MAX_ATTEMPTS),
+		time.Duration(getIntEnv("TASK_MIN_BACKOFF_S", DEFAULT_TASK_MIN_BACKOFF_S))*time.Second,
+		time.Duration(getIntEnv("TASK_MAX_BACKOFF_S", DEFAULT_TASK_MAX_BACKOFF_S))*time.Second,
+		concurrency,
+	)
+}
+
+func createJobQueueScheduler(managerUrl string, clock core.Clock, concurrency int) *adapters.JobQueueScheduler {
+	return adapters.NewJobQueueScheduler(
+		managerUrl,
+		os.Getenv("API_SECRET"),
+		clock,
+		time.Duration(getIntEnv("TASK_DELAY_S", DEFAULT_TASK_DELAY_S))*time.Second,
+		time.Duration(getIntEnv("TASK_DEADLINE_S", DEFAULT_TASK_DEADLINE_S))*time.Second,
+		getIntEnv("TASK_MAX_ATTEMPTS", DEFAULT_TASK_MAX_ATTEMPTS),
+		time.Duration(getIntEnv("TASK_MIN_BACKOFF_S", DEFAULT_TASK_MIN_BACKOFF_S))*time.Second,
+		time.Duration(getIntEnv("TASK_MAX_BACKOFF_S", DEFAULT_TASK_MAX_BACKOFF_S))*time.Second,
+		time.Duration(getIntEnv("JOB_POLL_INTERVAL_S", DEFAULT_JOB_POLL_INTERVAL_S))*time.Second,
+		concurrency,
+	)
+}
+
+func createDailyTrigger(clock core.Clock) *adapters.DailyTrigger {
+	scheduleTimeStr := getEnv("SCHEDULE_TIME", DEFAULT_SCHEDULE_TIME)
+	scheduleTime, err := time.Parse("15:04", scheduleTimeStr)
+	if err != nil {
+		log.Fatalf("Incorrect schedule time format - must be HH:MM, is: %v\n", scheduleTimeStr)
+	}
+	timeZone := getEnv("SCHEDULE_TIME_ZONE", DEFAULT_SCHEDULE_TIME_ZONE)
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		log.Fatalf("Unknown schedule time zone: %v\n", timeZone)
+	}
+	return adapters.NewDailyTrigger(clock, scheduleTime.Hour(), scheduleTime.Minute(), location)
+}
+
+func getEnv(name string, defaultValue string) string {
+	if value, present := os.LookupEnv(name); present {
+		return value
+	}
+	return defaultValue
+}
+
+func getIntEnv(name string, defaultValue int) int {
+	value, present := os.LookupEnv(name)
+	if !present {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("'%v' environment variable is not an int\n", name)
+	}
+	return intValue
+}
+
+func getCloudRunNotificationEndpointUrl() (string, error) {
 	serviceLocation, serviceName := common.GetServiceLocationAndName()
 	serviceUrl, err := common.GetServiceUrl(serviceLocation, serviceName)
 	if err != nil {
-		log.Fatalf("Failed to get service url due to: %v\n", err)
+		return "", err
 	}
 	fmt.Printf("Got service url: %s\n", serviceUrl)
-	notificationEndpointUrl := fmt.Sprintf("%s/notify", serviceUrl)
-	if err := birthdayNotifier.ScheduleBirthdayNotifications(r.Context(), notificationEndpointUrl); err != nil {
-		w.WriteHeader(500)
+	return fmt.Sprintf("%s/notify", serviceUrl), nil
+}
+
+func HandleScheduleBirthdayNotifications(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received a request to schedule birthdays")
+	notificationEndpointUrl, err := getNotificationEndpointUrl()
+	if err != nil {
+		log.Fatalf("Failed to get service url due to: %v\n", err)
+	}
+	summary, err := birthdayNotifier.ScheduleBirthdayNotifications(r.Context(), notificationEndpointUrl)
+	summaryJson := common.ScheduleSummaryJson{Scheduled: summary.Scheduled, Failed: summary.Failed}
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
 		common.ErrorLogger.Printf("Failed to schedule birthdays: %v\n", err)
+		summaryJson.Error = err.Error()
+		w.WriteHeader(500)
+	} else {
+		log.Printf("Scheduled %v notifications\n", summary.Scheduled)
+	}
+	if err := json.NewEncoder(w).Encode(summaryJson); err != nil {
+		common.ErrorLogger.Printf("Could not encode schedule summary: %v\n", err)
+	}
+}
+
+// scheduleBirthdayNotifications is run by the daily trigger of the in-process scheduler.
+func scheduleBirthdayNotifications(ctx context.Context) {
+	log.Printf("Scheduling birthdays")
+	summary, err := birthdayNotifier.ScheduleBirthdayNotifications(ctx, "")
+	if err != nil {
+		common.ErrorLogger.Printf("Failed to schedule birthdays (%v scheduled, %v failed): %v\n", summary.Scheduled, summary.Failed, err)
+		return
+	}
+	log.Printf("Scheduled %v notifications\n", summary.Scheduled)
+}
+
+// authenticated rejects requests without the api secret. All of them are rejected when the secret isn't configured.
+func authenticated(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expectedAuthorization := []byte(fmt.Sprintf("Bearer %s", apiSecret))
+		if len(apiSecret) == 0 || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expectedAuthorization) != 1 {
+			common.ErrorLogger.Printf("Rejected an unauthenticated request: %s %s\n", r.Method, r.URL)
+			w.WriteHeader(401)
+			return
+		}
+		handler(w, r)
 	}
 }
 
-func HandleSendBirthdayNotification(w http.ResponseWriter, r *http.Request) {
-	birthday := common.BirthdayJson{}
-	if err := json.NewDecoder(r.Body).Decode(&birthday); err != nil {
-		common.ErrorLogger.Printf("Could not decode birthday from topic message: %v\n", err)
+func HandleSendNotification(w http.ResponseWriter, r *http.Request) {
+	notificationJson := common.NotificationJson{}
+	if err := json.NewDecoder(r.Body).Decode(&notificationJson); err != nil {
+		common.ErrorLogger.Printf("Could not decode notification from task: %v\n", err)
+		w.WriteHeader(400)
+		return
+	}
+	log.Printf("Received a request to send a notification: %v\n", notificationJson)
+	notification, err := adapters.MapNotificationJson(notificationJson)
+	if err != nil {
+		common.ErrorLogger.Printf("Could not map notification: %v\n", err)
 		w.WriteHeader(400)
 		return
 	}
-	log.Printf("Received a request to send a birthday notification: %v\n", birthday)
-	if err := birthdayNotifier.SendBirthdayNotification(r.Context(), core.Birthday(birthday)); err != nil {
-		common.ErrorLogger.Printf("Could not send birthday notification: %v\n", err)
+	if err := birthdayNotifier.SendNotification(r.Context(), notification); err != nil {
+		common.ErrorLogger.Printf("Could not send notification: %v\n", err)
 		w.WriteHeader(500)
 	}
 }
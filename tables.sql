@@ -7,11 +7,175 @@ CREATE TABLE IF NOT EXISTS birthdays
     username             VARCHAR(32),
     first_name           VARCHAR(64),
     last_name            VARCHAR(64),
+    birth_year           INT,
     PRIMARY KEY (chat_id, user_id)
 );
 
+ALTER TABLE birthdays ADD COLUMN IF NOT EXISTS birth_year INT;
+
 CREATE INDEX IF NOT EXISTS idx_days ON birthdays (adjusted_day_of_year);
 
 CREATE INDEX IF NOT EXISTS idx_user_ids ON birthdays (user_id);
 
-CREATE INDEX IF NOT EXISTS idx_chat_adjusted_day_of_year ON birthdays (chat_id, adjusted_day_of_year);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_chat_adjusted_day_of_year ON birthdays (chat_id, adjusted_day_of_year);
+
+CREATE TABLE IF NOT EXISTS chat_settings
+(
+    chat_id          BIGINT NOT NULL,
+    board_message_id INT,
+    digest_enabled   BOOLEAN NOT NULL DEFAULT FALSE,
+    leap_day_policy  VARCHAR(8) NOT NULL DEFAULT 'feb28',
+    name_day_country VARCHAR(2),
+    PRIMARY KEY (chat_id)
+);
+
+ALTER TABLE chat_settings ADD COLUMN IF NOT EXISTS digest_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE chat_settings ADD COLUMN IF NOT EXISTS leap_day_policy VARCHAR(8) NOT NULL DEFAULT 'feb28';
+
+ALTER TABLE chat_settings ADD COLUMN IF NOT EXISTS name_day_country VARCHAR(2);
+
+CREATE TABLE IF NOT EXISTS events
+(
+    chat_id              BIGINT      NOT NULL,
+    name                 VARCHAR(64) NOT NULL,
+    kind                 VARCHAR(16) NOT NULL,
+    date                 DATE        NOT NULL,
+    adjusted_day_of_year INT         NOT NULL,
+    event_year           INT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_chat_name ON events (chat_id, LOWER(name));
+
+CREATE INDEX IF NOT EXISTS idx_events_days ON events (adjusted_day_of_year);
+
+CREATE TABLE IF NOT EXISTS name_days
+(
+    chat_id    BIGINT      NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    name       VARCHAR(64) NOT NULL,
+    username   VARCHAR(32),
+    first_name VARCHAR(64),
+    last_name  VARCHAR(64),
+    PRIMARY KEY (chat_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_name_days_user_ids ON name_days (user_id);
+
+CREATE TABLE IF NOT EXISTS wish_contributors
+(
+    user_id    BIGINT    NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS pending_wishes
+(
+    author_user_id       BIGINT    NOT NULL,
+    chat_id              BIGINT    NOT NULL,
+    birthday_user_id     BIGINT    NOT NULL,
+    birthday_date        DATE      NOT NULL,
+    birthday_person_name TEXT      NOT NULL,
+    created_at           TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (author_user_id, chat_id, birthday_user_id, birthday_date)
+);
+
+CREATE TABLE IF NOT EXISTS wishes
+(
+    chat_id          BIGINT    NOT NULL,
+    birthday_user_id BIGINT    NOT NULL,
+    birthday_date    DATE      NOT NULL,
+    author_user_id   BIGINT    NOT NULL,
+    username         VARCHAR(32),
+    first_name       VARCHAR(64),
+    last_name        VARCHAR(64),
+    text             TEXT      NOT NULL DEFAULT '',
+    voice_file_id    TEXT      NOT NULL DEFAULT '',
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chat_id, birthday_user_id, birthday_date, author_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishes_author_user_ids ON wishes (author_user_id);
+
+CREATE TABLE IF NOT EXISTS wishlist_items
+(
+    id         BIGSERIAL    NOT NULL,
+    user_id    BIGINT       NOT NULL,
+    chat_id    BIGINT       NOT NULL,
+    text       VARCHAR(512) NOT NULL,
+    claimed_by BIGINT,
+    username   VARCHAR(32),
+    first_name VARCHAR(64),
+    last_name  VARCHAR(64),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_user_ids ON wishlist_items (user_id, chat_id);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_claimed_by ON wishlist_items (claimed_by);
+
+CREATE TABLE IF NOT EXISTS birthday_videos
+(
+    id         BIGSERIAL NOT NULL,
+    user_id    BIGINT    NOT NULL,
+    chat_id    BIGINT    NOT NULL,
+    year       INT       NOT NULL,
+    file_id    TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id),
+    UNIQUE (user_id, chat_id, year)
+);
+
+CREATE TABLE IF NOT EXISTS cached_videos
+(
+    user_id           BIGINT    NOT NULL,
+    picture_unique_id TEXT      NOT NULL,
+    file_id           TEXT      NOT NULL,
+    created_at        TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, picture_unique_id)
+);
+
+CREATE TABLE IF NOT EXISTS custom_pictures
+(
+    user_id        BIGINT    NOT NULL,
+    chat_id        BIGINT    NOT NULL,
+    file_id        TEXT      NOT NULL,
+    file_unique_id TEXT      NOT NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, chat_id)
+);
+
+CREATE TABLE IF NOT EXISTS delivery_steps
+(
+    chat_id      BIGINT    NOT NULL,
+    user_id      BIGINT    NOT NULL,
+    date         DATE      NOT NULL,
+    kind         TEXT      NOT NULL,
+    step         TEXT      NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chat_id, user_id, date, kind, step)
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_dates
+(
+    date         DATE      NOT NULL,
+    scheduled_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (date)
+);
+
+CREATE TABLE IF NOT EXISTS notification_jobs
+(
+    id           BIGSERIAL   NOT NULL,
+    task_id      TEXT        NOT NULL,
+    notification JSONB       NOT NULL,
+    status       VARCHAR(8)  NOT NULL,
+    attempts     INT         NOT NULL DEFAULT 0,
+    run_at       TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    last_error   TEXT,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id),
+    UNIQUE (task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_jobs_status_run_at ON notification_jobs (status, run_at);
@@ -30,8 +30,12 @@ resource "google_cloud_run_v2_service" "manager_service" {
         name  = "BOT_TOKEN"
         value = var.telegram_bot_token
       }
+      env {
+        name  = "API_SECRET"
+        value = random_id.api_secret.hex
+      }
     }
-    timeout                          = "2s"
+    timeout                          = "60s"
     max_instance_request_concurrency = 10
   }
 }
@@ -47,6 +51,10 @@ resource "random_id" "random_id" {
   byte_length = 8
 }
 
+resource "random_id" "api_secret" {
+  byte_length = 32
+}
+
 resource "google_cloud_tasks_queue" "birthdays_queue" {
   name     = "birthday-notifications-${random_id.random_id.dec}" # workaround for a 7-day wait time to create a queue with the same name
   location = var.service_location
@@ -64,7 +72,7 @@ resource "google_cloud_run_v2_service" "notifier_service" {
   location = var.service_location
 
   template {
-    max_instance_request_concurrency = 1
+    max_instance_request_concurrency = 4
     timeout                          = "120s"
     scaling {
       max_instance_count = 1
@@ -105,6 +113,18 @@ resource "google_cloud_run_v2_service" "notifier_service" {
         name  = "TASK_DELAY_S"
         value = 30
       }
+      env {
+        name  = "MAX_CONCURRENT_NOTIFICATIONS"
+        value = 2
+      }
+      env {
+        name  = "CATCH_UP_LOOKBACK_DAYS"
+        value = 3
+      }
+      env {
+        name  = "API_SECRET"
+        value = random_id.api_secret.hex
+      }
     }
   }
 }
@@ -128,3 +148,35 @@ resource "google_cloud_scheduler_job" "job" {
     uri         = "${google_cloud_run_v2_service.notifier_service.uri}/schedule"
   }
 }
+
+resource "google_cloud_scheduler_job" "boards_job" {
+  name             = "boards-job"
+  schedule         = "0 9 * * *"
+  time_zone        = "CET"
+  attempt_deadline = "60s"
+  region           = var.service_location
+
+  http_target {
+    http_method = "POST"
+    uri         = "${google_cloud_run_v2_service.manager_service.uri}/boards"
+    headers = {
+      Authorization = "Bearer ${random_id.api_secret.hex}"
+    }
+  }
+}
+
+resource "google_cloud_scheduler_job" "wish_invitations_job" {
+  name             = "wish-invitations-job"
+  schedule         = "0 12 * * *"
+  time_zone        = "CET"
+  attempt_deadline = "60s"
+  region           = var.service_location
+
+  http_target {
+    http_method = "POST"
+    uri         = "${google_cloud_run_v2_service.manager_service.uri}/wishinvitations"
+    headers = {
+      Authorization = "Bearer ${random_id.api_secret.hex}"
+    }
+  }
+}